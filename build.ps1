@@ -85,11 +85,18 @@ Get-Content $envFile | ForEach-Object {
 }
 
 # Validate required vars
-$required = @("R2_ACCOUNT_ID", "R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY", "R2_BUCKET_NAME", "APP_NAME")
-foreach ($var in $required) {
-    if (-not $envVars.ContainsKey($var)) {
-        Write-Error "Missing required variable: $var"
-        exit 1
+# Remote credentials may instead come from config.json, RITUAL_* variables or flags at runtime
+if (-not $envVars.ContainsKey("APP_NAME")) {
+    Write-Error "Missing required variable: APP_NAME"
+    exit 1
+}
+$remote = @("R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY", "R2_BUCKET_NAME")
+if (-not $envVars['S3_ENDPOINT']) {
+    $remote += "R2_ACCOUNT_ID"
+}
+foreach ($var in $remote) {
+    if (-not $envVars[$var]) {
+        Write-Host "Note: $var not set; it must be configured at runtime" -ForegroundColor Yellow
     }
 }
 
@@ -104,6 +111,7 @@ Pop-Location
 # Build
 Write-Host "Compiling..." -ForegroundColor Gray
 $ldflags = "-X main.envAccountID=$($envVars['R2_ACCOUNT_ID']) -X main.envAccessKeyID=$($envVars['R2_ACCESS_KEY_ID']) -X main.envSecretAccessKey=$($envVars['R2_SECRET_ACCESS_KEY']) -X main.envBucket=$($envVars['R2_BUCKET_NAME']) -X ritual/internal/config.AppName=$($envVars['APP_NAME'])"
+$ldflags += " -X main.envEndpoint=$($envVars['S3_ENDPOINT']) -X main.envRegion=$($envVars['S3_REGION']) -X main.envPathStyle=$($envVars['S3_PATH_STYLE']) -X main.envTLSInsecure=$($envVars['S3_TLS_INSECURE']) -X main.envCACertFile=$($envVars['S3_CA_CERT_FILE'])"
 
 go build -ldflags $ldflags -o "ritual_$env.exe" ./cmd/cli
 
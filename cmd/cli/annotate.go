@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/services"
+)
+
+// Flags accepted by `ritual annotate`
+const (
+	annotateLabelFlag   = "--label"
+	annotateUnlabelFlag = "--unlabel"
+	annotateNoteFlag    = "--note"
+)
+
+// errAnnotateUsage reports malformed annotate arguments
+var errAnnotateUsage = errors.New("invalid arguments")
+
+// runPin marks a backup so retention never deletes it
+func runPin(ctx context.Context, env *commandEnv) {
+	setPinned(ctx, env, config.PinCommand, true)
+}
+
+// runUnpin returns a backup to normal retention
+func runUnpin(ctx context.Context, env *commandEnv) {
+	setPinned(ctx, env, config.UnpinCommand, false)
+}
+
+// setPinned pins or unpins the backup named by the single argument
+func setPinned(ctx context.Context, env *commandEnv, command string, pinned bool) {
+	if len(env.args) != 1 {
+		fmt.Printf("Usage: ritual %s <backup timestamp, file name or URI>\n", command)
+		return
+	}
+
+	annotator, err := services.NewBackupAnnotator(env.librarian, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create backup annotator: %v\n", err)
+		return
+	}
+
+	world, err := annotator.Pin(ctx, env.args[0], pinned)
+	switch {
+	case err != nil:
+		fmt.Printf("Failed to %s backup: %v\n", command, err)
+	case pinned:
+		fmt.Printf("Pinned %s; retention keeps it until it is unpinned\n", world.URI)
+	default:
+		fmt.Printf("Unpinned %s\n", world.URI)
+	}
+}
+
+// runAnnotate adds or removes labels and sets the note of a backup
+func runAnnotate(ctx context.Context, env *commandEnv) {
+	ref, annotation, err := parseAnnotateArgs(env.args)
+	if err != nil {
+		fmt.Printf("Usage: ritual %s <backup> [%s name]... [%s name]... [%s text]\n",
+			config.AnnotateCommand, annotateLabelFlag, annotateUnlabelFlag, annotateNoteFlag)
+		return
+	}
+
+	annotator, err := services.NewBackupAnnotator(env.librarian, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create backup annotator: %v\n", err)
+		return
+	}
+
+	world, err := annotator.Annotate(ctx, ref, annotation)
+	if err != nil {
+		fmt.Printf("Failed to annotate backup: %v\n", err)
+		return
+	}
+	fmt.Printf("Annotated %s\n", world.URI)
+	printAnnotations(os.Stdout, *world)
+}
+
+// parseAnnotateArgs reads the backup reference followed by label and note flags
+// Flags take their value from the next argument or after '='
+func parseAnnotateArgs(args []string) (string, services.BackupAnnotation, error) {
+	var annotation services.BackupAnnotation
+	if len(args) < 2 || strings.HasPrefix(args[0], "--") {
+		return "", annotation, errAnnotateUsage
+	}
+
+	for i := 1; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", annotation, errAnnotateUsage
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case annotateLabelFlag:
+			annotation.AddLabels = append(annotation.AddLabels, value)
+		case annotateUnlabelFlag:
+			annotation.RemoveLabels = append(annotation.RemoveLabels, value)
+		case annotateNoteFlag:
+			annotation.Note = &value
+		default:
+			return "", annotation, errAnnotateUsage
+		}
+	}
+	return args[0], annotation, nil
+}
+
+// printAnnotations writes the pin, labels and note of a backup entry
+func printAnnotations(w io.Writer, world domain.World) {
+	if world.Pinned {
+		fmt.Fprintln(w, "  pinned")
+	}
+	if len(world.Labels) > 0 {
+		fmt.Fprintf(w, "  labels: %s\n", strings.Join(world.Labels, ", "))
+	}
+	if world.Note != "" {
+		fmt.Fprintf(w, "  note: %s\n", world.Note)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"ritual/internal/adapters"
+	"ritual/internal/adapters/streamer"
+	"ritual/internal/config"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/services"
+)
+
+// commandEnv holds the dependencies shared by maintenance subcommands
+type commandEnv struct {
+	workRoot      *os.Root
+	localStorage  *adapters.FSRepository
+	remoteStorage *adapters.R2Repository
+	uploader      *adapters.S3Uploader
+	librarian     *services.LibrarianService
+	keys          *streamer.Keyring // nil when archives are not encrypted
+	bucket        string
+	replicas      []replica // additional backup targets from replicas.json
+	events        chan<- ports.Event
+	args          []string // arguments after the subcommand name
+}
+
+// commands maps subcommand names to their handlers
+// Subcommands run instead of the server lifecycle
+var commands = map[string]func(ctx context.Context, env *commandEnv){
+	config.UnlockCommand:    runUnlock,
+	config.RestoreCommand:   runRestore,
+	config.PublishCommand:   runPublish,
+	config.RetentionCommand: runRetention,
+	config.PinCommand:       runPin,
+	config.UnpinCommand:     runUnpin,
+	config.AnnotateCommand:  runAnnotate,
+	config.ListCommand:      runList,
+}
+
+// runSubcommand runs the subcommand named in args
+// Returns false if args name no subcommand
+func runSubcommand(args []string, rt *config.Runtime, input *inputRouter) bool {
+	if len(args) < 2 {
+		return false
+	}
+	// The config report needs no remote storage, so it works while the remote is misconfigured
+	if args[1] == config.ConfigCommand {
+		printRuntime(os.Stdout, rt)
+		return true
+	}
+	handler, ok := commands[args[1]]
+	if !ok {
+		return false
+	}
+
+	runCtx, _, stopSignals := newShutdownContexts()
+	defer stopSignals()
+
+	backend, err := remoteBackend(rt)
+	if err != nil {
+		fmt.Printf("Configuration error: remote storage not configured: %v\n", err)
+		fmt.Printf("Run '%s' to see where each value comes from\n", config.ConfigCommand)
+		return true
+	}
+
+	// Ensure root directory exists
+	if err := os.MkdirAll(config.RootPath, config.DirPermission); err != nil {
+		fmt.Printf("Failed to create root directory: %v\n", err)
+		return true
+	}
+
+	workRoot, err := os.OpenRoot(config.RootPath)
+	if err != nil {
+		fmt.Printf("Failed to open work root: %v\n", err)
+		return true
+	}
+	defer workRoot.Close()
+
+	events := make(chan ports.Event, 100)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		consumeEvents(events, nil, input)
+	}()
+	defer func() {
+		close(events)
+		wg.Wait()
+	}()
+
+	localStorage, err := adapters.NewFSRepository(workRoot)
+	if err != nil {
+		fmt.Printf("Failed to create local storage: %v\n", err)
+		return true
+	}
+
+	remoteStorage, uploader, err := adapters.NewS3RepositoryWithUploader(rt.Bucket, backend, events)
+	if err != nil {
+		fmt.Printf("Failed to create remote storage: %v\n", err)
+		return true
+	}
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	if err != nil {
+		fmt.Printf("Failed to create librarian service: %v\n", err)
+		return true
+	}
+
+	keys, err := loadKeyring(workRoot)
+	if err != nil {
+		fmt.Printf("Failed to load archive keys: %v\n", err)
+		return true
+	}
+
+	replicas, closeReplicas, err := loadReplicas(workRoot.Name(), events)
+	if err != nil {
+		fmt.Printf("Failed to load backup replicas: %v\n", err)
+		return true
+	}
+	defer closeReplicas()
+
+	handler(runCtx, &commandEnv{
+		workRoot:      workRoot,
+		localStorage:  localStorage,
+		remoteStorage: remoteStorage,
+		uploader:      uploader,
+		librarian:     librarian,
+		keys:          keys,
+		bucket:        rt.Bucket,
+		replicas:      replicas,
+		events:        events,
+		args:          args[2:],
+	})
+	return true
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,10 +17,9 @@ func timestamp() string {
 }
 
 // consumeEvents reads events from channel and prints to stdout and optional log file
+// Prompt answers are read from input
 // Runs until channel is closed
-func consumeEvents(events <-chan ports.Event, logFile io.Writer) {
-	reader := bufio.NewReader(os.Stdin)
-
+func consumeEvents(events <-chan ports.Event, logFile io.Writer, input *inputRouter) {
 	// Create writer that outputs to both stdout and log file
 	var writer io.Writer = os.Stdout
 	if logFile != nil {
@@ -46,30 +45,33 @@ func consumeEvents(events <-chan ports.Event, logFile io.Writer) {
 		case ports.ErrorEvent:
 			fmt.Fprintf(writer, "[%s] [%s] ERROR: %v\n", timestamp(), e.Operation, e.Err)
 		case ports.PromptEvent:
-			handlePrompt(reader, e, writer)
+			handlePrompt(input, e, writer)
 		}
 	}
 }
 
 // handlePrompt displays prompt and sends user response back via channel
-func handlePrompt(reader *bufio.Reader, e ports.PromptEvent, writer io.Writer) {
+func handlePrompt(input *inputRouter, e ports.PromptEvent, writer io.Writer) {
 	if e.DefaultValue != "" {
 		fmt.Fprintf(writer, "%s [%s]: ", e.Prompt, e.DefaultValue)
 	} else {
 		fmt.Fprintf(writer, "%s: ", e.Prompt)
 	}
 
-	input, err := reader.ReadString('\n')
+	line, err := input.ReadLineTimeout(e.Timeout)
 	if err != nil {
+		if errors.Is(err, errInputTimeout) {
+			fmt.Fprintln(writer)
+		}
 		e.ResponseChan <- any(e.DefaultValue)
 		return
 	}
 
-	input = strings.TrimSpace(input)
-	if input == "" {
+	line = strings.TrimSpace(line)
+	if line == "" {
 		e.ResponseChan <- any(e.DefaultValue)
 	} else {
-		fmt.Fprintf(writer, "%s\n", input)
-		e.ResponseChan <- any(input)
+		fmt.Fprintf(writer, "%s\n", line)
+		e.ResponseChan <- any(line)
 	}
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errInputTimeout is returned when no input line arrived in time
+var errInputTimeout = errors.New("no input received in time")
+
+// inputRouter owns stdin and hands each line to a pending prompt,
+// or to the server console when no prompt is waiting
+type inputRouter struct {
+	mu      sync.Mutex
+	prompt  chan string // Non-nil while a prompt waits for input
+	console io.Writer   // Receives lines while no prompt is pending (nil = drop)
+	closed  chan struct{}
+}
+
+// newInputRouter starts reading lines from r in the background
+func newInputRouter(r io.Reader) *inputRouter {
+	router := &inputRouter{
+		closed: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(router.closed)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			router.dispatch(scanner.Text())
+		}
+	}()
+
+	return router
+}
+
+// dispatch delivers a line to the waiting prompt or forwards it to the console
+func (ir *inputRouter) dispatch(line string) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	if ir.prompt != nil {
+		ir.prompt <- line
+		ir.prompt = nil
+		return
+	}
+
+	if ir.console != nil && strings.TrimSpace(line) != "" {
+		io.WriteString(ir.console, line+"\n")
+	}
+}
+
+// ReadLine blocks until the next input line arrives
+// Returns io.EOF once stdin is closed
+func (ir *inputRouter) ReadLine() (string, error) {
+	return ir.ReadLineTimeout(0)
+}
+
+// ReadLineTimeout is ReadLine giving up with errInputTimeout after timeout; 0 waits without limit
+func (ir *inputRouter) ReadLineTimeout(timeout time.Duration) (string, error) {
+	response := make(chan string, 1)
+
+	ir.mu.Lock()
+	ir.prompt = response
+	ir.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case line := <-response:
+		return line, nil
+	case <-expired:
+		ir.mu.Lock()
+		defer ir.mu.Unlock()
+		// A line may have been delivered right before the prompt was withdrawn
+		if ir.prompt != response {
+			return <-response, nil
+		}
+		ir.prompt = nil
+		return "", errInputTimeout
+	case <-ir.closed:
+		// A line may have been delivered right before stdin closed
+		select {
+		case line := <-response:
+			return line, nil
+		default:
+			return "", io.EOF
+		}
+	}
+}
+
+// SetConsole sets the writer that receives lines typed while no prompt is pending
+func (ir *inputRouter) SetConsole(console io.Writer) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	ir.console = console
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ritual/internal/adapters/streamer"
+	"ritual/internal/config"
+)
+
+// loadKeyring loads the archive encryption keys from the work root
+// The current key comes from the passphrase environment variable or archive.key
+// Retired keys in keys/*.key stay available for opening older archives
+// Returns nil if no current key is configured, leaving archives unencrypted
+func loadKeyring(workRoot *os.Root) (*streamer.Keyring, error) {
+	if workRoot == nil {
+		return nil, errors.New("workRoot cannot be nil")
+	}
+	rootPath := workRoot.Name()
+
+	var current *streamer.Key
+	if passphrase := os.Getenv(config.PassphraseEnvVar); passphrase != "" {
+		key, err := streamer.KeyFromPassphrase(passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key from %s: %w", config.PassphraseEnvVar, err)
+		}
+		current = key
+	} else {
+		keyPath := filepath.Join(rootPath, config.ArchiveKeyFilename)
+		key, err := streamer.LoadKeyFile(keyPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load %s: %w", config.ArchiveKeyFilename, err)
+		}
+		current = key
+	}
+
+	paths, err := filepath.Glob(filepath.Join(rootPath, config.RetiredKeysDir, "*"+config.KeyFileExtension))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retired keys: %w", err)
+	}
+	retired := make([]*streamer.Key, 0, len(paths))
+	for _, path := range paths {
+		key, err := streamer.LoadKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load retired key %s: %w", filepath.Base(path), err)
+		}
+		retired = append(retired, key)
+	}
+
+	if current == nil {
+		if len(retired) > 0 {
+			fmt.Printf("Warning: %d retired archive keys found but no current key; new archives will not be encrypted\n", len(retired))
+		}
+		return nil, nil
+	}
+
+	return streamer.NewKeyring(current, retired...)
+}
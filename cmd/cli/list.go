@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+)
+
+// runList prints the backups recorded in the remote manifest, newest first
+func runList(ctx context.Context, env *commandEnv) {
+	if len(env.args) != 0 {
+		fmt.Printf("Usage: ritual %s\n", config.ListCommand)
+		return
+	}
+
+	manifest, err := env.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		fmt.Printf("Failed to get remote manifest: %v\n", err)
+		return
+	}
+
+	printBackups(os.Stdout, manifest.Backups)
+}
+
+// printBackups writes each backup with its session metadata and annotations
+// Metadata missing from older entries is left out
+func printBackups(w io.Writer, backups []domain.World) {
+	if len(backups) == 0 {
+		fmt.Fprintln(w, "No backups recorded")
+		return
+	}
+
+	sorted := slices.Clone(backups)
+	slices.SortStableFunc(sorted, func(a, b domain.World) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	for _, world := range sorted {
+		fmt.Fprintf(w, "%s  %s  %s\n", world.CreatedAt.Local().Format(time.DateTime), world.URI, formatBytes(world.Size))
+		if world.Intermediate {
+			fmt.Fprintln(w, "  checkpoint taken while the server ran")
+		}
+		if !world.RestoredAt.IsZero() {
+			fmt.Fprintf(w, "  restored: %s\n", world.RestoredAt.Local().Format(time.DateTime))
+		}
+		if world.Host != "" {
+			fmt.Fprintf(w, "  host: %s\n", world.Host)
+		}
+		if world.SessionSeconds > 0 {
+			fmt.Fprintf(w, "  session: %s\n", world.SessionDuration())
+		}
+		if world.MinecraftVersion != "" {
+			fmt.Fprintf(w, "  minecraft: %s\n", world.MinecraftVersion)
+		}
+		if world.RitualVersion != "" {
+			fmt.Fprintf(w, "  ritual: %s\n", world.RitualVersion)
+		}
+		if len(world.WorldDirs) > 0 {
+			fmt.Fprintf(w, "  worlds: %s\n", strings.Join(world.WorldDirs, ", "))
+		}
+		if len(world.Players) > 0 {
+			fmt.Fprintf(w, "  players: %s\n", strings.Join(world.Players, ", "))
+		}
+		printAnnotations(w, world)
+	}
+	fmt.Fprintf(w, "%d backups\n", len(sorted))
+}
@@ -3,24 +3,33 @@ package main
 //go:generate goversioninfo
 
 import (
-	"bufio"
-	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"ritual/internal/adapters"
+	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
 	"ritual/internal/core/ports"
 	"ritual/internal/core/services"
 )
 
 // Injected at build time via ldflags
+// Build-time values are the lowest configuration layer above the defaults; see loadRuntime
 var (
 	envAccountID       string
 	envAccessKeyID     string
 	envSecretAccessKey string
 	envBucket          string
+
+	// Optional S3-compatible backend; an empty endpoint means Cloudflare R2
+	envEndpoint    string
+	envRegion      string
+	envPathStyle   string
+	envTLSInsecure string
+	envCACertFile  string
 )
 
 func main() {
@@ -29,16 +38,39 @@ func main() {
 		return
 	}
 
+	// Single stdin owner shared by prompts and the server console
+	input := newInputRouter(os.Stdin)
+
+	rt, args, err := loadRuntime(os.Args)
+	if err != nil {
+		fmt.Printf("Configuration error: %v\n", err)
+		fmt.Println("\nPress Enter to exit...")
+		input.ReadLine()
+		return
+	}
+	rt.Apply()
+
+	if runSubcommand(args, rt, input) {
+		return
+	}
+
+	// Signals cancel runCtx first; exitCtx survives until a second signal
+	runCtx, exitCtx, stopSignals := newShutdownContexts()
+	defer stopSignals()
+
 	success := false
+	interrupted := false
 	defer func() {
-		if !success {
+		if !success && !interrupted {
 			fmt.Println("\nPress Enter to exit...")
-			bufio.NewReader(os.Stdin).ReadBytes('\n')
+			input.ReadLine()
 		}
 	}()
 
-	if envAccountID == "" || envAccessKeyID == "" || envSecretAccessKey == "" || envBucket == "" {
-		fmt.Println("Build error: R2 credentials not injected")
+	backend, err := remoteBackend(rt)
+	if err != nil {
+		fmt.Printf("Configuration error: remote storage not configured: %v\n", err)
+		fmt.Printf("Run '%s' to see where each value comes from\n", config.ConfigCommand)
 		return
 	}
 
@@ -72,9 +104,11 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		consumeEvents(events, logFile)
+		consumeEvents(events, logFile, input)
 	}()
 
+	ports.SendEvent(events, ports.UpdateEvent{Operation: "config", Message: "Configuration loaded", Data: runtimeSources(rt)})
+
 	// Create local storage
 	localStorage, err := adapters.NewFSRepository(workRoot)
 	if err != nil {
@@ -84,8 +118,8 @@ func main() {
 		return
 	}
 
-	// Create remote storage (R2) and uploader
-	remoteStorage, r2Uploader, err := adapters.NewR2RepositoryWithUploader(envBucket, envAccountID, envAccessKeyID, envSecretAccessKey, events)
+	// Create remote storage (R2 or another S3-compatible backend) and uploader
+	remoteStorage, r2Uploader, err := adapters.NewS3RepositoryWithUploader(rt.Bucket, backend, events)
 	if err != nil {
 		fmt.Printf("Failed to create remote storage: %v\n", err)
 		close(events)
@@ -102,6 +136,15 @@ func main() {
 		return
 	}
 
+	// Load archive encryption keys (nil when encryption is not configured)
+	keys, err := loadKeyring(workRoot)
+	if err != nil {
+		fmt.Printf("Failed to load archive keys: %v\n", err)
+		close(events)
+		wg.Wait()
+		return
+	}
+
 	// Create validator service
 	validator, err := services.NewValidatorService()
 	if err != nil {
@@ -120,7 +163,7 @@ func main() {
 		return
 	}
 
-	instanceUpdater, err := services.NewInstanceUpdater(librarian, validator, remoteStorage, envBucket, workRoot)
+	instanceUpdater, err := services.NewInstanceUpdater(librarian, validator, remoteStorage, rt.Bucket, workRoot, keys)
 	if err != nil {
 		fmt.Printf("Failed to create instance updater: %v\n", err)
 		close(events)
@@ -128,7 +171,7 @@ func main() {
 		return
 	}
 
-	worldsUpdater, err := services.NewWorldsUpdater(librarian, validator, remoteStorage, envBucket, workRoot, events)
+	worldsUpdater, err := services.NewWorldsUpdater(librarian, validator, remoteStorage, rt.Bucket, workRoot, keys, events)
 	if err != nil {
 		fmt.Printf("Failed to create worlds updater: %v\n", err)
 		close(events)
@@ -140,7 +183,7 @@ func main() {
 
 	// Create conditions (pre-flight checks before updaters run)
 	// Fetch remote manifest to get thresholds for conditions
-	remoteManifestForConditions, err := librarian.GetRemoteManifest(context.Background())
+	remoteManifestForConditions, err := librarian.GetRemoteManifest(runCtx)
 	if err != nil {
 		fmt.Printf("Failed to get remote manifest for conditions: %v\n", err)
 		close(events)
@@ -149,13 +192,13 @@ func main() {
 	}
 
 	// Create system info adapter for RAM and disk space checks
-	systemInfo := adapters.NewWindowsSystemInfo()
+	systemInfo := adapters.NewSystemInfo()
 
 	// Create Java info adapter for Java version check
 	javaInfo := adapters.NewJavaInfo()
 
 	// Create manifest lock condition
-	lockCondition, err := services.NewManifestLockCondition(librarian)
+	lockCondition, err := services.NewManifestLockCondition(librarian, events)
 	if err != nil {
 		fmt.Printf("Failed to create lock condition: %v\n", err)
 		close(events)
@@ -192,35 +235,27 @@ func main() {
 
 	conditions := []ports.ConditionService{lockCondition, ramCondition, diskCondition, javaCondition}
 
-	// Create retention services
-	localRetention, err := services.NewLocalRetention(localStorage, events)
+	// Open additional backup targets from replicas.json
+	replicas, closeReplicas, err := loadReplicas(workRoot.Name(), events)
 	if err != nil {
-		fmt.Printf("Failed to create local retention: %v\n", err)
+		fmt.Printf("Failed to load backup replicas: %v\n", err)
 		close(events)
 		wg.Wait()
 		return
 	}
+	defer closeReplicas()
 
-	r2Retention, err := services.NewR2Retention(remoteStorage, events)
-	if err != nil {
-		fmt.Printf("Failed to create R2 retention: %v\n", err)
-		close(events)
-		wg.Wait()
-		return
-	}
-
-	logRetention, err := services.NewLogRetention(localStorage, events)
+	// Create retention services
+	retentions, err := newRetentions(localStorage, remoteStorage, replicas, events)
 	if err != nil {
-		fmt.Printf("Failed to create log retention: %v\n", err)
+		fmt.Printf("Failed to create retention services: %v\n", err)
 		close(events)
 		wg.Wait()
 		return
 	}
 
-	retentions := []ports.RetentionService{localRetention, r2Retention, logRetention}
-
 	// Fetch remote manifest to get configuration
-	remoteManifest, err := librarian.GetRemoteManifest(context.Background())
+	remoteManifest, err := librarian.GetRemoteManifest(runCtx)
 	if err != nil {
 		fmt.Printf("Failed to get remote manifest: %v\n", err)
 		close(events)
@@ -244,8 +279,14 @@ func main() {
 		return joined
 	}
 
+	// Chunked backups list stored chunks to upload only new ones
+	var chunkLister streamer.S3StreamLister
+	if remoteManifest.UsesChunkedBackups() {
+		chunkLister = remoteStorage
+	}
+
 	// Create backupper (R2 with local tee - single archive stream to both destinations)
-	r2Backupper, err := services.NewR2Backupper(r2Uploader, envBucket, workRoot, remoteManifest.WorldDirs, true, nil, shouldRunBackup, events)
+	r2Backupper, err := services.NewR2Backupper(r2Uploader, remoteStorage, chunkLister, rt.Bucket, workRoot, remoteManifest.WorldDirs, true, nil, shouldRunBackup, keys, events)
 	if err != nil {
 		fmt.Printf("Failed to create R2 backupper: %v\n", err)
 		close(events)
@@ -254,10 +295,20 @@ func main() {
 	}
 
 	backuppers := []ports.BackupperService{r2Backupper}
+	if len(replicas) > 0 {
+		// Verified backups are copied from R2 to every replica target
+		replicatedBackupper, err := services.NewReplicatedBackupper(r2Backupper, remoteStorage, rt.Bucket, replicaTargets(replicas), events)
+		if err != nil {
+			fmt.Printf("Failed to create replicated backupper: %v\n", err)
+			close(events)
+			wg.Wait()
+			return
+		}
+		backuppers = []ports.BackupperService{replicatedBackupper}
+	}
 
-	// Create server runner
-	commandExecutor := adapters.NewCommandExecutorAdapter()
-	serverRunner, err := adapters.NewServerRunner(config.RootPath, workRoot, remoteManifest.StartScript, commandExecutor)
+	// Create server runner (owns the java process and its console)
+	serverRunner, err := adapters.NewManagedServerRunner(workRoot, remoteManifest.StartScript, events)
 	if err != nil {
 		fmt.Printf("Failed to create server runner: %v\n", err)
 		close(events)
@@ -265,6 +316,23 @@ func main() {
 		return
 	}
 
+	// Checkpoint and stop commands go over RCON when enabled
+	if rt.Rcon {
+		rcon, err := adapters.NewRconClient(time.Duration(config.RconCommandTimeoutSec) * time.Second)
+		if err == nil {
+			err = serverRunner.EnableRcon(rcon)
+		}
+		if err != nil {
+			fmt.Printf("Failed to enable RCON: %v\n", err)
+			close(events)
+			wg.Wait()
+			return
+		}
+	}
+
+	// Lines typed while no prompt is pending go to the server console
+	input.SetConsole(serverRunner.Console())
+
 	// Create Molfar service
 	molfar, err := services.NewMolfarService(conditions, updaters, backuppers, retentions, serverRunner, librarian, events, workRoot)
 	if err != nil {
@@ -274,6 +342,35 @@ func main() {
 		return
 	}
 
+	// Back up the world while the server runs, so a crash loses at most one interval of play
+	if rt.CheckpointInterval > 0 {
+		checkpointer, err := services.NewCheckpointer(serverRunner, backuppers[0], librarian, rt.CheckpointInterval, events)
+		if err != nil {
+			fmt.Printf("Failed to create checkpointer: %v\n", err)
+			close(events)
+			wg.Wait()
+			return
+		}
+		molfar.EnableCheckpoints(checkpointer)
+	}
+
+	// Report whether the server came up and who is online, and notice when it stops answering
+	pinger, err := adapters.NewServerListPinger(time.Duration(config.HealthProbeTimeoutSec) * time.Second)
+	if err != nil {
+		fmt.Printf("Failed to create server list pinger: %v\n", err)
+		close(events)
+		wg.Wait()
+		return
+	}
+	healthProbe, err := services.NewHealthProbe(pinger, config.HealthProbeInterval, events)
+	if err != nil {
+		fmt.Printf("Failed to create health probe: %v\n", err)
+		close(events)
+		wg.Wait()
+		return
+	}
+	molfar.EnableHealthProbe(healthProbe)
+
 	// Prompt for settings and create server config
 	// Pass min RAM from manifest so user can't enter less than required
 	settings, err := services.PromptSettings(events, remoteManifestForConditions.GetMinRAMMB())
@@ -295,20 +392,22 @@ func main() {
 	// Run lifecycle
 	fmt.Println("Starting Ritual")
 
-	if err := molfar.Prepare(); err != nil {
+	if err := molfar.Prepare(runCtx); err != nil {
 		fmt.Printf("Prepare phase failed: %v\n", err)
+		interrupted = errors.Is(err, services.ErrShutdownRequested)
 		close(events)
 		wg.Wait()
 		return
 	}
 
-	runErr := molfar.Run(server)
+	runErr := molfar.Run(runCtx, server)
 	if runErr != nil {
 		fmt.Printf("Run phase failed: %v\n", runErr)
+		interrupted = errors.Is(runErr, services.ErrShutdownRequested)
 	}
 
-	// Always attempt Exit to unlock manifests, even if Run failed
-	if err := molfar.Exit(); err != nil {
+	// Always attempt Exit to back up and unlock manifests, even if Run failed or was interrupted
+	if err := molfar.Exit(exitCtx); err != nil {
 		fmt.Printf("Exit phase failed: %v\n", err)
 		close(events)
 		wg.Wait()
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ritual/internal/config"
+	"ritual/internal/core/services"
+)
+
+// runPublish uploads the local instance as the version every host updates to
+func runPublish(ctx context.Context, env *commandEnv) {
+	if len(env.args) != 1 {
+		fmt.Printf("Usage: ritual %s <instance version>\n", config.PublishCommand)
+		return
+	}
+
+	publisher, err := services.NewPublishService(env.librarian, env.uploader, env.remoteStorage, env.remoteStorage, env.bucket, env.workRoot, env.keys, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create publish service: %v\n", err)
+		return
+	}
+
+	err = publisher.Publish(ctx, env.args[0])
+	switch {
+	case errors.Is(err, services.ErrPublishDeclined):
+		fmt.Println("Publish cancelled")
+	case err != nil:
+		fmt.Printf("Publish failed: %v\n", err)
+	default:
+		fmt.Printf("Published instance version %s\n", env.args[0])
+	}
+}
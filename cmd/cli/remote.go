@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+)
+
+// remoteBackend builds the remote storage configuration from the effective runtime configuration
+// Without an endpoint the remote is the Cloudflare R2 account in rt.AccountID
+func remoteBackend(rt *config.Runtime) (adapters.S3Config, error) {
+	if rt == nil {
+		return adapters.S3Config{}, errors.New("runtime configuration cannot be nil")
+	}
+	if rt.Bucket == "" {
+		return adapters.S3Config{}, errors.New("bucket not configured")
+	}
+
+	backend := adapters.S3Config{
+		Endpoint:           rt.Endpoint,
+		Region:             rt.Region,
+		AccessKeyID:        rt.AccessKeyID,
+		SecretAccessKey:    rt.SecretAccessKey,
+		UsePathStyle:       rt.PathStyle,
+		InsecureSkipVerify: rt.TLSInsecure,
+		CACertFile:         rt.CACertFile,
+	}
+	if rt.Endpoint == "" {
+		if rt.AccountID == "" {
+			return adapters.S3Config{}, errors.New("neither an S3 endpoint nor an R2 account ID is configured")
+		}
+		r2 := adapters.R2Config(rt.AccountID, rt.AccessKeyID, rt.SecretAccessKey)
+		backend.Endpoint = r2.Endpoint
+		if backend.Region == "" {
+			backend.Region = r2.Region
+		}
+	}
+
+	return backend, backend.Validate()
+}
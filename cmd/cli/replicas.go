@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/services"
+)
+
+// replicaConfig is one entry of replicas.json
+// A path makes the target a directory (e.g. a NAS mount); otherwise it is an S3-compatible bucket
+type replicaConfig struct {
+	Name            string `json:"name"`
+	Path            string `json:"path,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	PathStyle       bool   `json:"path_style,omitempty"`
+	TLSInsecure     bool   `json:"tls_insecure,omitempty"`
+	CACertFile      string `json:"ca_cert_file,omitempty"`
+}
+
+// replica is an opened replication target with the storage its retention prunes
+type replica struct {
+	target  services.ReplicaTarget
+	storage ports.StorageRepository
+}
+
+// loadReplicas opens the targets listed in replicas.json under the root directory
+// Returns no targets when the file does not exist; close releases directory targets
+func loadReplicas(rootPath string, events chan<- ports.Event) ([]replica, func(), error) {
+	data, err := os.ReadFile(filepath.Join(rootPath, config.ReplicasFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, func() {}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", config.ReplicasFilename, err)
+	}
+
+	var entries []replicaConfig
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", config.ReplicasFilename, err)
+	}
+
+	var closers []func() error
+	closeAll := func() {
+		for _, closer := range closers {
+			closer()
+		}
+	}
+
+	replicas := make([]replica, 0, len(entries))
+	for _, entry := range entries {
+		opened, closer, err := openReplica(entry, events)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("replica %q: %w", entry.Name, err)
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		replicas = append(replicas, opened)
+	}
+
+	return replicas, closeAll, nil
+}
+
+// openReplica creates the storage adapters for one target
+func openReplica(entry replicaConfig, events chan<- ports.Event) (replica, func() error, error) {
+	if entry.Path != "" {
+		if err := os.MkdirAll(entry.Path, config.DirPermission); err != nil {
+			return replica{}, nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		root, err := os.OpenRoot(entry.Path)
+		if err != nil {
+			return replica{}, nil, fmt.Errorf("failed to open directory: %w", err)
+		}
+		storage, err := adapters.NewFSRepository(root)
+		if err != nil {
+			root.Close()
+			return replica{}, nil, err
+		}
+		// The bucket is not used by FSRepository but required by the streamer
+		target := services.ReplicaTarget{Name: entry.Name, Bucket: "local", Uploader: storage, Downloader: storage, Lister: storage}
+		return replica{target: target, storage: storage}, storage.Close, nil
+	}
+
+	if entry.Bucket == "" {
+		return replica{}, nil, errors.New("either path or bucket must be set")
+	}
+	storage, uploader, err := adapters.NewS3RepositoryWithUploader(entry.Bucket, adapters.S3Config{
+		Endpoint:           entry.Endpoint,
+		Region:             entry.Region,
+		AccessKeyID:        entry.AccessKeyID,
+		SecretAccessKey:    entry.SecretAccessKey,
+		UsePathStyle:       entry.PathStyle,
+		InsecureSkipVerify: entry.TLSInsecure,
+		CACertFile:         entry.CACertFile,
+	}, events)
+	if err != nil {
+		return replica{}, nil, err
+	}
+	target := services.ReplicaTarget{Name: entry.Name, Bucket: entry.Bucket, Uploader: uploader, Downloader: storage, Lister: storage}
+	return replica{target: target, storage: storage}, nil, nil
+}
+
+// replicaTargets returns the targets of the opened replicas
+func replicaTargets(replicas []replica) []services.ReplicaTarget {
+	targets := make([]services.ReplicaTarget, 0, len(replicas))
+	for _, opened := range replicas {
+		targets = append(targets, opened.target)
+	}
+	return targets
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ritual/internal/core/services"
+)
+
+// runRestore extracts an older backup and makes it the current world
+func runRestore(ctx context.Context, env *commandEnv) {
+	restorer, err := services.NewRestoreService(env.librarian, env.remoteStorage, env.bucket, env.workRoot, env.keys, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create restore service: %v\n", err)
+		return
+	}
+	if err := restorer.SetReplicas(replicaTargets(env.replicas)); err != nil {
+		fmt.Printf("Failed to configure restore replicas: %v\n", err)
+		return
+	}
+
+	world, err := restorer.Restore(ctx)
+	switch {
+	case errors.Is(err, services.ErrRestoreDeclined):
+		fmt.Println("Restore cancelled")
+	case err != nil:
+		fmt.Printf("Restore failed: %v\n", err)
+	default:
+		fmt.Printf("Restored %s\n", world.URI)
+	}
+}
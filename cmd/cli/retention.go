@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/services"
+)
+
+// newRetentions creates the retention services run after each backup
+// Local backups, the primary remote and every replica are pruned before old logs
+func newRetentions(localStorage, remoteStorage ports.StorageRepository, replicas []replica, events chan<- ports.Event) ([]ports.RetentionService, error) {
+	localRetention, err := services.NewLocalRetention(localStorage, events)
+	if err != nil {
+		return nil, fmt.Errorf("local retention: %w", err)
+	}
+
+	r2Retention, err := services.NewR2Retention(remoteStorage, events)
+	if err != nil {
+		return nil, fmt.Errorf("R2 retention: %w", err)
+	}
+
+	logRetention, err := services.NewLogRetention(localStorage, events)
+	if err != nil {
+		return nil, fmt.Errorf("log retention: %w", err)
+	}
+
+	retentions := []ports.RetentionService{localRetention, r2Retention}
+	for _, opened := range replicas {
+		replicaRetention, err := services.NewReplicaRetention(opened.target.Name, opened.storage, events)
+		if err != nil {
+			return nil, fmt.Errorf("retention for replica %s: %w", opened.target.Name, err)
+		}
+		retentions = append(retentions, replicaRetention)
+	}
+	return append(retentions, logRetention), nil
+}
+
+// runRetention prints what retention would delete against the current remote manifest
+// Nothing is deleted and no manifest is changed
+func runRetention(ctx context.Context, env *commandEnv) {
+	if len(env.args) != 1 || env.args[0] != config.RetentionPlanAction {
+		fmt.Printf("Usage: ritual %s %s\n", config.RetentionCommand, config.RetentionPlanAction)
+		return
+	}
+
+	retentions, err := newRetentions(env.localStorage, env.remoteStorage, env.replicas, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create retention services: %v\n", err)
+		return
+	}
+
+	manifest, err := env.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		fmt.Printf("Failed to get remote manifest: %v\n", err)
+		return
+	}
+
+	plans := make([]*domain.RetentionPlan, 0, len(retentions))
+	for _, retention := range retentions {
+		plan, err := retention.Plan(ctx, manifest)
+		if err != nil {
+			fmt.Printf("Failed to plan retention: %v\n", err)
+			return
+		}
+		plans = append(plans, plan)
+	}
+
+	printRetentionPlans(os.Stdout, plans)
+}
+
+// printRetentionPlans writes each plan as a table of kept and deleted objects with a summary
+// Unreferenced chunks are summarized in a single line
+func printRetentionPlans(w io.Writer, plans []*domain.RetentionPlan) {
+	var total int64
+	for _, plan := range plans {
+		fmt.Fprintf(w, "%s (%s)\n", plan.Scope, plan.Policy)
+
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		var chunks int
+		var chunkBytes int64
+		for _, item := range plan.Items {
+			if item.Delete && len(item.Reasons) == 1 && item.Reasons[0] == domain.RetentionUnreferenced {
+				chunks++
+				chunkBytes += item.Size
+				continue
+			}
+			action := "keep"
+			if item.Delete {
+				action = "delete"
+			}
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\n", action, item.Key, formatBytes(item.Size), strings.Join(item.Reasons, ", "))
+		}
+		if chunks > 0 {
+			fmt.Fprintf(table, "  delete\t%d chunks\t%s\t%s\n", chunks, formatBytes(chunkBytes), domain.RetentionUnreferenced)
+		}
+		table.Flush()
+
+		deletions := plan.Deletions()
+		fmt.Fprintf(w, "  %d to delete, %s reclaimed\n\n", len(deletions), formatBytes(plan.ReclaimedBytes()))
+		total += plan.ReclaimedBytes()
+	}
+	fmt.Fprintf(w, "Total reclaimed: %s (dry run, nothing was deleted)\n", formatBytes(total))
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for value := n / unit; value >= unit; value /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
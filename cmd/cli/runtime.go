@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"ritual/internal/config"
+)
+
+// loadRuntime layers the build-time values, config file, environment and flags in args
+// Returns the effective configuration and args with the configuration flags removed
+func loadRuntime(args []string) (*config.Runtime, []string, error) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	loader := config.RuntimeLoader{
+		Build: map[string]string{
+			config.KeyAccountID:       envAccountID,
+			config.KeyAccessKeyID:     envAccessKeyID,
+			config.KeySecretAccessKey: envSecretAccessKey,
+			config.KeyBucket:          envBucket,
+			config.KeyEndpoint:        envEndpoint,
+			config.KeyRegion:          envRegion,
+			config.KeyPathStyle:       envPathStyle,
+			config.KeyTLSInsecure:     envTLSInsecure,
+			config.KeyCACertFile:      envCACertFile,
+		},
+		Args: args[1:],
+	}
+
+	rt, rest, err := loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	return rt, append([]string{args[0]}, rest...), nil
+}
+
+// printRuntime writes every effective configuration value and the layer it came from as a table
+func printRuntime(w io.Writer, rt *config.Runtime) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "KEY\tVALUE\tSOURCE\tORIGIN")
+	for _, value := range rt.Report() {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", value.Key, value.Value, value.Source, value.Origin)
+	}
+	table.Flush()
+}
+
+// runtimeSources maps each value not taken from the defaults to its source, for the startup log
+func runtimeSources(rt *config.Runtime) map[string]any {
+	sources := make(map[string]any)
+	for _, value := range rt.Report() {
+		if value.Source != config.SourceDefault {
+			sources[value.Key] = string(value.Source)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// shutdownSignals trigger a graceful shutdown
+// Closing the console window arrives as SIGTERM on Windows and SIGHUP elsewhere
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// newShutdownContexts returns the contexts for the session and exit phases
+// The first signal cancels runCtx: transfers abort and the server stops gracefully,
+// while Exit keeps running with exitCtx to back up and unlock
+// A second signal cancels exitCtx as well
+func newShutdownContexts() (runCtx context.Context, exitCtx context.Context, stop func()) {
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	exitCtx, cancelExit := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, shutdownSignals...)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			fmt.Println("\nShutdown requested, stopping server (press Ctrl+C again to abort backup)")
+			cancelRun()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			fmt.Println("\nShutdown forced, aborting backup")
+			cancelExit()
+		case <-done:
+		}
+	}()
+
+	stop = func() {
+		signal.Stop(signals)
+		close(done)
+		cancelRun()
+		cancelExit()
+	}
+
+	return runCtx, exitCtx, stop
+}
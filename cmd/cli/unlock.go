@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ritual/internal/core/services"
+)
+
+// runUnlock breaks an orphaned manifest lock after confirmation
+func runUnlock(ctx context.Context, env *commandEnv) {
+	breaker, err := services.NewLockBreaker(env.librarian, env.remoteStorage, env.events)
+	if err != nil {
+		fmt.Printf("Failed to create lock breaker: %v\n", err)
+		return
+	}
+
+	record, err := breaker.Break(ctx)
+	switch {
+	case errors.Is(err, services.ErrManifestNotLocked):
+		fmt.Println("Manifest is not locked, nothing to do")
+	case errors.Is(err, services.ErrLockBreakDeclined):
+		fmt.Println("Lock left in place")
+	case err != nil && record == nil:
+		fmt.Printf("Unlock failed: %v\n", err)
+	case err != nil:
+		fmt.Printf("Lock %s broken, but: %v\n", record.LockID, err)
+	default:
+		fmt.Printf("Lock %s held by %s broken\n", record.LockID, record.Holder)
+	}
+}
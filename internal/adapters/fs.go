@@ -2,22 +2,36 @@ package adapters
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"ritual/internal/adapters/streamer"
 	"ritual/internal/core/ports"
 	"strings"
+	"sync"
 )
 
 const (
 	ErrOpenRootDir = "failed to open root directory %s: %w"
 )
 
+// fsVersionTempSuffix marks the temporary file written before a conditional replace
+const fsVersionTempSuffix = ".tmp"
+
+// Compile-time check to ensure FSRepository supports conditional writes
+var _ ports.VersionedStorage = (*FSRepository)(nil)
+
+// Compile-time check to ensure FSRepository reports sizes while listing
+var _ ports.SizedLister = (*FSRepository)(nil)
+
 // FSRepository implements StorageRepository using local filesystem
 type FSRepository struct {
 	root *os.Root
+	mu   sync.Mutex // Serializes conditional writes within this process
 }
 
 // NewFSRepository creates a new filesystem storage repository
@@ -37,7 +51,7 @@ func (f *FSRepository) Get(ctx context.Context, key string) ([]byte, error) {
 	file, err := f.root.Open(key)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("key not found: %s", key)
+			return nil, fmt.Errorf("%w: %s", ports.ErrNotFound, key)
 		}
 		return nil, fmt.Errorf("failed to read file %s: %w", key, err)
 	}
@@ -84,6 +98,77 @@ func (f *FSRepository) Put(ctx context.Context, key string, data []byte) error {
 	return nil
 }
 
+// GetWithVersion retrieves data together with its SHA-256 content hash as version
+func (f *FSRepository) GetWithVersion(ctx context.Context, key string) ([]byte, string, error) {
+	data, err := f.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentVersion(data), nil
+}
+
+// PutIfVersion writes data only if the current content hash still equals version
+// An empty version writes only if key does not exist
+// The check is exclusive within this process; the replace itself is an atomic rename
+func (f *FSRepository) PutIfVersion(ctx context.Context, key string, data []byte, version string) (string, error) {
+	key = filepath.FromSlash(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := filepath.Dir(key)
+	if dir != "." {
+		if err := f.root.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	if version == "" {
+		// Exclusive create fails if another writer got there first
+		file, err := f.root.OpenFile(key, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				return "", fmt.Errorf("%w: %s already exists", ports.ErrVersionConflict, key)
+			}
+			return "", fmt.Errorf("failed to create file %s: %w", key, err)
+		}
+		defer file.Close()
+
+		if _, err := file.Write(data); err != nil {
+			return "", fmt.Errorf("failed to write file %s: %w", key, err)
+		}
+		return contentVersion(data), nil
+	}
+
+	current, err := f.root.ReadFile(key)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s no longer exists", ports.ErrVersionConflict, key)
+		}
+		return "", fmt.Errorf("failed to read file %s: %w", key, err)
+	}
+	if contentVersion(current) != version {
+		return "", fmt.Errorf("%w: %s was modified concurrently", ports.ErrVersionConflict, key)
+	}
+
+	tempKey := key + fsVersionTempSuffix
+	if err := f.root.WriteFile(tempKey, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %w", tempKey, err)
+	}
+	if err := f.root.Rename(tempKey, key); err != nil {
+		f.root.Remove(tempKey)
+		return "", fmt.Errorf("failed to replace file %s: %w", key, err)
+	}
+
+	return contentVersion(data), nil
+}
+
+// contentVersion returns the hex SHA-256 of data
+func contentVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // Delete removes data by key from filesystem
 func (f *FSRepository) Delete(ctx context.Context, key string) error {
 	key = filepath.FromSlash(key)
@@ -91,7 +176,7 @@ func (f *FSRepository) Delete(ctx context.Context, key string) error {
 	file, err := f.root.Open(key)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("key not found: %s", key)
+			return fmt.Errorf("%w: %s", ports.ErrNotFound, key)
 		}
 		return fmt.Errorf("failed to open %s: %w", key, err)
 	}
@@ -110,7 +195,7 @@ func (f *FSRepository) Delete(ctx context.Context, key string) error {
 		// For files, use the existing Remove method
 		if err := f.root.Remove(key); err != nil {
 			if os.IsNotExist(err) {
-				return fmt.Errorf("key not found: %s", key)
+				return fmt.Errorf("%w: %s", ports.ErrNotFound, key)
 			}
 			return fmt.Errorf("failed to delete file %s: %w", key, err)
 		}
@@ -121,8 +206,40 @@ func (f *FSRepository) Delete(ctx context.Context, key string) error {
 
 // List returns all keys with the given prefix from filesystem
 func (f *FSRepository) List(ctx context.Context, prefix string) ([]string, error) {
-	var keys []string
+	entries, err := f.list(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.key)
+	}
+	return keys, nil
+}
+
+// ListSizes returns the size of every key with the given prefix from filesystem
+func (f *FSRepository) ListSizes(ctx context.Context, prefix string) (map[string]int64, error) {
+	entries, err := f.list(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		sizes[entry.key] = entry.size
+	}
+	return sizes, nil
+}
 
+// listEntry is a listed key with its file size
+type listEntry struct {
+	key  string
+	size int64
+}
+
+// list reads the entries under prefix, or prefix itself if it is a file
+func (f *FSRepository) list(prefix string) ([]listEntry, error) {
 	if prefix == "" {
 		prefix = "."
 	} else {
@@ -132,7 +249,7 @@ func (f *FSRepository) List(ctx context.Context, prefix string) ([]string, error
 	file, err := f.root.Open(prefix)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []string{}, nil
+			return []listEntry{}, nil
 		}
 		return nil, fmt.Errorf("failed to open directory %s: %w", prefix, err)
 	}
@@ -144,20 +261,21 @@ func (f *FSRepository) List(ctx context.Context, prefix string) ([]string, error
 	}
 
 	if !info.IsDir() {
-		return []string{prefix}, nil
+		return []listEntry{{key: prefix, size: info.Size()}}, nil
 	}
 
-	entries, err := file.Readdir(0)
+	infos, err := file.Readdir(0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory %s: %w", prefix, err)
 	}
 
-	for _, entry := range entries {
+	entries := make([]listEntry, 0, len(infos))
+	for _, entry := range infos {
 		entryPath := strings.ReplaceAll(filepath.Join(prefix, entry.Name()), "\\", "/")
-		keys = append(keys, entryPath)
+		entries = append(entries, listEntry{key: entryPath, size: entry.Size()})
 	}
 
-	return keys, nil
+	return entries, nil
 }
 
 // Copy copies data from source key to destination key
@@ -346,6 +464,68 @@ func (f *FSRepository) copyDirectory(ctx context.Context, sourceDir string, dest
 	return nil
 }
 
+// Upload streams body to key, replacing any existing file only once the stream completed
+// bucket is ignored; the repository root plays its part. Lets a directory serve as a backup target
+func (f *FSRepository) Upload(ctx context.Context, bucket, key string, body io.Reader, estimatedSize int64) (int64, error) {
+	if ctx == nil {
+		return 0, errors.New("context cannot be nil")
+	}
+	key = filepath.FromSlash(key)
+	dir := filepath.Dir(key)
+	if dir != "." {
+		if err := f.root.MkdirAll(dir, 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	tempKey := key + fsVersionTempSuffix
+	file, err := f.root.Create(tempKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file %s: %w", tempKey, err)
+	}
+
+	written, err := io.Copy(file, contextReader{ctx: ctx, r: body})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		f.root.Remove(tempKey)
+		return 0, fmt.Errorf("failed to write file %s: %w", key, err)
+	}
+	if err := f.root.Rename(tempKey, key); err != nil {
+		f.root.Remove(tempKey)
+		return 0, fmt.Errorf("failed to replace file %s: %w", key, err)
+	}
+
+	return written, nil
+}
+
+// Download opens key for streaming; bucket is ignored
+func (f *FSRepository) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	key = filepath.FromSlash(key)
+	file, err := f.root.Open(key)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ports.ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to open file %s: %w", key, err)
+	}
+	return file, nil
+}
+
+// contextReader stops a copy once ctx is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // Close closes the root filesystem
 func (f *FSRepository) Close() error {
 	return f.root.Close()
@@ -353,3 +533,9 @@ func (f *FSRepository) Close() error {
 
 // Ensure FSRepository implements StorageRepository interface
 var _ ports.StorageRepository = (*FSRepository)(nil)
+
+// Ensure FSRepository can serve as a streaming backup target
+var (
+	_ streamer.S3StreamUploader   = (*FSRepository)(nil)
+	_ streamer.S3StreamDownloader = (*FSRepository)(nil)
+)
@@ -2,13 +2,18 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
+
+	"ritual/internal/core/ports"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +42,7 @@ func TestFSRepository_Get(t *testing.T) {
 	t.Run("key not found", func(t *testing.T) {
 		_, err := repo.Get(ctx, "nonexistent/key")
 		assert.Error(t, err, "Expected error for nonexistent key")
+		assert.ErrorIs(t, err, ports.ErrNotFound)
 	})
 }
 
@@ -177,6 +183,26 @@ func TestFSRepository_List(t *testing.T) {
 	})
 }
 
+func TestFSRepository_ListSizes(t *testing.T) {
+	ctx := context.Background()
+	root, err := os.OpenRoot(t.TempDir())
+	assert.NoError(t, err)
+	repo, err := NewFSRepository(root)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.NoError(t, repo.Put(ctx, "worlds/1.tar", []byte("12345")))
+	assert.NoError(t, repo.Put(ctx, "worlds/2.tar", []byte("123")))
+
+	sizes, err := repo.ListSizes(ctx, "worlds")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"worlds/1.tar": 5, "worlds/2.tar": 3}, sizes)
+
+	sizes, err = repo.ListSizes(ctx, "nonexistent")
+	assert.NoError(t, err)
+	assert.Empty(t, sizes)
+}
+
 func TestFSRepository_Copy(t *testing.T) {
 	ctx := context.Background()
 	tempDir := t.TempDir()
@@ -612,3 +638,133 @@ func TestFSRepository_SpacesInDirectoryNames(t *testing.T) {
 		assert.Equal(t, string(data), string(retrievedData), "Leading spaces data mismatch")
 	})
 }
+
+func TestFSRepository_VersionedWrites(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	root, err := os.OpenRoot(tempDir)
+	assert.NoError(t, err)
+	repo, err := NewFSRepository(root)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	t.Run("create only when version is empty", func(t *testing.T) {
+		version, err := repo.PutIfVersion(ctx, "cas/create.json", []byte("v1"), "")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, version)
+
+		_, err = repo.PutIfVersion(ctx, "cas/create.json", []byte("v2"), "")
+		assert.ErrorIs(t, err, ports.ErrVersionConflict)
+
+		data, err := repo.Get(ctx, "cas/create.json")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", string(data))
+	})
+
+	t.Run("replace when version matches", func(t *testing.T) {
+		assert.NoError(t, repo.Put(ctx, "cas/replace.json", []byte("v1")))
+
+		data, version, err := repo.GetWithVersion(ctx, "cas/replace.json")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", string(data))
+
+		newVersion, err := repo.PutIfVersion(ctx, "cas/replace.json", []byte("v2"), version)
+		assert.NoError(t, err)
+		assert.NotEqual(t, version, newVersion)
+
+		_, current, err := repo.GetWithVersion(ctx, "cas/replace.json")
+		assert.NoError(t, err)
+		assert.Equal(t, newVersion, current)
+
+		_, err = os.Stat(filepath.Join(tempDir, "cas", "replace.json"+fsVersionTempSuffix))
+		assert.True(t, os.IsNotExist(err), "temporary file should be renamed away")
+	})
+
+	t.Run("stale version conflicts", func(t *testing.T) {
+		assert.NoError(t, repo.Put(ctx, "cas/stale.json", []byte("v1")))
+		_, version, err := repo.GetWithVersion(ctx, "cas/stale.json")
+		assert.NoError(t, err)
+
+		// Another writer changes the object after our read
+		assert.NoError(t, repo.Put(ctx, "cas/stale.json", []byte("other")))
+
+		_, err = repo.PutIfVersion(ctx, "cas/stale.json", []byte("v2"), version)
+		assert.ErrorIs(t, err, ports.ErrVersionConflict)
+
+		data, err := repo.Get(ctx, "cas/stale.json")
+		assert.NoError(t, err)
+		assert.Equal(t, "other", string(data))
+	})
+
+	t.Run("missing object with version conflicts", func(t *testing.T) {
+		_, err := repo.PutIfVersion(ctx, "cas/missing.json", []byte("v1"), "deadbeef")
+		assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	})
+
+	t.Run("concurrent writers with same version", func(t *testing.T) {
+		assert.NoError(t, repo.Put(ctx, "cas/race.json", []byte("v0")))
+		_, version, err := repo.GetWithVersion(ctx, "cas/race.json")
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+		results := make([]error, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, results[i] = repo.PutIfVersion(ctx, "cas/race.json", []byte(fmt.Sprintf("writer-%d", i)), version)
+			}(i)
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range results {
+			if err == nil {
+				succeeded++
+			} else {
+				assert.ErrorIs(t, err, ports.ErrVersionConflict)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+}
+
+func TestFSRepository_StreamingTarget(t *testing.T) {
+	ctx := context.Background()
+	root, err := os.OpenRoot(t.TempDir())
+	assert.NoError(t, err)
+	repo, err := NewFSRepository(root)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	t.Run("upload then download", func(t *testing.T) {
+		written, err := repo.Upload(ctx, "ignored", "worlds/1.tar", strings.NewReader("archive"), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len("archive")), written)
+
+		body, err := repo.Download(ctx, "ignored", "worlds/1.tar")
+		assert.NoError(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Equal(t, "archive", string(data))
+	})
+
+	t.Run("failed upload keeps the existing file", func(t *testing.T) {
+		assert.NoError(t, repo.Put(ctx, "worlds/2.tar", []byte("old")))
+
+		_, err := repo.Upload(ctx, "ignored", "worlds/2.tar", io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("dropped"))), 0)
+		assert.Error(t, err)
+
+		data, err := repo.Get(ctx, "worlds/2.tar")
+		assert.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+		_, err = os.Stat(filepath.Join(root.Name(), "worlds", "2.tar"+fsVersionTempSuffix))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := repo.Download(ctx, "ignored", "worlds/missing.tar")
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+}
@@ -0,0 +1,444 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManagedServerRunner error constants
+var (
+	ErrManagedRunnerNil    = errors.New("managed server runner cannot be nil")
+	ErrServerNotRunning    = errors.New("server is not running")
+	ErrServerAlreadyActive = errors.New("server is already running")
+	ErrServerStopTimeout   = errors.New("server did not stop in time and was killed")
+)
+
+// managedOutputWaitDelay bounds how long Wait keeps copying output after the process exits
+const managedOutputWaitDelay = 5 * time.Second
+
+// Server launch commands
+const (
+	windowsShellCommand = "cmd"
+	shellCommand        = "sh"
+	javaCommand         = "java"
+)
+
+// Compile-time checks to ensure ManagedServerRunner implements the server ports
+var _ ports.ServerRunner = (*ManagedServerRunner)(nil)
+var _ ports.ServerConsole = (*ManagedServerRunner)(nil)
+
+// ManagedServerRunner owns the process started by the instance start script
+// It keeps the console stdin open for commands, streams output lines as events
+// and tees them into logs/server.log
+// With RCON enabled commands go over RCON and fall back to the console while it is unreachable
+// On Windows the script runs in ritual's own console rather than a separate window
+type ManagedServerRunner struct {
+	workRoot    *os.Root
+	startScript string
+	events      chan<- ports.Event
+	goos        string                                      // Platform the start script is launched for (replaceable in tests)
+	newCommand  func(name string, args ...string) *exec.Cmd // Process factory (replaceable in tests)
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	done   chan struct{}  // Closed when the running process has exited
+	killed bool           // Set when Stop had to kill the process
+	server *domain.Server // Server of the running process
+
+	rcon          ports.RemoteConsole // nil = commands only go to the console
+	rconMu        sync.Mutex          // Serializes RCON commands and guards rconConnected
+	rconConnected bool
+}
+
+// NewManagedServerRunner creates a new ManagedServerRunner instance
+// startScript is the path to the start script (or server jar) relative to ritual root
+func NewManagedServerRunner(workRoot *os.Root, startScript string, events chan<- ports.Event) (*ManagedServerRunner, error) {
+	if workRoot == nil {
+		return nil, fmt.Errorf("workRoot cannot be nil")
+	}
+	if startScript == "" {
+		return nil, fmt.Errorf("start script cannot be empty")
+	}
+
+	return &ManagedServerRunner{
+		workRoot:    workRoot,
+		startScript: startScript,
+		events:      events,
+		goos:        runtime.GOOS,
+		newCommand:  exec.Command,
+	}, nil
+}
+
+// EnableRcon makes the runner enable RCON on the server and send commands through client
+// Must be called before Run
+func (r *ManagedServerRunner) EnableRcon(client ports.RemoteConsole) error {
+	if r == nil {
+		return ErrManagedRunnerNil
+	}
+	if client == nil {
+		return ErrRconNil
+	}
+	r.rcon = client
+	return nil
+}
+
+// send safely sends an event to the channel
+func (r *ManagedServerRunner) send(evt ports.Event) {
+	ports.SendEvent(r.events, evt)
+}
+
+// Run starts the server through the start script and blocks until it exits
+func (r *ManagedServerRunner) Run(server *domain.Server) error {
+	if r == nil {
+		return ErrManagedRunnerNil
+	}
+	if server == nil {
+		return fmt.Errorf("server cannot be nil")
+	}
+
+	launchPath, err := resolveLaunchPath(r.workRoot, r.startScript, r.goos)
+	if err != nil {
+		return err
+	}
+
+	// Update server.properties with address and RCON settings before starting
+	if err := applyServerConfig(r.workRoot, r.startScript, server, r.rcon != nil); err != nil {
+		return fmt.Errorf("failed to update server.properties: %w", err)
+	}
+
+	logFile, err := createServerLog(r.workRoot)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	rootPath := r.workRoot.Name()
+	launch := buildLaunchCommand(r.goos, filepath.Join(rootPath, launchPath), server.Memory)
+	cmd := r.newCommand(launch.name, launch.args...)
+	cmd.Dir = filepath.Join(rootPath, filepath.Dir(launchPath))
+	cmd.WaitDelay = managedOutputWaitDelay
+	prepareProcess(cmd, launch.cmdLine)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open server console: %w", err)
+	}
+
+	// stdout and stderr share one pipe so lines keep their original order
+	outputReader, outputWriter := io.Pipe()
+	cmd.Stdout = outputWriter
+	cmd.Stderr = outputWriter
+
+	done := make(chan struct{})
+	r.mu.Lock()
+	if r.cmd != nil {
+		r.mu.Unlock()
+		return ErrServerAlreadyActive
+	}
+	if err := cmd.Start(); err != nil {
+		r.mu.Unlock()
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	r.cmd = cmd
+	r.stdin = stdin
+	r.done = done
+	r.killed = false
+	r.server = server
+	r.mu.Unlock()
+
+	r.send(ports.UpdateEvent{Operation: "server", Message: "Server process started", Data: map[string]any{"pid": cmd.Process.Pid}})
+
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		r.streamOutput(outputReader, logFile)
+	}()
+
+	waitErr := cmd.Wait()
+	outputWriter.Close()
+	<-streamDone
+
+	r.mu.Lock()
+	killed := r.killed
+	r.cmd = nil
+	r.stdin = nil
+	r.done = nil
+	r.server = nil
+	r.mu.Unlock()
+	r.closeRcon()
+	close(done)
+
+	if killed {
+		return ErrServerStopTimeout
+	}
+	if waitErr != nil {
+		return fmt.Errorf("server exited with error: %w", waitErr)
+	}
+
+	return nil
+}
+
+// streamOutput forwards each server output line to the log file and the event channel
+func (r *ManagedServerRunner) streamOutput(output io.ReadCloser, logFile io.Writer) {
+	defer output.Close()
+
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if logFile != nil {
+			io.WriteString(logFile, line+"\n")
+		}
+		r.send(ports.UpdateEvent{Operation: "server", Message: line})
+	}
+	if err := scanner.Err(); err != nil {
+		r.send(ports.ErrorEvent{Operation: "server", Err: fmt.Errorf("failed to read server output: %w", err)})
+		// Drain so the process never blocks on a full pipe
+		io.Copy(io.Discard, output)
+	}
+}
+
+// SendCommand sends a command to the running server over RCON or, failing that, its console
+func (r *ManagedServerRunner) SendCommand(command string) error {
+	if r == nil {
+		return ErrManagedRunnerNil
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return errors.New("command cannot be empty")
+	}
+
+	if r.sendRcon(command) {
+		return nil
+	}
+	_, err := r.Console().Write([]byte(command + "\n"))
+	return err
+}
+
+// Console returns a writer connected to the server console stdin
+// Writes fail with ErrServerNotRunning while no server process is active
+func (r *ManagedServerRunner) Console() io.Writer {
+	return consoleWriter{runner: r}
+}
+
+// Stop sends the stop command and waits for the process to exit
+// The console is closed after the command, so a script that pauses once the server exits does not wait for input
+// The process is killed with every process it started if it is still running after timeout
+// Returns nil if no server is running
+func (r *ManagedServerRunner) Stop(timeout time.Duration) error {
+	if r == nil {
+		return ErrManagedRunnerNil
+	}
+
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	r.send(ports.UpdateEvent{Operation: "server", Message: "Stopping server", Data: map[string]any{"timeout": timeout.String()}})
+	if err := r.SendCommand(config.ServerStopCommand); err != nil && !errors.Is(err, ErrServerNotRunning) {
+		r.send(ports.ErrorEvent{Operation: "server", Err: fmt.Errorf("failed to send stop command: %w", err)})
+	}
+	r.closeConsole()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	r.mu.Lock()
+	if r.done != done {
+		// Process exited between the timeout and acquiring the lock
+		r.mu.Unlock()
+		return nil
+	}
+	if r.cmd != nil && r.cmd.Process != nil {
+		r.killed = true
+		if err := killProcessTree(r.cmd); err != nil {
+			r.mu.Unlock()
+			return fmt.Errorf("failed to kill server process: %w", err)
+		}
+	}
+	r.mu.Unlock()
+
+	<-done
+	return ErrServerStopTimeout
+}
+
+// sendRcon runs command over RCON, connecting on first use, and reports whether it ran
+// The connection is dropped on failure and opened again for the next command
+func (r *ManagedServerRunner) sendRcon(command string) bool {
+	if r.rcon == nil {
+		return false
+	}
+	r.mu.Lock()
+	server := r.server
+	r.mu.Unlock()
+	if server == nil {
+		return false
+	}
+
+	r.rconMu.Lock()
+	defer r.rconMu.Unlock()
+
+	ctx := context.Background()
+	if !r.rconConnected {
+		if err := r.rcon.Connect(ctx, server.RconAddress(), server.RconPassword); err != nil {
+			r.send(ports.UpdateEvent{Operation: "server", Message: "RCON unavailable, using the console", Data: map[string]any{"error": err.Error()}})
+			return false
+		}
+		r.rconConnected = true
+	}
+
+	response, err := r.rcon.Command(ctx, command)
+	if err != nil {
+		r.send(ports.UpdateEvent{Operation: "server", Message: "RCON command failed, using the console", Data: map[string]any{"error": err.Error()}})
+		r.rcon.Close()
+		r.rconConnected = false
+		return false
+	}
+	if response = strings.TrimSpace(response); response != "" {
+		r.send(ports.UpdateEvent{Operation: "server", Message: response})
+	}
+	return true
+}
+
+// closeRcon closes the RCON connection if one is open
+func (r *ManagedServerRunner) closeRcon() {
+	if r.rcon == nil {
+		return
+	}
+	r.rconMu.Lock()
+	defer r.rconMu.Unlock()
+	if r.rconConnected {
+		r.rcon.Close()
+		r.rconConnected = false
+	}
+}
+
+// closeConsole closes the server stdin; later commands fail with ErrServerNotRunning
+func (r *ManagedServerRunner) closeConsole() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stdin != nil {
+		r.stdin.Close()
+		r.stdin = nil
+	}
+}
+
+// resolveLaunchPath returns the script or jar to launch on goos, relative to workRoot
+// Off Windows, Windows scripts (.bat/.cmd) are substituted with a sibling .sh script,
+// falling back to the server jar in the same directory
+func resolveLaunchPath(workRoot *os.Root, startScript string, goos string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(startScript))
+	if goos == "windows" || (ext != ".bat" && ext != ".cmd") {
+		if _, err := workRoot.Stat(startScript); err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("start script not found at %s", startScript)
+			}
+			return "", fmt.Errorf("failed to check start script at %s: %w", startScript, err)
+		}
+		return startScript, nil
+	}
+
+	candidates := []string{
+		strings.TrimSuffix(startScript, filepath.Ext(startScript)) + ".sh",
+		filepath.Join(filepath.Dir(startScript), config.ServerJarFilename),
+	}
+	for _, candidate := range candidates {
+		if _, err := workRoot.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to check %s: %w", candidate, err)
+		}
+	}
+
+	return "", fmt.Errorf("start script not found: no POSIX alternative for %s", startScript)
+}
+
+// launchCommand is the process that launches the server
+type launchCommand struct {
+	name    string
+	args    []string
+	cmdLine string // Complete Windows command line used verbatim instead of quoting args; empty = quote args
+}
+
+// buildLaunchCommand builds the command for launching the server on goos
+// Jars are run with java directly; scripts get the memory limit as their first argument
+// and run through cmd.exe on Windows and sh elsewhere
+func buildLaunchCommand(goos string, launchPath string, memoryMB int) launchCommand {
+	memoryArg := "-Xmx" + strconv.Itoa(memoryMB) + "M"
+
+	if strings.EqualFold(filepath.Ext(launchPath), ".jar") {
+		return launchCommand{name: javaCommand, args: []string{memoryArg, "-jar", launchPath, "nogui"}}
+	}
+	if goos == "windows" {
+		// Argument quoting does not follow cmd.exe's rules, which split a path with ( ) & or ^
+		// With /S cmd.exe drops only the outer quotes, so the quoted script path stays whole
+		command := `"` + launchPath + `" ` + memoryArg
+		return launchCommand{
+			name:    windowsShellCommand,
+			args:    []string{"/S", "/C", command},
+			cmdLine: windowsShellCommand + ` /S /C "` + command + `"`,
+		}
+	}
+
+	return launchCommand{name: shellCommand, args: []string{launchPath, memoryArg}}
+}
+
+// createServerLog creates (truncates) logs/server.log under workRoot
+func createServerLog(workRoot *os.Root) (*os.File, error) {
+	if workRoot == nil {
+		return nil, fmt.Errorf("workRoot cannot be nil")
+	}
+	if err := workRoot.MkdirAll(config.LogsDir, config.DirPermission); err != nil {
+		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	file, err := workRoot.Create(filepath.Join(config.LogsDir, config.ServerLogFilename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server log: %w", err)
+	}
+
+	return file, nil
+}
+
+// consoleWriter writes raw bytes to the server stdin under the runner lock
+type consoleWriter struct {
+	runner *ManagedServerRunner
+}
+
+func (w consoleWriter) Write(p []byte) (int, error) {
+	if w.runner == nil {
+		return 0, ErrManagedRunnerNil
+	}
+
+	w.runner.mu.Lock()
+	defer w.runner.mu.Unlock()
+
+	if w.runner.stdin == nil {
+		return 0, ErrServerNotRunning
+	}
+	n, err := w.runner.stdin.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("failed to write to server console: %w", err)
+	}
+	return n, nil
+}
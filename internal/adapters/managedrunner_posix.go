@@ -0,0 +1,26 @@
+//go:build !windows
+
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// prepareProcess puts the server in a process group of its own, so Stop can kill
+// the java process a start script launched along with the script itself
+// Terminal signals such as Ctrl+C no longer reach the server directly; ritual stops it
+// cmdLine is only set for Windows and is ignored here
+func prepareProcess(cmd *exec.Cmd, cmdLine string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessTree kills the process group started for cmd
+func killProcessTree(cmd *exec.Cmd) error {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to kill process group %d: %w", cmd.Process.Pid, err)
+	}
+	return nil
+}
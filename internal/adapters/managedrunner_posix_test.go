@@ -0,0 +1,98 @@
+//go:build !windows
+
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processAlive reports whether pid is running; an exited process waiting to be reaped is not
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	return err != nil || !strings.Contains(string(stat), ") Z ")
+}
+
+func TestManagedServerRunner_StopKillsScriptChildren(t *testing.T) {
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { workRoot.Close() })
+
+	// The script starts the server without exec, so killing the shell alone would orphan it
+	pidFile := filepath.Join(tempDir, "server.pid")
+	instanceDir := filepath.Join(tempDir, config.InstanceDir)
+	require.NoError(t, os.MkdirAll(instanceDir, 0755))
+	script := "#!/bin/sh\n" +
+		fakeServerEnv + "=ignore-stop " + fakeServerPidEnv + "='" + pidFile + "' '" + os.Args[0] + "' -test.run=TestManagedRunnerHelperProcess\n" +
+		"echo script finished\n"
+	require.NoError(t, os.WriteFile(filepath.Join(instanceDir, "run.sh"), []byte(script), 0755))
+
+	runner, err := NewManagedServerRunner(workRoot, filepath.Join(config.InstanceDir, "run.sh"), nil)
+	require.NoError(t, err)
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- runner.Run(server) }()
+
+	var pid int
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(pidFile)
+		if err != nil {
+			return false
+		}
+		pid, err = strconv.Atoi(string(data))
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	assert.ErrorIs(t, runner.Stop(200*time.Millisecond), ErrServerStopTimeout)
+	assert.ErrorIs(t, <-runErr, ErrServerStopTimeout)
+	assert.Eventually(t, func() bool { return !processAlive(pid) }, 5*time.Second, 10*time.Millisecond, "the server started by the script is killed too")
+}
+
+func TestManagedServerRunner_RunShellScript(t *testing.T) {
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { workRoot.Close() })
+
+	// A quote in the path stays part of a single argument
+	instanceDir := filepath.Join(tempDir, config.InstanceDir, "it's here")
+	require.NoError(t, os.MkdirAll(instanceDir, 0755))
+	script := "#!/bin/sh\necho \"started with $*\"\necho 'WARN something' >&2\n"
+	require.NoError(t, os.WriteFile(filepath.Join(instanceDir, "run.sh"), []byte(script), 0755))
+
+	runner, err := NewManagedServerRunner(workRoot, filepath.Join(config.InstanceDir, "it's here", "run.sh"), nil)
+	require.NoError(t, err)
+
+	server, err := domain.NewServer("127.0.0.1:25566", 2048)
+	require.NoError(t, err)
+	require.NoError(t, runner.Run(server))
+
+	// Output is teed into the server log
+	logContent, err := os.ReadFile(filepath.Join(tempDir, config.LogsDir, config.ServerLogFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(logContent), "started with -Xmx2048M")
+	assert.Contains(t, string(logContent), "WARN something")
+
+	propsContent, err := os.ReadFile(filepath.Join(instanceDir, "server.properties"))
+	require.NoError(t, err)
+	assert.Contains(t, string(propsContent), "server-ip=127.0.0.1")
+	assert.Contains(t, string(propsContent), "server-port=25566")
+}
@@ -0,0 +1,476 @@
+package adapters
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Environment of the fake server helper process
+const (
+	fakeServerEnv    = "RITUAL_FAKE_SERVER"     // Selects the behaviour
+	fakeServerPidEnv = "RITUAL_FAKE_SERVER_PID" // File the helper writes its PID to, if set
+)
+
+// TestManagedRunnerHelperProcess acts as a fake Minecraft server for ManagedServerRunner tests
+func TestManagedRunnerHelperProcess(t *testing.T) {
+	mode := os.Getenv(fakeServerEnv)
+	if mode == "" {
+		return
+	}
+	if pidFile := os.Getenv(fakeServerPidEnv); pidFile != "" {
+		os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+	}
+
+	fmt.Println(`Done (1.234s)! For help, type "help"`)
+	fmt.Fprintln(os.Stderr, "WARN fake server warning")
+	if mode == "crash" {
+		os.Exit(3)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Println("> " + line)
+		// The pause mode keeps reading like a start script that pauses after the server exits
+		if line == "stop" && mode != "ignore-stop" && mode != "pause" {
+			fmt.Println("Stopping server")
+			os.Exit(0)
+		}
+	}
+	// Keep running after stdin closes so only a kill ends the ignore-stop mode
+	if mode == "ignore-stop" {
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// newFakeManagedRunner creates a ManagedServerRunner whose java process is the fake server helper
+func newFakeManagedRunner(t *testing.T, mode string, events chan<- ports.Event) (*ManagedServerRunner, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { workRoot.Close() })
+
+	instanceDir := filepath.Join(tempDir, config.InstanceDir)
+	require.NoError(t, os.MkdirAll(instanceDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(instanceDir, config.ServerJarFilename), []byte("jar"), 0644))
+
+	runner, err := NewManagedServerRunner(workRoot, filepath.Join(config.InstanceDir, "run.bat"), events)
+	require.NoError(t, err)
+
+	// run.bat falls back to the jar on every platform, so the helper is launched as java
+	runner.goos = "linux"
+	runner.newCommand = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestManagedRunnerHelperProcess", "--", name}, args...)...)
+		cmd.Env = append(os.Environ(), fakeServerEnv+"="+mode)
+		return cmd
+	}
+
+	return runner, tempDir
+}
+
+// collectEvents drains events into a slice until the channel is closed
+func collectEvents(events <-chan ports.Event) (*[]ports.Event, *sync.WaitGroup) {
+	var collected []ports.Event
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for evt := range events {
+			collected = append(collected, evt)
+		}
+	}()
+	return &collected, &wg
+}
+
+// waitForRunning blocks until the runner has an active process
+func waitForRunning(t *testing.T, runner *ManagedServerRunner) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		runner.mu.Lock()
+		defer runner.mu.Unlock()
+		return runner.stdin != nil
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestNewManagedServerRunner(t *testing.T) {
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	defer workRoot.Close()
+
+	runner, err := NewManagedServerRunner(workRoot, "instance/run.bat", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, runner)
+
+	_, err = NewManagedServerRunner(nil, "instance/run.bat", nil)
+	assert.ErrorContains(t, err, "workRoot cannot be nil")
+
+	_, err = NewManagedServerRunner(workRoot, "", nil)
+	assert.ErrorContains(t, err, "start script cannot be empty")
+}
+
+func TestManagedServerRunner_RunAndStop(t *testing.T) {
+	events := make(chan ports.Event, 100)
+	collected, wg := collectEvents(events)
+	runner, tempDir := newFakeManagedRunner(t, "normal", events)
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- runner.Run(server) }()
+
+	waitForRunning(t, runner)
+	require.NoError(t, runner.SendCommand("say hello"))
+	require.NoError(t, runner.Stop(10*time.Second))
+	require.NoError(t, <-runErr)
+
+	close(events)
+	wg.Wait()
+
+	var lines []string
+	for _, evt := range *collected {
+		if update, ok := evt.(ports.UpdateEvent); ok && update.Operation == "server" {
+			lines = append(lines, update.Message)
+		}
+	}
+	assert.Contains(t, lines, `Done (1.234s)! For help, type "help"`)
+	assert.Contains(t, lines, "WARN fake server warning")
+	assert.Contains(t, lines, "> say hello")
+	assert.Contains(t, lines, "Stopping server")
+
+	logContent, err := os.ReadFile(filepath.Join(tempDir, config.LogsDir, config.ServerLogFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(logContent), "> say hello")
+
+	propsContent, err := os.ReadFile(filepath.Join(tempDir, config.InstanceDir, "server.properties"))
+	require.NoError(t, err)
+	assert.Contains(t, string(propsContent), "server-ip=127.0.0.1")
+
+	// Console is closed once the process is gone
+	assert.ErrorIs(t, runner.SendCommand("list"), ErrServerNotRunning)
+	assert.NoError(t, runner.Stop(time.Second))
+}
+
+// serverLines returns the messages of the server update events
+func serverLines(collected []ports.Event) []string {
+	var lines []string
+	for _, evt := range collected {
+		if update, ok := evt.(ports.UpdateEvent); ok && update.Operation == "server" {
+			lines = append(lines, update.Message)
+		}
+	}
+	return lines
+}
+
+func TestManagedServerRunner_Rcon(t *testing.T) {
+	t.Run("commands and stop go over RCON", func(t *testing.T) {
+		rcon := newFakeRconServer(t, "secret", map[string]string{"save-off": "Automatic saving is now disabled"})
+		_, port, err := net.SplitHostPort(rcon.address())
+		require.NoError(t, err)
+
+		events := make(chan ports.Event, 100)
+		collected, wg := collectEvents(events)
+		runner, tempDir := newFakeManagedRunner(t, "normal", events)
+		client, err := NewRconClient(5 * time.Second)
+		require.NoError(t, err)
+		require.NoError(t, runner.EnableRcon(client))
+
+		server, err := domain.NewServer("127.0.0.1:25565", 1024)
+		require.NoError(t, err)
+		server.RconPort, err = strconv.Atoi(port)
+		require.NoError(t, err)
+		server.RconPassword = "secret"
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- runner.Run(server) }()
+
+		waitForRunning(t, runner)
+		require.NoError(t, runner.SendCommand("save-off"))
+		assert.Equal(t, "save-off", <-rcon.commands)
+		require.NoError(t, runner.Stop(10*time.Second))
+		assert.Equal(t, config.ServerStopCommand, <-rcon.commands)
+		require.NoError(t, <-runErr)
+
+		close(events)
+		wg.Wait()
+
+		lines := serverLines(*collected)
+		assert.Contains(t, lines, "Automatic saving is now disabled", "responses are reported as server output")
+		assert.NotContains(t, lines, "> save-off", "the console is not used while RCON answers")
+
+		propsContent, err := os.ReadFile(filepath.Join(tempDir, config.InstanceDir, "server.properties"))
+		require.NoError(t, err)
+		assert.Contains(t, string(propsContent), "enable-rcon=true")
+		assert.Contains(t, string(propsContent), "rcon.password=secret")
+	})
+
+	t.Run("unreachable RCON falls back to the console", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		closedPort := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		events := make(chan ports.Event, 100)
+		collected, wg := collectEvents(events)
+		runner, _ := newFakeManagedRunner(t, "normal", events)
+		client, err := NewRconClient(5 * time.Second)
+		require.NoError(t, err)
+		require.NoError(t, runner.EnableRcon(client))
+
+		server, err := domain.NewServer("127.0.0.1:25565", 1024)
+		require.NoError(t, err)
+		server.RconPort = closedPort
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- runner.Run(server) }()
+
+		waitForRunning(t, runner)
+		require.NoError(t, runner.SendCommand("say hello"))
+		require.NoError(t, runner.Stop(10*time.Second))
+		require.NoError(t, <-runErr)
+
+		close(events)
+		wg.Wait()
+
+		lines := serverLines(*collected)
+		assert.Contains(t, lines, "RCON unavailable, using the console")
+		assert.Contains(t, lines, "> say hello")
+		assert.Contains(t, lines, "Stopping server")
+	})
+
+	t.Run("rejects a nil client", func(t *testing.T) {
+		runner, _ := newFakeManagedRunner(t, "normal", nil)
+		assert.ErrorIs(t, runner.EnableRcon(nil), ErrRconNil)
+	})
+}
+
+func TestManagedServerRunner_StopKillsAfterTimeout(t *testing.T) {
+	runner, _ := newFakeManagedRunner(t, "ignore-stop", nil)
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- runner.Run(server) }()
+
+	waitForRunning(t, runner)
+	start := time.Now()
+	err = runner.Stop(200 * time.Millisecond)
+	assert.ErrorIs(t, err, ErrServerStopTimeout)
+	assert.Less(t, time.Since(start), 30*time.Second)
+	assert.ErrorIs(t, <-runErr, ErrServerStopTimeout)
+}
+
+func TestManagedServerRunner_RunReportsCrash(t *testing.T) {
+	runner, _ := newFakeManagedRunner(t, "crash", nil)
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	err = runner.Run(server)
+	assert.ErrorContains(t, err, "server exited with error")
+}
+
+func TestManagedServerRunner_StopClosesConsole(t *testing.T) {
+	runner, _ := newFakeManagedRunner(t, "pause", nil)
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- runner.Run(server) }()
+
+	waitForRunning(t, runner)
+	assert.NoError(t, runner.Stop(10*time.Second), "a paused script exits once the console is closed")
+	assert.NoError(t, <-runErr)
+}
+
+func TestManagedServerRunner_RunScriptNotFound(t *testing.T) {
+	tests := []struct {
+		name        string
+		startScript string
+		wantErr     string
+	}{
+		{name: "missing script", startScript: "run.sh", wantErr: "start script not found"},
+		{name: "bat without POSIX alternative", startScript: "run.bat", wantErr: "no POSIX alternative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, workRoot := setupLaunchInstance(t, "run.bat")
+			runner, err := NewManagedServerRunner(workRoot, filepath.Join(config.InstanceDir, tt.startScript), nil)
+			require.NoError(t, err)
+			runner.goos = "linux"
+			runner.newCommand = func(name string, args ...string) *exec.Cmd {
+				t.Fatalf("no process is started, got %s %v", name, args)
+				return nil
+			}
+
+			server, err := domain.NewServer("127.0.0.1:25565", 1024)
+			require.NoError(t, err)
+
+			assert.ErrorContains(t, runner.Run(server), tt.wantErr)
+
+			// Nothing is written before the launch path is known
+			_, err = os.Stat(filepath.Join(tempDir, config.InstanceDir, "server.properties"))
+			assert.True(t, os.IsNotExist(err), "server.properties is left alone")
+			_, err = os.Stat(filepath.Join(tempDir, config.LogsDir))
+			assert.True(t, os.IsNotExist(err), "no server log is created")
+		})
+	}
+}
+
+func TestManagedServerRunner_RunUpdatesServerProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		port int
+	}{
+		{name: "standard config", ip: "0.0.0.0", port: 25565},
+		{name: "custom port and IP", ip: "192.168.1.100", port: 25566},
+		{name: "localhost", ip: "127.0.0.1", port: 19132},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, tempDir := newFakeManagedRunner(t, "normal", nil)
+			propsPath := filepath.Join(tempDir, config.InstanceDir, "server.properties")
+			require.NoError(t, os.WriteFile(propsPath, []byte("server-ip=old-ip\nserver-port=12345\nother-setting=value\n"), 0644))
+
+			server, err := domain.NewServer(tt.ip+":"+strconv.Itoa(tt.port), 2048)
+			require.NoError(t, err)
+
+			runErr := make(chan error, 1)
+			go func() { runErr <- runner.Run(server) }()
+
+			// The file is written before the process starts
+			waitForRunning(t, runner)
+			propsContent, err := os.ReadFile(propsPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(propsContent), "server-ip="+tt.ip)
+			assert.Contains(t, string(propsContent), "server-port="+strconv.Itoa(tt.port))
+			assert.Contains(t, string(propsContent), "other-setting=value", "Other settings should be preserved")
+			assert.NotContains(t, string(propsContent), "old-ip", "Old IP should be replaced")
+			assert.NotContains(t, string(propsContent), "12345", "Old port should be replaced")
+
+			require.NoError(t, runner.Stop(10*time.Second))
+			require.NoError(t, <-runErr)
+		})
+	}
+}
+
+func TestManagedServerRunner_RunStartFailure(t *testing.T) {
+	runner, _ := newFakeManagedRunner(t, "normal", nil)
+	runner.newCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command(filepath.Join(t.TempDir(), "missing-java"), args...)
+	}
+
+	server, err := domain.NewServer("127.0.0.1:25565", 1024)
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, runner.Run(server), "failed to start server")
+	assert.ErrorIs(t, runner.SendCommand("list"), ErrServerNotRunning, "a failed start leaves no process behind")
+}
+
+func TestManagedServerRunner_NotRunning(t *testing.T) {
+	var nilRunner *ManagedServerRunner
+	assert.ErrorIs(t, nilRunner.Run(&domain.Server{}), ErrManagedRunnerNil)
+	assert.ErrorIs(t, nilRunner.SendCommand("list"), ErrManagedRunnerNil)
+	assert.ErrorIs(t, nilRunner.Stop(time.Second), ErrManagedRunnerNil)
+
+	runner, _ := newFakeManagedRunner(t, "normal", nil)
+	assert.ErrorIs(t, runner.SendCommand("list"), ErrServerNotRunning)
+	assert.ErrorContains(t, runner.SendCommand("   "), "command cannot be empty")
+	assert.NoError(t, runner.Stop(time.Second))
+	assert.ErrorContains(t, runner.Run(nil), "server cannot be nil")
+}
+
+// setupLaunchInstance creates an instance dir with the given files under a temp work root
+func setupLaunchInstance(t *testing.T, files ...string) (string, *os.Root) {
+	t.Helper()
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { workRoot.Close() })
+
+	instanceDir := filepath.Join(tempDir, config.InstanceDir)
+	require.NoError(t, os.MkdirAll(instanceDir, 0755))
+	for _, name := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(instanceDir, name), []byte("#!/bin/sh"), 0644))
+	}
+
+	return tempDir, workRoot
+}
+
+func TestResolveLaunchPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []string
+		startScript string
+		goos        string
+		want        string
+		wantErr     string
+	}{
+		{name: "shell script", files: []string{"run.sh"}, startScript: "run.sh", goos: "linux", want: "run.sh"},
+		{name: "bat falls back to sibling script", files: []string{"run.bat", "run.sh", config.ServerJarFilename}, startScript: "run.bat", goos: "darwin", want: "run.sh"},
+		{name: "bat falls back to server jar", files: []string{"run.bat", config.ServerJarFilename}, startScript: "run.bat", goos: "linux", want: config.ServerJarFilename},
+		{name: "bat without alternative", files: []string{"run.bat"}, startScript: "run.bat", goos: "linux", wantErr: "no POSIX alternative"},
+		{name: "bat on windows", files: []string{"run.bat", "run.sh"}, startScript: "run.bat", goos: "windows", want: "run.bat"},
+		{name: "missing script", startScript: "run.sh", goos: "linux", wantErr: "start script not found"},
+		{name: "missing script on windows", files: []string{config.ServerJarFilename}, startScript: "run.bat", goos: "windows", wantErr: "start script not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, workRoot := setupLaunchInstance(t, tt.files...)
+
+			got, err := resolveLaunchPath(workRoot, filepath.Join(config.InstanceDir, tt.startScript), tt.goos)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(config.InstanceDir, tt.want), got)
+		})
+	}
+}
+
+func TestBuildLaunchCommand(t *testing.T) {
+	scriptPath := filepath.Join("srv", "it's here", "run.sh")
+	launch := buildLaunchCommand("linux", scriptPath, 2048)
+	assert.Equal(t, "sh", launch.name)
+	assert.Equal(t, []string{scriptPath, "-Xmx2048M"}, launch.args, "a quoted path stays a single argument")
+	assert.Empty(t, launch.cmdLine)
+
+	// cmd.exe splits an unquoted path at ( ) & ^, so the command line is built with its own quoting
+	windowsPath := `C:\Program Files (x86)\R&D ^1\instance\run.bat`
+	launch = buildLaunchCommand("windows", windowsPath, 4096)
+	assert.Equal(t, "cmd", launch.name)
+	assert.Equal(t, []string{"/S", "/C", `"` + windowsPath + `" -Xmx4096M`}, launch.args)
+	assert.Equal(t, `cmd /S /C ""C:\Program Files (x86)\R&D ^1\instance\run.bat" -Xmx4096M"`, launch.cmdLine)
+
+	for _, goos := range []string{"linux", "windows"} {
+		launch = buildLaunchCommand(goos, filepath.Join("srv", "custom.JAR"), 1024)
+		assert.Equal(t, "java", launch.name)
+		assert.Equal(t, []string{"-Xmx1024M", "-jar", filepath.Join("srv", "custom.JAR"), "nogui"}, launch.args)
+		assert.Empty(t, launch.cmdLine, "java arguments follow the standard quoting rules")
+	}
+}
@@ -0,0 +1,28 @@
+package adapters
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// prepareProcess passes cmdLine to the process verbatim when it is set
+// No process group is needed; taskkill finds the children of cmd.exe by parent PID
+func prepareProcess(cmd *exec.Cmd, cmdLine string) {
+	if cmdLine != "" {
+		cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: cmdLine}
+	}
+}
+
+// killProcessTree kills cmd and every process it started, such as the java process of a start script
+// Falls back to killing cmd alone if taskkill fails
+func killProcessTree(cmd *exec.Cmd) error {
+	pid := strconv.Itoa(cmd.Process.Pid)
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", pid).Run(); err != nil {
+		if killErr := cmd.Process.Kill(); killErr != nil {
+			return fmt.Errorf("failed to kill process tree %s: %w", pid, err)
+		}
+	}
+	return nil
+}
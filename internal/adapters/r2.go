@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
 	"sync/atomic"
 	"time"
@@ -19,8 +20,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
+// Compile-time check to ensure R2Repository supports conditional writes
+var _ ports.VersionedStorage = (*R2Repository)(nil)
+
+// Compile-time check to ensure R2Repository reports sizes while listing
+var _ ports.SizedLister = (*R2Repository)(nil)
+
 type S3Client interface {
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
@@ -33,26 +42,48 @@ type R2Repository struct {
 	client S3Client
 	bucket string
 	events chan<- ports.Event
+	retry  RetryPolicy
 }
 
-func setupS3Client(accountID string, accessKeyID string, secretAccessKey string) (S3Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
-		config.WithRegion("auto"),
-	)
+// setupS3Client creates an S3 client for the configured backend
+func setupS3Client(backend S3Config) (*s3.Client, error) {
+	if err := backend.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid remote backend configuration: %w", err)
+	}
+
+	options := []func(*config.LoadOptions) error{
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(backend.AccessKeyID, backend.SecretAccessKey, "")),
+		config.WithRegion(backend.region()),
+	}
+	httpClient, err := backend.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	if httpClient != nil {
+		options = append(options, config.WithHTTPClient(httpClient))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(fmt.Sprintf(appconfig.R2EndpointFormat, accountID))
+		o.BaseEndpoint = aws.String(backend.Endpoint)
+		o.UsePathStyle = backend.UsePathStyle
 	})
 
 	return client, nil
 }
 
+// NewR2Repository creates a repository for a Cloudflare R2 account
 func NewR2Repository(bucket string, accountID string, accessKeyID string, secretAccessKey string, events chan<- ports.Event) (*R2Repository, error) {
-	client, err := setupS3Client(accountID, accessKeyID, secretAccessKey)
+	return NewS3Repository(bucket, R2Config(accountID, accessKeyID, secretAccessKey), events)
+}
+
+// NewS3Repository creates a repository for any S3-compatible backend
+func NewS3Repository(bucket string, backend S3Config, events chan<- ports.Event) (*R2Repository, error) {
+	client, err := setupS3Client(backend)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +92,7 @@ func NewR2Repository(bucket string, accountID string, accessKeyID string, secret
 		client: client,
 		bucket: bucket,
 		events: events,
+		retry:  DefaultRetryPolicy(),
 	}, nil
 }
 
@@ -69,12 +101,18 @@ func NewR2RepositoryWithClient(client S3Client, bucket string, events chan<- por
 		client: client,
 		bucket: bucket,
 		events: events,
+		retry:  DefaultRetryPolicy(),
 	}
 }
 
 // NewR2RepositoryWithUploader creates both R2Repository and S3Uploader sharing the same client
 func NewR2RepositoryWithUploader(bucket string, accountID string, accessKeyID string, secretAccessKey string, events chan<- ports.Event) (*R2Repository, *S3Uploader, error) {
-	client, err := setupS3Client(accountID, accessKeyID, secretAccessKey)
+	return NewS3RepositoryWithUploader(bucket, R2Config(accountID, accessKeyID, secretAccessKey), events)
+}
+
+// NewS3RepositoryWithUploader creates both R2Repository and S3Uploader for any S3-compatible backend
+func NewS3RepositoryWithUploader(bucket string, backend S3Config, events chan<- ports.Event) (*R2Repository, *S3Uploader, error) {
+	client, err := setupS3Client(backend)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -83,6 +121,7 @@ func NewR2RepositoryWithUploader(bucket string, accountID string, accessKeyID st
 		client: client,
 		bucket: bucket,
 		events: events,
+		retry:  DefaultRetryPolicy(),
 	}
 
 	uploader, err := NewS3Uploader(client, bucket, events)
@@ -98,26 +137,56 @@ func (r *R2Repository) send(evt ports.Event) {
 	ports.SendEvent(r.events, evt)
 }
 
+// SetRetryPolicy replaces the retry policy used by Get, Put, List, Copy and Download
+func (r *R2Repository) SetRetryPolicy(policy RetryPolicy) {
+	r.retry = policy
+}
+
 func (r *R2Repository) Get(ctx context.Context, key string) ([]byte, error) {
+	data, _, err := r.getObject(ctx, key)
+	return data, err
+}
+
+// getObject reads a whole object and its ETag, retrying transient failures
+// A failed body read is retried like a failed request, since the object is small
+func (r *R2Repository) getObject(ctx context.Context, key string) ([]byte, string, error) {
 	key = filepath.ToSlash(key)
-	result, err := r.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(key),
+
+	var data []byte
+	var etag string
+	err := r.retry.Do(ctx, "download", r.events, func() error {
+		result, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(r.bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return wrapGetError(key, err)
+		}
+		defer result.Body.Close()
+
+		data, err = io.ReadAll(result.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read object %s: %w", key, err)
+		}
+		etag = aws.ToString(result.ETag)
+		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+		return nil, "", err
 	}
-	defer result.Body.Close()
 
-	return io.ReadAll(result.Body)
+	return data, etag, nil
 }
 
 func (r *R2Repository) Put(ctx context.Context, key string, data []byte) error {
 	key = filepath.ToSlash(key)
-	_, err := r.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(r.bucket),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(data),
+	err := r.retry.Do(ctx, "upload", r.events, func() error {
+		_, err := r.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(r.bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(data),
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to put object %s: %w", key, err)
@@ -126,6 +195,65 @@ func (r *R2Repository) Put(ctx context.Context, key string, data []byte) error {
 	return nil
 }
 
+// GetWithVersion retrieves an object together with its ETag
+func (r *R2Repository) GetWithVersion(ctx context.Context, key string) ([]byte, string, error) {
+	return r.getObject(ctx, key)
+}
+
+// PutIfVersion writes an object only if its ETag still equals version (If-Match)
+// An empty version writes only if the object does not exist (If-None-Match: *)
+// It is not retried: a write that landed before a dropped response would be reported as a conflict
+func (r *R2Repository) PutIfVersion(ctx context.Context, key string, data []byte, version string) (string, error) {
+	key = filepath.ToSlash(key)
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}
+	if version == "" {
+		input.IfNoneMatch = aws.String("*")
+	} else {
+		input.IfMatch = aws.String(version)
+	}
+
+	result, err := r.client.PutObject(ctx, input)
+	if err != nil {
+		if isPreconditionFailed(err) {
+			return "", fmt.Errorf("%w: object %s was modified concurrently", ports.ErrVersionConflict, key)
+		}
+		return "", fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+
+	return aws.ToString(result.ETag), nil
+}
+
+// wrapGetError wraps a GetObject error, mapping a missing key to ports.ErrNotFound
+func wrapGetError(key string, err error) error {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return fmt.Errorf("failed to get object %s: %w: %w", key, ports.ErrNotFound, err)
+	}
+	return fmt.Errorf("failed to get object %s: %w", key, err)
+}
+
+// isPreconditionFailed reports whether err is a rejected conditional write
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return true
+		}
+	}
+
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() == http.StatusPreconditionFailed
+	}
+
+	return false
+}
+
 func (r *R2Repository) Delete(ctx context.Context, key string) error {
 	key = filepath.ToSlash(key)
 	_, err := r.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -139,24 +267,61 @@ func (r *R2Repository) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// List returns all keys with the given prefix, following continuation tokens past the 1000-key page limit
 func (r *R2Repository) List(ctx context.Context, prefix string) ([]string, error) {
-	prefix = filepath.ToSlash(prefix)
-	result, err := r.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(r.bucket),
-		Prefix: aws.String(prefix),
+	keys := []string{}
+	err := r.listObjects(ctx, prefix, func(key string, _ int64) {
+		keys = append(keys, key)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		return nil, err
 	}
+	return keys, nil
+}
 
-	keys := make([]string, 0, len(result.Contents))
-	for _, obj := range result.Contents {
-		if obj.Key != nil {
-			keys = append(keys, *obj.Key)
-		}
+// ListSizes returns the size of every key with the given prefix
+func (r *R2Repository) ListSizes(ctx context.Context, prefix string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := r.listObjects(ctx, prefix, func(key string, size int64) {
+		sizes[key] = size
+	})
+	if err != nil {
+		return nil, err
 	}
+	return sizes, nil
+}
 
-	return keys, nil
+// listObjects calls visit for every object with the given prefix, page by page
+func (r *R2Repository) listObjects(ctx context.Context, prefix string, visit func(key string, size int64)) error {
+	prefix = filepath.ToSlash(prefix)
+
+	var token *string
+	for {
+		var result *s3.ListObjectsV2Output
+		err := r.retry.Do(ctx, "list", r.events, func() error {
+			var err error
+			result, err = r.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+				Bucket:            aws.String(r.bucket),
+				Prefix:            aws.String(prefix),
+				ContinuationToken: token,
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		}
+
+		for _, obj := range result.Contents {
+			if obj.Key != nil {
+				visit(*obj.Key, aws.ToInt64(obj.Size))
+			}
+		}
+
+		if result.IsTruncated == nil || !*result.IsTruncated || result.NextContinuationToken == nil {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
 }
 
 // Copy copies data from source key to destination key
@@ -187,10 +352,13 @@ func (r *R2Repository) Copy(ctx context.Context, sourceKey string, destKey strin
 	sourceURI := fmt.Sprintf("%s/%s", r.bucket, sourceKey)
 
 	// Copy object within same bucket
-	_, err := r.client.CopyObject(ctx, &s3.CopyObjectInput{
-		Bucket:     aws.String(r.bucket),
-		Key:        aws.String(destKey),
-		CopySource: aws.String(sourceURI),
+	err := r.retry.Do(ctx, "copy", r.events, func() error {
+		_, err := r.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(r.bucket),
+			Key:        aws.String(destKey),
+			CopySource: aws.String(sourceURI),
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to copy object from %s to %s: %w", sourceKey, destKey, err)
@@ -266,6 +434,8 @@ func (pr *progressReadCloser) Close() error {
 
 // Download streams content from R2 as an io.ReadCloser
 // Implements streamer.S3StreamDownloader interface
+// Opening the object is retried under the repository's retry policy, and a read that fails
+// mid-stream resumes with a ranged GET at the current offset, so callers see one unbroken stream
 func (r *R2Repository) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
 	if ctx == nil {
 		return nil, errors.New("context cannot be nil")
@@ -280,20 +450,130 @@ func (r *R2Repository) Download(ctx context.Context, bucket, key string) (io.Rea
 
 	key = filepath.ToSlash(key)
 
-	result, err := r.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+	stream := &resumableReader{ctx: ctx, repo: r, bucket: bucket, key: key}
+	var contentLength int64
+	err := r.retry.Do(ctx, "download", r.events, func() error {
+		result, err := stream.open()
+		if err != nil {
+			return err
+		}
+		stream.body = result.Body
+		stream.etag = aws.ToString(result.ETag)
+		if result.ContentLength != nil {
+			contentLength = *result.ContentLength
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to download %s: %w", key, err)
 	}
 
-	var contentLength int64
-	if result.ContentLength != nil {
-		contentLength = *result.ContentLength
+	// Progress wraps the resumed stream so retried bytes are never counted twice
+	return newProgressReadCloser(stream, key, contentLength, r.events), nil
+}
+
+// resumableReader reads an object and reopens it at the current offset after transient read errors
+type resumableReader struct {
+	ctx    context.Context
+	repo   *R2Repository
+	bucket string
+	key    string
+	etag   string // pins resumed reads to the object first opened
+	body   io.ReadCloser
+	offset int64
+	failed int // consecutive failures without progress
+}
+
+// open requests the object from the current offset
+// Resumed requests are conditional on the original ETag so a replaced object is never spliced in
+func (s *resumableReader) open() (*s3.GetObjectOutput, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	}
+	if s.offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", s.offset))
+		if s.etag != "" {
+			input.IfMatch = aws.String(s.etag)
+		}
+	}
+
+	result, err := s.repo.client.GetObject(s.ctx, input)
+	if err != nil {
+		return nil, wrapGetError(s.key, err)
 	}
+	return result, nil
+}
+
+func (s *resumableReader) Read(p []byte) (int, error) {
+	for {
+		n, err := s.body.Read(p)
+		s.offset += int64(n)
+		if n > 0 {
+			s.failed = 0
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		s.failed++
+		policy := s.repo.retry
+		if s.failed >= policy.MaxAttempts || s.ctx.Err() != nil || !policy.retryable(err) {
+			return n, err
+		}
+		if resumeErr := s.resume(err); resumeErr != nil {
+			return n, resumeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume waits out the backoff and reopens the body at the current offset
+func (s *resumableReader) resume(cause error) error {
+	s.body.Close()
+	s.body = io.NopCloser(errReader{cause})
+
+	policy := s.repo.retry
+	s.repo.send(ports.UpdateEvent{
+		Operation: "download",
+		Message:   "Resuming interrupted download",
+		Data:      map[string]any{"key": s.key, "offset_mb": fmt.Sprintf("%.2f", float64(s.offset)/(1024*1024)), "attempt": s.failed + 1, "error": cause.Error()},
+	})
+	if err := policy.wait(s.ctx, s.failed); err != nil {
+		return err
+	}
+
+	result, err := s.open()
+	if err != nil {
+		// Counted as another failure on the next Read through errReader
+		s.body = io.NopCloser(errReader{err})
+		return nil
+	}
+	if result.ContentRange == nil {
+		// The range was ignored and the whole object sent again; skip what was already read
+		if _, err := io.CopyN(io.Discard, result.Body, s.offset); err != nil {
+			result.Body.Close()
+			s.body = io.NopCloser(errReader{err})
+			return nil
+		}
+	}
+	s.body = result.Body
+	return nil
+}
+
+func (s *resumableReader) Close() error {
+	return s.body.Close()
+}
+
+// errReader fails every read with err
+type errReader struct {
+	err error
+}
 
-	return newProgressReadCloser(result.Body, key, contentLength, r.events), nil
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
 }
 
 var _ streamer.S3StreamDownloader = (*R2Repository)(nil)
@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"syscall"
 	"testing"
+	"time"
 
 	"ritual/internal/core/ports"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -105,6 +110,48 @@ func TestR2Repository_SuccessCases(t *testing.T) {
 		mockClient.AssertExpectations(t)
 	})
 
+	t.Run("list follows continuation tokens", func(t *testing.T) {
+		pagedClient := new(MockS3Client)
+		pagedRepo := NewR2RepositoryWithClient(pagedClient, "test-bucket", nil)
+		first, second := "chunks/a", "chunks/b"
+
+		pagedClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(in *s3.ListObjectsV2Input) bool {
+			return in.ContinuationToken == nil
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents:              []types.Object{{Key: &first}},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("page-2"),
+		}, nil).Once()
+		pagedClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(in *s3.ListObjectsV2Input) bool {
+			return in.ContinuationToken != nil && *in.ContinuationToken == "page-2"
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents:    []types.Object{{Key: &second}},
+			IsTruncated: aws.Bool(false),
+		}, nil).Once()
+
+		result, err := pagedRepo.List(context.Background(), "chunks/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{first, second}, result)
+		pagedClient.AssertExpectations(t)
+	})
+
+	t.Run("list sizes", func(t *testing.T) {
+		sizedClient := new(MockS3Client)
+		sizedRepo := NewR2RepositoryWithClient(sizedClient, "test-bucket", nil)
+		first, second := "worlds/1.tar", "worlds/2.tar"
+
+		sizedClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents: []types.Object{{Key: &first, Size: aws.Int64(10)}, {Key: &second}},
+		}, nil)
+
+		result, err := sizedRepo.ListSizes(context.Background(), "worlds")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int64{first: 10, second: 0}, result)
+		sizedClient.AssertExpectations(t)
+	})
+
 	t.Run("copy success", func(t *testing.T) {
 		sourceKey := "source-key"
 		destKey := "dest-key"
@@ -275,6 +322,242 @@ func TestR2Repository_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestR2Repository_VersionedWrites(t *testing.T) {
+	t.Run("get returns etag", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("data"))),
+			ETag: aws.String(`"etag-1"`),
+		}, nil)
+
+		data, version, err := repo.GetWithVersion(context.Background(), "manifest.json")
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(data))
+		assert.Equal(t, `"etag-1"`, version)
+	})
+
+	t.Run("put with version sends If-Match", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+
+		mockClient.On("PutObject", mock.Anything, mock.MatchedBy(func(in *s3.PutObjectInput) bool {
+			return aws.ToString(in.IfMatch) == `"etag-1"` && in.IfNoneMatch == nil
+		}), mock.Anything).Return(&s3.PutObjectOutput{ETag: aws.String(`"etag-2"`)}, nil)
+
+		version, err := repo.PutIfVersion(context.Background(), "manifest.json", []byte("data"), `"etag-1"`)
+		assert.NoError(t, err)
+		assert.Equal(t, `"etag-2"`, version)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("put without version sends If-None-Match", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+
+		mockClient.On("PutObject", mock.Anything, mock.MatchedBy(func(in *s3.PutObjectInput) bool {
+			return aws.ToString(in.IfNoneMatch) == "*" && in.IfMatch == nil
+		}), mock.Anything).Return(&s3.PutObjectOutput{ETag: aws.String(`"etag-1"`)}, nil)
+
+		_, err := repo.PutIfVersion(context.Background(), "manifest.json", []byte("data"), "")
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("precondition failure is a version conflict", func(t *testing.T) {
+		for _, code := range []string{"PreconditionFailed", "ConditionalRequestConflict"} {
+			mockClient := new(MockS3Client)
+			repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+
+			mockClient.On("PutObject", mock.Anything, mock.Anything, mock.Anything).Return(
+				(*s3.PutObjectOutput)(nil), &smithy.GenericAPIError{Code: code, Message: "At least one of the pre-conditions you specified did not hold"})
+
+			_, err := repo.PutIfVersion(context.Background(), "manifest.json", []byte("data"), `"etag-1"`)
+			assert.ErrorIs(t, err, ports.ErrVersionConflict, code)
+		}
+	})
+
+	t.Run("other errors are not conflicts", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+
+		mockClient.On("PutObject", mock.Anything, mock.Anything, mock.Anything).Return(
+			(*s3.PutObjectOutput)(nil), errors.New("network error"))
+
+		_, err := repo.PutIfVersion(context.Background(), "manifest.json", []byte("data"), `"etag-1"`)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ports.ErrVersionConflict)
+	})
+}
+
 func TestR2Repository_InterfaceCompliance(t *testing.T) {
 	var _ ports.StorageRepository = (*R2Repository)(nil)
+	var _ ports.VersionedStorage = (*R2Repository)(nil)
+}
+
+// failingBody returns data and then fails with err instead of io.EOF
+type failingBody struct {
+	data []byte
+	err  error
+}
+
+func (f *failingBody) Read(p []byte) (int, error) {
+	if len(f.data) == 0 {
+		return 0, f.err
+	}
+	n := copy(p, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+func (f *failingBody) Close() error { return nil }
+
+// fastRetryPolicy retries without noticeable delays
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+}
+
+// withRange matches GetObject requests by their Range header (empty = no range)
+func withRange(rangeHeader string) any {
+	return mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+		return aws.ToString(input.Range) == rangeHeader
+	})
+}
+
+func TestR2Repository_Retries(t *testing.T) {
+	slowDown := &smithy.GenericAPIError{Code: "SlowDown", Message: "reduce your request rate"}
+
+	t.Run("transient get error is retried", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return((*s3.GetObjectOutput)(nil), slowDown).Once()
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("data"))),
+		}, nil).Once()
+
+		result, err := repo.Get(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data"), result)
+		mockClient.AssertNumberOfCalls(t, "GetObject", 2)
+	})
+
+	t.Run("put list and copy give up after max attempts", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("PutObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.PutObjectOutput{}, slowDown)
+		mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{}, slowDown)
+		mockClient.On("CopyObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.CopyObjectOutput{}, slowDown)
+
+		assert.Error(t, repo.Put(context.Background(), "key", []byte("data")))
+		_, err := repo.List(context.Background(), "prefix")
+		assert.Error(t, err)
+		assert.Error(t, repo.Copy(context.Background(), "src", "dst"))
+
+		for _, method := range []string{"PutObject", "ListObjectsV2", "CopyObject"} {
+			mockClient.AssertNumberOfCalls(t, method, 3)
+		}
+	})
+
+	t.Run("missing key is not retried", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return((*s3.GetObjectOutput)(nil), &types.NoSuchKey{})
+
+		_, err := repo.Get(context.Background(), "key")
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+		mockClient.AssertNumberOfCalls(t, "GetObject", 1)
+	})
+}
+
+func TestR2Repository_DownloadResumes(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	half := int64(len(data) / 2)
+
+	t.Run("dropped connection resumes at the current offset", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, withRange(""), mock.Anything).Return(&s3.GetObjectOutput{
+			Body:          &failingBody{data: data[:half], err: io.ErrUnexpectedEOF},
+			ContentLength: aws.Int64(int64(len(data))),
+			ETag:          aws.String(`"v1"`),
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return aws.ToString(input.Range) == fmt.Sprintf("bytes=%d-", half) && aws.ToString(input.IfMatch) == `"v1"`
+		}), mock.Anything).Return(&s3.GetObjectOutput{
+			Body:         io.NopCloser(bytes.NewReader(data[half:])),
+			ContentRange: aws.String(fmt.Sprintf("bytes %d-%d/%d", half, len(data)-1, len(data))),
+		}, nil).Once()
+
+		body, err := repo.Download(context.Background(), "", "worlds/1.tar")
+		assert.NoError(t, err)
+		result, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+
+		// Progress counts every byte once across the retry
+		assert.Equal(t, int64(len(data)), body.(*progressReadCloser).bytesRead)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("ignored range skips bytes already read", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, withRange(""), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: &failingBody{data: data[:half], err: syscall.ECONNRESET},
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, withRange(fmt.Sprintf("bytes=%d-", half)), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader(data)),
+		}, nil).Once()
+
+		body, err := repo.Download(context.Background(), "", "worlds/1.tar")
+		assert.NoError(t, err)
+		result, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+	})
+
+	t.Run("repeated failures without progress give up", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+			Body: &failingBody{err: io.ErrUnexpectedEOF},
+		}, nil)
+
+		body, err := repo.Download(context.Background(), "", "worlds/1.tar")
+		assert.NoError(t, err)
+		_, err = io.ReadAll(body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		mockClient.AssertNumberOfCalls(t, "GetObject", 3)
+	})
+
+	t.Run("replaced object is not spliced", func(t *testing.T) {
+		mockClient := new(MockS3Client)
+		repo := NewR2RepositoryWithClient(mockClient, "test-bucket", nil)
+		repo.SetRetryPolicy(fastRetryPolicy())
+
+		mockClient.On("GetObject", mock.Anything, withRange(""), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: &failingBody{data: data[:half], err: io.ErrUnexpectedEOF},
+			ETag: aws.String(`"v1"`),
+		}, nil).Once()
+		mockClient.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return((*s3.GetObjectOutput)(nil), &smithy.GenericAPIError{Code: "PreconditionFailed"}).Once()
+
+		body, err := repo.Download(context.Background(), "", "worlds/1.tar")
+		assert.NoError(t, err)
+		_, err = io.ReadAll(body)
+		assert.Error(t, err)
+		mockClient.AssertNumberOfCalls(t, "GetObject", 2)
+	})
 }
@@ -0,0 +1,312 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/ports"
+)
+
+// RconClient error constants
+var (
+	ErrRconNil            = errors.New("RCON client cannot be nil")
+	ErrRconNotConnected   = errors.New("RCON client is not connected")
+	ErrRconConnected      = errors.New("RCON client is already connected")
+	ErrRconAuthFailed     = errors.New("RCON authentication failed")
+	ErrRconCommandEmpty   = errors.New("RCON command cannot be empty")
+	ErrRconCommandTooLong = errors.New("RCON command is too long")
+	ErrRconMalformed      = errors.New("malformed RCON packet")
+)
+
+// RCON packet types
+const (
+	rconTypeResponse = 0 // SERVERDATA_RESPONSE_VALUE
+	rconTypeCommand  = 2 // SERVERDATA_EXECCOMMAND; also the type of the login response
+	rconTypeLogin    = 3 // SERVERDATA_AUTH
+)
+
+// RCON packet limits
+const (
+	rconPacketOverhead   = 10   // Request ID, type and the two terminating null bytes
+	rconMaxCommandLength = 1446 // Longest body the server reads from a client
+	rconMaxResponseBody  = 4096 // Longer responses are split across several packets
+	rconAuthFailedID     = -1   // Request ID of the login response for a wrong password
+)
+
+// Compile-time check to ensure RconClient implements ports.RemoteConsole
+var _ ports.RemoteConsole = (*RconClient)(nil)
+
+// rconPacket is one message of the RCON protocol
+// On the wire: little-endian int32 length, request ID and type, then the body and two null bytes
+type rconPacket struct {
+	id         int32
+	packetType int32
+	body       string
+}
+
+// RconClient talks to a Minecraft server over the RCON protocol
+// Commands are serialized; a response split across packets is reassembled
+type RconClient struct {
+	timeout time.Duration // Applies to each command unless ctx has an earlier deadline
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int32
+}
+
+// NewRconClient creates a new RCON client
+// timeout bounds sending a command and reading its whole response
+func NewRconClient(timeout time.Duration) (*RconClient, error) {
+	if timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	return &RconClient{timeout: timeout}, nil
+}
+
+// Connect opens a connection to address and authenticates with password
+// The connection is closed again if authentication fails
+func (c *RconClient) Connect(ctx context.Context, address, password string) error {
+	if c == nil {
+		return ErrRconNil
+	}
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if address == "" {
+		return errors.New("address cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return ErrRconConnected
+	}
+
+	dialer := net.Dialer{Timeout: time.Duration(config.RconDialTimeoutSec) * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RCON at %s: %w", address, err)
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if err := c.login(ctx, password); err != nil {
+		c.closeLocked()
+		return err
+	}
+	return nil
+}
+
+// login sends the password and waits for the login response
+func (c *RconClient) login(ctx context.Context, password string) error {
+	stop := c.watch(ctx)
+	defer stop()
+
+	id := c.requestID()
+	if err := c.write(rconPacket{id: id, packetType: rconTypeLogin, body: password}); err != nil {
+		return fmt.Errorf("failed to send RCON login: %w", err)
+	}
+
+	for {
+		packet, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read RCON login response: %w", contextError(ctx, err))
+		}
+		// Some servers send an empty response value ahead of the login response
+		if packet.packetType == rconTypeResponse {
+			continue
+		}
+		if packet.id == rconAuthFailedID {
+			return ErrRconAuthFailed
+		}
+		if packet.id != id {
+			return fmt.Errorf("%w: login response for request %d, expected %d", ErrRconMalformed, packet.id, id)
+		}
+		return nil
+	}
+}
+
+// Command runs a console command and returns its complete response
+// An empty marker request follows the command; the server answers requests in order,
+// so every response packet before the marker's answer belongs to the command
+func (c *RconClient) Command(ctx context.Context, command string) (string, error) {
+	if c == nil {
+		return "", ErrRconNil
+	}
+	if ctx == nil {
+		return "", errors.New("context cannot be nil")
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", ErrRconCommandEmpty
+	}
+	if len(command) > rconMaxCommandLength {
+		return "", fmt.Errorf("%w: %d bytes, at most %d", ErrRconCommandTooLong, len(command), rconMaxCommandLength)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return "", ErrRconNotConnected
+	}
+
+	stop := c.watch(ctx)
+	defer stop()
+
+	id := c.requestID()
+	marker := c.requestID()
+	if err := c.write(rconPacket{id: id, packetType: rconTypeCommand, body: command}); err != nil {
+		return "", c.fail(ctx, fmt.Errorf("failed to send RCON command: %w", err))
+	}
+	if err := c.write(rconPacket{id: marker, packetType: rconTypeResponse}); err != nil {
+		return "", c.fail(ctx, fmt.Errorf("failed to send RCON command: %w", err))
+	}
+
+	var response strings.Builder
+	for {
+		packet, err := c.read()
+		if err != nil {
+			return "", c.fail(ctx, fmt.Errorf("failed to read RCON response: %w", err))
+		}
+		switch packet.id {
+		case id:
+			response.WriteString(packet.body)
+		case marker:
+			return response.String(), nil
+		default:
+			return "", c.fail(ctx, fmt.Errorf("%w: response for request %d, expected %d", ErrRconMalformed, packet.id, id))
+		}
+	}
+}
+
+// Close closes the connection
+// Returns nil if the client is not connected
+func (c *RconClient) Close() error {
+	if c == nil {
+		return ErrRconNil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeLocked()
+}
+
+// closeLocked closes the connection while c.mu is held
+func (c *RconClient) closeLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.reader = nil
+	return err
+}
+
+// fail closes the connection after a broken exchange and returns err
+// Unread response packets would otherwise be taken as the answer to the next command
+func (c *RconClient) fail(ctx context.Context, err error) error {
+	c.closeLocked()
+	return contextError(ctx, err)
+}
+
+// contextError reports the cancellation of ctx instead of the deadline error it caused
+// The connection deadline can pass moments before ctx reports its own deadline
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// watch sets the exchange deadline and interrupts blocked I/O when ctx is cancelled
+// The returned function clears the deadline
+func (c *RconClient) watch(ctx context.Context) func() {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn := c.conn
+	conn.SetDeadline(deadline)
+
+	stopAfter := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	return func() {
+		stopAfter()
+		conn.SetDeadline(time.Time{})
+	}
+}
+
+// requestID returns the next request ID
+// IDs stay positive, since the server answers a failed login with -1
+func (c *RconClient) requestID() int32 {
+	if c.nextID == 1<<31-1 {
+		c.nextID = 0
+	}
+	c.nextID++
+	return c.nextID
+}
+
+// write sends one packet
+func (c *RconClient) write(packet rconPacket) error {
+	_, err := c.conn.Write(encodeRconPacket(packet))
+	return err
+}
+
+// read receives one packet
+func (c *RconClient) read() (rconPacket, error) {
+	return decodeRconPacket(c.reader)
+}
+
+// encodeRconPacket returns the wire form of packet
+func encodeRconPacket(packet rconPacket) []byte {
+	buf := make([]byte, 0, 4+rconPacketOverhead+len(packet.body))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(rconPacketOverhead+len(packet.body)))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(packet.id))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(packet.packetType))
+	buf = append(buf, packet.body...)
+	return append(buf, 0, 0)
+}
+
+// decodeRconPacket reads one packet from r
+func decodeRconPacket(r io.Reader) (rconPacket, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return rconPacket{}, err
+	}
+	length := int(int32(binary.LittleEndian.Uint32(header[:])))
+	if length < rconPacketOverhead || length > rconPacketOverhead+rconMaxResponseBody {
+		return rconPacket{}, fmt.Errorf("%w: length %d", ErrRconMalformed, length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return rconPacket{}, err
+	}
+
+	// The body ends at the first of the two terminating null bytes
+	body := payload[8 : length-2]
+	if end := bytes.IndexByte(body, 0); end >= 0 {
+		body = body[:end]
+	}
+	return rconPacket{
+		id:         int32(binary.LittleEndian.Uint32(payload[0:4])),
+		packetType: int32(binary.LittleEndian.Uint32(payload[4:8])),
+		body:       string(body),
+	}, nil
+}
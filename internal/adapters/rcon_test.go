@@ -0,0 +1,201 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRconServer answers RCON requests the way a Minecraft server does
+// Responses longer than one packet body are split; unknown request types are answered in order
+type fakeRconServer struct {
+	listener  net.Listener
+	password  string
+	responses map[string]string // Response per command; other commands echo "ran <command>"
+	commands  chan string       // Commands received, in order
+}
+
+func newFakeRconServer(t *testing.T, password string, responses map[string]string) *fakeRconServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeRconServer{listener: listener, password: password, responses: responses, commands: make(chan string, 16)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRconServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRconServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		packet, err := decodeRconPacket(reader)
+		if err != nil {
+			return
+		}
+		switch packet.packetType {
+		case rconTypeLogin:
+			id := packet.id
+			if packet.body != s.password {
+				id = rconAuthFailedID
+			}
+			conn.Write(encodeRconPacket(rconPacket{id: id, packetType: rconTypeCommand}))
+		case rconTypeCommand:
+			s.commands <- packet.body
+			response, ok := s.responses[packet.body]
+			if !ok {
+				response = "ran " + packet.body
+			}
+			for len(response) > rconMaxResponseBody {
+				conn.Write(encodeRconPacket(rconPacket{id: packet.id, packetType: rconTypeResponse, body: response[:rconMaxResponseBody]}))
+				response = response[rconMaxResponseBody:]
+			}
+			conn.Write(encodeRconPacket(rconPacket{id: packet.id, packetType: rconTypeResponse, body: response}))
+		default:
+			conn.Write(encodeRconPacket(rconPacket{id: packet.id, packetType: rconTypeResponse, body: fmt.Sprintf("Unknown request %x", packet.packetType)}))
+		}
+	}
+}
+
+func TestRconPacket_RoundTrip(t *testing.T) {
+	encoded := encodeRconPacket(rconPacket{id: 7, packetType: rconTypeCommand, body: "list"})
+	assert.Equal(t, []byte{14, 0, 0, 0, 7, 0, 0, 0, 2, 0, 0, 0, 'l', 'i', 's', 't', 0, 0}, encoded)
+
+	decoded, err := decodeRconPacket(bufio.NewReader(strings.NewReader(string(encoded))))
+	require.NoError(t, err)
+	assert.Equal(t, rconPacket{id: 7, packetType: rconTypeCommand, body: "list"}, decoded)
+
+	tooLong := encodeRconPacket(rconPacket{id: 1, body: strings.Repeat("x", rconMaxResponseBody+1)})
+	_, err = decodeRconPacket(bufio.NewReader(strings.NewReader(string(tooLong))))
+	assert.ErrorIs(t, err, ErrRconMalformed)
+}
+
+func TestNewRconClient(t *testing.T) {
+	_, err := NewRconClient(0)
+	assert.Error(t, err)
+
+	client, err := NewRconClient(time.Second)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+
+	var nilClient *RconClient
+	assert.ErrorIs(t, nilClient.Connect(context.Background(), "127.0.0.1:25575", "secret"), ErrRconNil)
+	_, err = nilClient.Command(context.Background(), "list")
+	assert.ErrorIs(t, err, ErrRconNil)
+}
+
+func TestRconClient_Command(t *testing.T) {
+	ctx := context.Background()
+	long := strings.Repeat("0123456789", 1000)
+	server := newFakeRconServer(t, "secret", map[string]string{"list": "There are 1 of a max of 20 players online: owl", "long": long})
+
+	client, err := NewRconClient(5 * time.Second)
+	require.NoError(t, err)
+	require.NoError(t, client.Connect(ctx, server.address(), "secret"))
+	defer client.Close()
+	assert.ErrorIs(t, client.Connect(ctx, server.address(), "secret"), ErrRconConnected)
+
+	response, err := client.Command(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, "There are 1 of a max of 20 players online: owl", response)
+
+	response, err = client.Command(ctx, "long")
+	require.NoError(t, err)
+	assert.Equal(t, long, response, "fragmented responses are reassembled")
+
+	response, err = client.Command(ctx, "  save-all  ")
+	require.NoError(t, err)
+	assert.Equal(t, "ran save-all", response, "the next command is not confused with the split response")
+	assert.Equal(t, []string{"list", "long", "save-all"}, []string{<-server.commands, <-server.commands, <-server.commands})
+
+	_, err = client.Command(ctx, " ")
+	assert.ErrorIs(t, err, ErrRconCommandEmpty)
+	_, err = client.Command(ctx, strings.Repeat("x", rconMaxCommandLength+1))
+	assert.ErrorIs(t, err, ErrRconCommandTooLong)
+
+	require.NoError(t, client.Close())
+	_, err = client.Command(ctx, "list")
+	assert.ErrorIs(t, err, ErrRconNotConnected)
+}
+
+func TestRconClient_Connect(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRconServer(t, "secret", nil)
+
+	t.Run("wrong password", func(t *testing.T) {
+		client, err := NewRconClient(5 * time.Second)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, client.Connect(ctx, server.address(), "wrong"), ErrRconAuthFailed)
+		_, err = client.Command(ctx, "list")
+		assert.ErrorIs(t, err, ErrRconNotConnected, "the connection is closed after a failed login")
+	})
+
+	t.Run("nothing listening", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		client, err := NewRconClient(5 * time.Second)
+		require.NoError(t, err)
+		assert.Error(t, client.Connect(ctx, address, "secret"))
+	})
+}
+
+func TestRconClient_CommandCancelled(t *testing.T) {
+	// A server that accepts the login but never answers commands
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		login, err := decodeRconPacket(reader)
+		if err != nil {
+			return
+		}
+		conn.Write(encodeRconPacket(rconPacket{id: login.id, packetType: rconTypeCommand}))
+		for {
+			if _, err := decodeRconPacket(reader); err != nil {
+				return
+			}
+		}
+	}()
+
+	client, err := NewRconClient(time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, client.Connect(context.Background(), listener.Addr().String(), "secret"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Command(ctx, "list")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.Command(context.Background(), "list")
+	assert.ErrorIs(t, err, ErrRconNotConnected, "a broken exchange closes the connection")
+}
@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	appconfig "ritual/internal/config"
+	"ritual/internal/core/ports"
+
+	"github.com/aws/smithy-go"
+)
+
+// RetryPolicy controls how storage operations are retried after transient failures
+type RetryPolicy struct {
+	MaxAttempts int                  // Attempts including the first; 1 or less disables retries
+	BaseDelay   time.Duration        // Delay before the first retry, doubled for each further attempt
+	MaxDelay    time.Duration        // Upper bound on a single delay. 0 = unbounded
+	Retryable   func(err error) bool // Optional: errors worth retrying. nil = IsRetryable
+}
+
+// DefaultRetryPolicy returns the policy configured in config
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: appconfig.R2RetryAttempts,
+		BaseDelay:   appconfig.R2RetryBaseDelayMs * time.Millisecond,
+		MaxDelay:    appconfig.R2RetryMaxDelayMs * time.Millisecond,
+	}
+}
+
+// retryable reports whether err should be retried under this policy
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// delay returns the backoff before retry number attempt (1 = first retry)
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// wait sleeps for the backoff of attempt, returning early with the context error on cancellation
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.delay(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do runs fn until it succeeds, fails with a non-retryable error or runs out of attempts
+// Each retry is reported on events; the last error is returned
+func (p RetryPolicy) Do(ctx context.Context, operation string, events chan<- ports.Event, fn func() error) error {
+	if ctx == nil {
+		return fn()
+	}
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(err) {
+			return err
+		}
+
+		ports.SendEvent(events, ports.UpdateEvent{
+			Operation: operation,
+			Message:   "Retrying after transient error",
+			Data:      map[string]any{"attempt": attempt + 1, "max_attempts": p.MaxAttempts, "delay": p.delay(attempt).String(), "error": err.Error()},
+		})
+		if waitErr := p.wait(ctx, attempt); waitErr != nil {
+			return err
+		}
+	}
+}
+
+// IsRetryable reports whether err is a transient storage or network failure
+// Throttling, server errors, timeouts and dropped connections are retryable; missing keys,
+// rejected conditional writes, other client errors and cancellation are not
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ports.ErrNotFound) || errors.Is(err, ports.ErrVersionConflict) || isPreconditionFailed(err) {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout", "RequestTimeTooSkewed":
+			return true
+		}
+	}
+
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		status := respErr.HTTPStatusCode()
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
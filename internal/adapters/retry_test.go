@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"ritual/internal/core/ports"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+	assert.Equal(t, 5*time.Second, policy.delay(60))
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	transient := &smithy.GenericAPIError{Code: "InternalError"}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		events := make(chan ports.Event, 10)
+		calls := 0
+		err := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}.Do(context.Background(), "list", events, func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Len(t, events, 2, "each retry is reported")
+	})
+
+	t.Run("custom retryable", func(t *testing.T) {
+		calls := 0
+		policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Retryable: func(error) bool { return false }}
+		err := policy.Do(context.Background(), "list", nil, func() error {
+			calls++
+			return transient
+		})
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("cancellation stops the backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}.Do(ctx, "list", nil, func() error {
+			calls++
+			cancel()
+			return transient
+		})
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+// statusError is an error carrying an HTTP status code
+type statusError int
+
+func (s statusError) Error() string       { return fmt.Sprintf("status %d", int(s)) }
+func (s statusError) HTTPStatusCode() int { return int(s) }
+
+func TestIsRetryable(t *testing.T) {
+	retryable := []error{
+		&smithy.GenericAPIError{Code: "SlowDown"},
+		statusError(503),
+		statusError(429),
+		fmt.Errorf("read body: %w", io.ErrUnexpectedEOF),
+		syscall.ECONNRESET,
+	}
+	for _, err := range retryable {
+		assert.True(t, IsRetryable(err), "%v should be retryable", err)
+	}
+
+	permanent := []error{
+		nil,
+		errors.New("s3 error"),
+		context.Canceled,
+		statusError(403),
+		statusError(412),
+		&smithy.GenericAPIError{Code: "PreconditionFailed"},
+		wrapGetError("key", &types.NoSuchKey{}),
+		fmt.Errorf("%w: conflict", ports.ErrVersionConflict),
+	}
+	for _, err := range permanent {
+		assert.False(t, IsRetryable(err), "%v should not be retryable", err)
+	}
+}
@@ -0,0 +1,95 @@
+package adapters
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	appconfig "ritual/internal/config"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// S3Config error constants
+var (
+	ErrS3EndpointEmpty     = errors.New("endpoint cannot be empty")
+	ErrS3EndpointInvalid   = errors.New("endpoint must be an http or https URL with a host")
+	ErrS3CredentialsEmpty  = errors.New("access key ID and secret access key cannot be empty")
+	ErrS3CACertificateFile = errors.New("CA certificate file holds no PEM certificates")
+)
+
+// S3Config describes an S3-compatible remote backend such as R2, MinIO, Garage, Backblaze B2 or AWS S3
+type S3Config struct {
+	Endpoint           string // Base URL, e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000
+	Region             string // Signing region. Empty = config.S3DefaultRegion
+	AccessKeyID        string
+	SecretAccessKey    string
+	UsePathStyle       bool   // Address buckets as endpoint/bucket instead of bucket.endpoint (MinIO, Garage)
+	InsecureSkipVerify bool   // Accept any TLS certificate; for self-signed test setups only
+	CACertFile         string // Optional: PEM bundle trusted in addition to the system roots
+}
+
+// R2Config returns the backend configuration for a Cloudflare R2 account
+func R2Config(accountID string, accessKeyID string, secretAccessKey string) S3Config {
+	return S3Config{
+		Endpoint:        fmt.Sprintf(appconfig.R2EndpointFormat, accountID),
+		Region:          appconfig.R2Region,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	}
+}
+
+// Validate checks that the configuration names a reachable endpoint and carries credentials
+func (c S3Config) Validate() error {
+	if c.Endpoint == "" {
+		return ErrS3EndpointEmpty
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("%w: %q", ErrS3EndpointInvalid, c.Endpoint)
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return ErrS3CredentialsEmpty
+	}
+	return nil
+}
+
+// region returns the signing region, defaulting for backends that ignore it
+func (c S3Config) region() string {
+	if c.Region == "" {
+		return appconfig.S3DefaultRegion
+	}
+	return c.Region
+}
+
+// httpClient returns an HTTP client honoring the TLS options
+// Returns nil when the SDK default client can be used
+func (c S3Config) httpClient() (*awshttp.BuildableClient, error) {
+	if !c.InsecureSkipVerify && c.CACertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: %s", ErrS3CACertificateFile, c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(transport *http.Transport) {
+		transport.TLSClientConfig = tlsConfig
+	}), nil
+}
@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	appconfig "ritual/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3Config_Validate(t *testing.T) {
+	valid := S3Config{Endpoint: "http://localhost:9000", AccessKeyID: "minio", SecretAccessKey: "minio123"}
+	assert.NoError(t, valid.Validate())
+
+	cases := map[string]struct {
+		mutate func(*S3Config)
+		err    error
+	}{
+		"empty endpoint":     {func(c *S3Config) { c.Endpoint = "" }, ErrS3EndpointEmpty},
+		"endpoint no scheme": {func(c *S3Config) { c.Endpoint = "localhost:9000" }, ErrS3EndpointInvalid},
+		"endpoint ftp":       {func(c *S3Config) { c.Endpoint = "ftp://localhost" }, ErrS3EndpointInvalid},
+		"missing access key": {func(c *S3Config) { c.AccessKeyID = "" }, ErrS3CredentialsEmpty},
+		"missing secret":     {func(c *S3Config) { c.SecretAccessKey = "" }, ErrS3CredentialsEmpty},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			tc.mutate(&cfg)
+			assert.ErrorIs(t, cfg.Validate(), tc.err)
+		})
+	}
+}
+
+func TestR2Config(t *testing.T) {
+	cfg := R2Config("account", "key", "secret")
+	assert.Equal(t, "https://account.r2.cloudflarestorage.com", cfg.Endpoint)
+	assert.Equal(t, appconfig.R2Region, cfg.Region)
+	assert.False(t, cfg.UsePathStyle)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestSetupS3Client(t *testing.T) {
+	t.Run("endpoint region and path style", func(t *testing.T) {
+		client, err := setupS3Client(S3Config{
+			Endpoint:        "http://localhost:9000",
+			AccessKeyID:     "minio",
+			SecretAccessKey: "minio123",
+			UsePathStyle:    true,
+		})
+		require.NoError(t, err)
+
+		options := client.Options()
+		assert.Equal(t, "http://localhost:9000", aws.ToString(options.BaseEndpoint))
+		assert.Equal(t, appconfig.S3DefaultRegion, options.Region)
+		assert.True(t, options.UsePathStyle)
+	})
+
+	t.Run("invalid configuration is rejected", func(t *testing.T) {
+		_, err := setupS3Client(S3Config{Endpoint: "http://localhost:9000"})
+		assert.ErrorIs(t, err, ErrS3CredentialsEmpty)
+	})
+
+	t.Run("CA certificate file without certificates is rejected", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0644))
+
+		_, err := setupS3Client(S3Config{
+			Endpoint:        "https://garage.local:3900",
+			Region:          "garage",
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+			CACertFile:      caFile,
+		})
+		assert.ErrorIs(t, err, ErrS3CACertificateFile)
+	})
+
+	t.Run("insecure TLS uses a custom HTTP client", func(t *testing.T) {
+		client, err := setupS3Client(S3Config{
+			Endpoint:           "https://localhost:9000",
+			AccessKeyID:        "key",
+			SecretAccessKey:    "secret",
+			InsecureSkipVerify: true,
+		})
+		require.NoError(t, err)
+		assert.IsType(t, &awshttp.BuildableClient{}, client.Options().HTTPClient)
+	})
+}
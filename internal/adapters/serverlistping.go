@@ -0,0 +1,250 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// ServerListPinger error constants
+var (
+	ErrPingerNil       = errors.New("server list pinger cannot be nil")
+	ErrPingMalformed   = errors.New("malformed server list ping response")
+	ErrVarIntTooLong   = errors.New("VarInt is too long")
+	ErrPingPacketLarge = errors.New("server list ping packet is too large")
+)
+
+// Server List Ping protocol values
+const (
+	slpProtocolUnknown = -1        // Handshake protocol version used when the server's version is not known
+	slpStateStatus     = 1         // Handshake next state that requests the status
+	slpPacketStatus    = 0x00      // ID of the handshake, status request and status response packets
+	slpMaxPacketLength = 1<<21 - 1 // Longest packet the protocol allows (three-byte VarInt length)
+	slpMaxVarIntBytes  = 5
+)
+
+// motdFormatCode matches legacy section-sign formatting codes such as "§a"
+var motdFormatCode = regexp.MustCompile(`§.`)
+
+// Compile-time check to ensure ServerListPinger implements ports.ServerPinger
+var _ ports.ServerPinger = (*ServerListPinger)(nil)
+
+// ServerListPinger reads a Minecraft server's status with the Server List Ping handshake
+// Each ping uses a new connection, since the server closes it after answering
+type ServerListPinger struct {
+	timeout time.Duration // Bounds connecting and reading the status response
+}
+
+// NewServerListPinger creates a new Server List Ping client
+// timeout bounds each ping unless ctx has an earlier deadline
+func NewServerListPinger(timeout time.Duration) (*ServerListPinger, error) {
+	if timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	return &ServerListPinger{timeout: timeout}, nil
+}
+
+// slpStatusResponse is the JSON document of the status response
+type slpStatusResponse struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int    `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"` // Plain string or chat component
+}
+
+// slpChatComponent is the part of a chat component that carries text
+type slpChatComponent struct {
+	Text  string            `json:"text"`
+	Extra []json.RawMessage `json:"extra"`
+}
+
+// Ping sends the handshake and status request to address and parses the status response
+func (p *ServerListPinger) Ping(ctx context.Context, address string) (*domain.ServerStatus, error) {
+	if p == nil {
+		return nil, ErrPingerNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in address %q: %w", address, err)
+	}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(p.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	status, err := p.exchange(conn, host, uint16(port))
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return status, nil
+}
+
+// exchange writes the handshake and status request, then reads the status response
+func (p *ServerListPinger) exchange(conn net.Conn, host string, port uint16) (*domain.ServerStatus, error) {
+	var handshake []byte
+	handshake = appendVarInt(handshake, slpPacketStatus)
+	handshake = appendVarInt(handshake, slpProtocolUnknown)
+	handshake = appendString(handshake, host)
+	handshake = binary.BigEndian.AppendUint16(handshake, port)
+	handshake = appendVarInt(handshake, slpStateStatus)
+
+	var request []byte
+	request = appendSLPPacket(request, handshake)
+	request = appendSLPPacket(request, appendVarInt(nil, slpPacketStatus))
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("failed to send status request: %w", err)
+	}
+
+	payload, err := readSLPPacket(bufio.NewReader(conn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+	return decodeStatusResponse(payload)
+}
+
+// decodeStatusResponse parses the payload of a status response packet
+func decodeStatusResponse(payload []byte) (*domain.ServerStatus, error) {
+	reader := bytes.NewReader(payload)
+	packetID, err := readVarInt(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPingMalformed, err)
+	}
+	if packetID != slpPacketStatus {
+		return nil, fmt.Errorf("%w: packet ID %#x, expected %#x", ErrPingMalformed, packetID, slpPacketStatus)
+	}
+	length, err := readVarInt(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPingMalformed, err)
+	}
+	if length < 0 || int(length) != reader.Len() {
+		return nil, fmt.Errorf("%w: JSON length %d, %d bytes left", ErrPingMalformed, length, reader.Len())
+	}
+
+	var response slpStatusResponse
+	if err := json.Unmarshal(payload[len(payload)-int(length):], &response); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPingMalformed, err)
+	}
+	return &domain.ServerStatus{
+		Version:       response.Version.Name,
+		Protocol:      response.Version.Protocol,
+		OnlinePlayers: response.Players.Online,
+		MaxPlayers:    response.Players.Max,
+		MOTD:          strings.TrimSpace(motdFormatCode.ReplaceAllString(chatText(response.Description), "")),
+	}, nil
+}
+
+// chatText flattens a plain string or chat component into its text
+// Components nest through "extra"; text that cannot be parsed is left out
+func chatText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var component slpChatComponent
+	if err := json.Unmarshal(raw, &component); err != nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(component.Text)
+	for _, extra := range component.Extra {
+		builder.WriteString(chatText(extra))
+	}
+	return builder.String()
+}
+
+// appendSLPPacket appends payload prefixed with its VarInt length
+func appendSLPPacket(buf, payload []byte) []byte {
+	buf = appendVarInt(buf, int32(len(payload)))
+	return append(buf, payload...)
+}
+
+// readSLPPacket reads one length-prefixed packet and returns its payload
+func readSLPPacket(r *bufio.Reader) ([]byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: packet length %d", ErrPingMalformed, length)
+	}
+	if length > slpMaxPacketLength {
+		return nil, fmt.Errorf("%w: length %d", ErrPingPacketLarge, length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// appendString appends s prefixed with its VarInt byte length
+func appendString(buf []byte, s string) []byte {
+	buf = appendVarInt(buf, int32(len(s)))
+	return append(buf, s...)
+}
+
+// appendVarInt appends value as a VarInt: seven bits per byte, least significant first
+// Negative values take the full five bytes
+func appendVarInt(buf []byte, value int32) []byte {
+	unsigned := uint32(value)
+	for unsigned >= 0x80 {
+		buf = append(buf, byte(unsigned)|0x80)
+		unsigned >>= 7
+	}
+	return append(buf, byte(unsigned))
+}
+
+// readVarInt reads one VarInt from r
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < slpMaxVarIntBytes; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, ErrVarIntTooLong
+}
@@ -0,0 +1,209 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"ritual/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slpHandshake is what the fake server read from a client's handshake
+type slpHandshake struct {
+	protocol  int32
+	host      string
+	port      uint16
+	nextState int32
+}
+
+// newFakeStatusServer answers each connection's handshake and status request with response
+// A nil response accepts the request but never answers
+func newFakeStatusServer(t *testing.T, response []byte) (string, <-chan slpHandshake) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	handshakes := make(chan slpHandshake, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				handshake, err := readFakeHandshake(reader)
+				if err != nil {
+					return
+				}
+				if _, err := readSLPPacket(reader); err != nil {
+					return
+				}
+				handshakes <- handshake
+				if response == nil {
+					io.Copy(io.Discard, reader)
+					return
+				}
+				conn.Write(response)
+			}()
+		}
+	}()
+	return listener.Addr().String(), handshakes
+}
+
+// readFakeHandshake decodes the handshake packet a client sends first
+func readFakeHandshake(r *bufio.Reader) (slpHandshake, error) {
+	payload, err := readSLPPacket(r)
+	if err != nil {
+		return slpHandshake{}, err
+	}
+	reader := bytes.NewReader(payload)
+	var handshake slpHandshake
+	if _, err := readVarInt(reader); err != nil {
+		return slpHandshake{}, err
+	}
+	if handshake.protocol, err = readVarInt(reader); err != nil {
+		return slpHandshake{}, err
+	}
+	length, err := readVarInt(reader)
+	if err != nil {
+		return slpHandshake{}, err
+	}
+	host := make([]byte, length)
+	if _, err := io.ReadFull(reader, host); err != nil {
+		return slpHandshake{}, err
+	}
+	handshake.host = string(host)
+	if err := binary.Read(reader, binary.BigEndian, &handshake.port); err != nil {
+		return slpHandshake{}, err
+	}
+	if handshake.nextState, err = readVarInt(reader); err != nil {
+		return slpHandshake{}, err
+	}
+	return handshake, nil
+}
+
+// statusResponse builds a status response packet carrying document
+func statusResponse(document string) []byte {
+	payload := appendVarInt(nil, slpPacketStatus)
+	payload = appendString(payload, document)
+	return appendSLPPacket(nil, payload)
+}
+
+func TestVarInt_RoundTrip(t *testing.T) {
+	tests := []struct {
+		value   int32
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{25565, []byte{0xdd, 0xc7, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(int(tt.value)), func(t *testing.T) {
+			assert.Equal(t, tt.encoded, appendVarInt(nil, tt.value))
+			value, err := readVarInt(bytes.NewReader(tt.encoded))
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+
+	_, err := readVarInt(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x01}))
+	assert.ErrorIs(t, err, ErrVarIntTooLong)
+}
+
+func TestNewServerListPinger(t *testing.T) {
+	_, err := NewServerListPinger(0)
+	assert.Error(t, err)
+
+	pinger, err := NewServerListPinger(time.Second)
+	require.NoError(t, err)
+	assert.NotNil(t, pinger)
+
+	var nilPinger *ServerListPinger
+	_, err = nilPinger.Ping(context.Background(), "127.0.0.1:25565")
+	assert.ErrorIs(t, err, ErrPingerNil)
+}
+
+func TestServerListPinger_Ping(t *testing.T) {
+	ctx := context.Background()
+	pinger, err := NewServerListPinger(5 * time.Second)
+	require.NoError(t, err)
+
+	t.Run("plain description", func(t *testing.T) {
+		address, handshakes := newFakeStatusServer(t, statusResponse(
+			`{"version":{"name":"1.21.1","protocol":767},"players":{"max":20,"online":3,"sample":[{"name":"owl","id":"4566e69f-c907-48ee-8d71-d7ba5aa00d20"}]},"description":"§aA Minecraft Server"}`))
+
+		status, err := pinger.Ping(ctx, address)
+		require.NoError(t, err)
+		assert.Equal(t, &domain.ServerStatus{Version: "1.21.1", Protocol: 767, OnlinePlayers: 3, MaxPlayers: 20, MOTD: "A Minecraft Server"}, status)
+
+		handshake := <-handshakes
+		_, port, _ := net.SplitHostPort(address)
+		assert.Equal(t, int32(slpProtocolUnknown), handshake.protocol)
+		assert.Equal(t, "127.0.0.1", handshake.host)
+		assert.Equal(t, port, strconv.Itoa(int(handshake.port)))
+		assert.Equal(t, int32(slpStateStatus), handshake.nextState)
+	})
+
+	t.Run("chat component description", func(t *testing.T) {
+		address, _ := newFakeStatusServer(t, statusResponse(
+			`{"version":{"name":"Paper 1.20.4","protocol":765},"players":{"max":10,"online":0},"description":{"text":"Ritual ","extra":[{"text":"§lworld","bold":true},{"extra":["\nof owls"]}]}}`))
+
+		status, err := pinger.Ping(ctx, address)
+		require.NoError(t, err)
+		assert.Equal(t, "Ritual world\nof owls", status.MOTD)
+		assert.Equal(t, "Paper 1.20.4", status.Version)
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		address, _ := newFakeStatusServer(t, statusResponse(`{"version":`))
+
+		_, err := pinger.Ping(ctx, address)
+		assert.ErrorIs(t, err, ErrPingMalformed)
+	})
+
+	t.Run("nothing listening", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		_, err = pinger.Ping(ctx, address)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := pinger.Ping(ctx, "127.0.0.1")
+		assert.Error(t, err)
+		_, err = pinger.Ping(ctx, "127.0.0.1:70000")
+		assert.Error(t, err)
+	})
+}
+
+func TestServerListPinger_PingCancelled(t *testing.T) {
+	address, _ := newFakeStatusServer(t, nil)
+	pinger, err := NewServerListPinger(time.Minute)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = pinger.Ping(ctx, address)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
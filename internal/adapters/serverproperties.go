@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"strconv"
+	"strings"
+)
+
+// serverProperty is a single key=value entry in server.properties
+type serverProperty struct {
+	key   string
+	value string
+}
+
+// serverAddressProperties returns the server.properties entries derived from the server config
+func serverAddressProperties(server *domain.Server) []serverProperty {
+	return []serverProperty{
+		{key: "server-ip", value: server.IP},
+		{key: "server-port", value: strconv.Itoa(server.Port)},
+	}
+}
+
+// serverRconProperties returns the server.properties entries that enable RCON
+// A password is generated and stored on server unless it already has one
+func serverRconProperties(server *domain.Server) ([]serverProperty, error) {
+	if server.RconPassword == "" {
+		password, err := generateRconPassword()
+		if err != nil {
+			return nil, err
+		}
+		server.RconPassword = password
+	}
+	return []serverProperty{
+		{key: "enable-rcon", value: "true"},
+		{key: "rcon.port", value: strconv.Itoa(server.RconListenPort())},
+		{key: "rcon.password", value: server.RconPassword},
+	}, nil
+}
+
+// generateRconPassword returns a random hex password
+func generateRconPassword() (string, error) {
+	buf := make([]byte, config.RconPasswordBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate RCON password: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// applyServerConfig sets the address of server in the server.properties next to startScript
+// With rcon the RCON properties are set as well; otherwise the file's RCON settings are left alone
+func applyServerConfig(workRoot *os.Root, startScript string, server *domain.Server, rcon bool) error {
+	props := serverAddressProperties(server)
+	if rcon {
+		rconProps, err := serverRconProperties(server)
+		if err != nil {
+			return err
+		}
+		props = append(props, rconProps...)
+	}
+	return applyServerProperties(workRoot, startScript, props)
+}
+
+// applyServerProperties sets the given properties in the server.properties file next to startScript
+// Existing keys are replaced in place, missing keys are appended, other lines are preserved
+func applyServerProperties(workRoot *os.Root, startScript string, props []serverProperty) error {
+	if workRoot == nil {
+		return fmt.Errorf("workRoot cannot be nil")
+	}
+	propsPath := filepath.Join(filepath.Dir(startScript), "server.properties")
+
+	// Read existing properties using workRoot
+	file, err := workRoot.Open(propsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Create new file with just the given properties
+			return writeServerProperties(workRoot, propsPath, props, nil)
+		}
+		return fmt.Errorf("failed to open server.properties: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read server.properties: %w", err)
+	}
+
+	return writeServerProperties(workRoot, propsPath, props, lines)
+}
+
+// writeServerProperties writes the updated server.properties file
+func writeServerProperties(workRoot *os.Root, propsPath string, props []serverProperty, existingLines []string) error {
+	found := make([]bool, len(props))
+
+	var newLines []string
+	for _, line := range existingLines {
+		trimmed := strings.TrimSpace(line)
+		replaced := false
+		for i, prop := range props {
+			if strings.HasPrefix(trimmed, prop.key+"=") {
+				newLines = append(newLines, prop.key+"="+prop.value)
+				found[i] = true
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			newLines = append(newLines, line)
+		}
+	}
+
+	// Add missing properties
+	for i, prop := range props {
+		if !found[i] {
+			newLines = append(newLines, prop.key+"="+prop.value)
+		}
+	}
+
+	content := strings.Join(newLines, "\n")
+	if len(newLines) > 0 {
+		content += "\n"
+	}
+
+	// Write using workRoot
+	file, err := workRoot.Create(propsPath)
+	if err != nil {
+		return fmt.Errorf("failed to create server.properties: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	return err
+}
@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyServerConfig_UpdatesServerProperties(t *testing.T) {
+	// This test verifies that IP/port are written to server.properties over the old values
+	testCases := []struct {
+		name string
+		ip   string
+		port int
+	}{
+		{
+			name: "standard config",
+			ip:   "0.0.0.0",
+			port: 25565,
+		},
+		{
+			name: "custom port and IP",
+			ip:   "192.168.1.100",
+			port: 25566,
+		},
+		{
+			name: "localhost",
+			ip:   "127.0.0.1",
+			port: 19132,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			workRoot, err := os.OpenRoot(tempDir)
+			require.NoError(t, err)
+			defer workRoot.Close()
+
+			instanceDir := filepath.Join(tempDir, "instance")
+			err = os.MkdirAll(instanceDir, 0755)
+			require.NoError(t, err)
+
+			// Create initial server.properties with different values to test override
+			propsPath := filepath.Join(instanceDir, "server.properties")
+			err = os.WriteFile(propsPath, []byte("server-ip=old-ip\nserver-port=12345\nother-setting=value\n"), 0644)
+			require.NoError(t, err)
+
+			address := tc.ip + ":" + strconv.Itoa(tc.port)
+			server, err := domain.NewServer(address, 2048)
+			require.NoError(t, err)
+
+			err = applyServerConfig(workRoot, filepath.Join("instance", "run.bat"), server, false)
+			assert.NoError(t, err)
+
+			// Verify server.properties was updated with correct IP and port (overriding old values)
+			propsContent, err := os.ReadFile(propsPath)
+			assert.NoError(t, err)
+			assert.Contains(t, string(propsContent), "server-ip="+tc.ip)
+			assert.Contains(t, string(propsContent), "server-port="+strconv.Itoa(tc.port))
+			assert.Contains(t, string(propsContent), "other-setting=value", "Other settings should be preserved")
+			assert.NotContains(t, string(propsContent), "old-ip", "Old IP should be replaced")
+			assert.NotContains(t, string(propsContent), "12345", "Old port should be replaced")
+			assert.NotContains(t, string(propsContent), "enable-rcon", "RCON is left alone unless enabled")
+		})
+	}
+}
+
+func TestApplyServerConfig_CreatesServerPropertiesIfMissing(t *testing.T) {
+	tempDir := t.TempDir()
+	workRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	defer workRoot.Close()
+
+	instanceDir := filepath.Join(tempDir, "instance")
+	err = os.MkdirAll(instanceDir, 0755)
+	require.NoError(t, err)
+
+	// Note: NOT creating server.properties - it should be created
+	server, err := domain.NewServer("192.168.1.50:25570", 2048)
+	require.NoError(t, err)
+
+	err = applyServerConfig(workRoot, filepath.Join("instance", "run.bat"), server, true)
+	assert.NoError(t, err)
+
+	// Verify server.properties was created with IP and port
+	propsPath := filepath.Join(instanceDir, "server.properties")
+	propsContent, err := os.ReadFile(propsPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(propsContent), "server-ip=192.168.1.50")
+	assert.Contains(t, string(propsContent), "server-port=25570")
+
+	// Enabled RCON gets a generated password kept on the server for clients
+	assert.Len(t, server.RconPassword, 2*config.RconPasswordBytes)
+	assert.Contains(t, string(propsContent), "enable-rcon=true")
+	assert.Contains(t, string(propsContent), "rcon.port=25580")
+	assert.Contains(t, string(propsContent), "rcon.password="+server.RconPassword)
+}
+
+func TestServerRconProperties(t *testing.T) {
+	server := &domain.Server{IP: "127.0.0.1", Port: 25565, RconPort: 30000, RconPassword: "kept"}
+	props, err := serverRconProperties(server)
+	require.NoError(t, err)
+	assert.Equal(t, []serverProperty{
+		{key: "enable-rcon", value: "true"},
+		{key: "rcon.port", value: "30000"},
+		{key: "rcon.password", value: "kept"},
+	}, props)
+
+	first := &domain.Server{IP: "127.0.0.1", Port: 25565}
+	second := &domain.Server{IP: "127.0.0.1", Port: 25565}
+	_, err = serverRconProperties(first)
+	require.NoError(t, err)
+	_, err = serverRconProperties(second)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RconPassword, second.RconPassword, "each run gets a new password")
+}
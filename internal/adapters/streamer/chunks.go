@@ -0,0 +1,459 @@
+package streamer
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ritual/internal/core/ports"
+)
+
+// Chunk store error constants
+var (
+	ErrChunkListerNil = errors.New("chunk lister cannot be nil")
+	ErrChunkIndexKey  = errors.New("chunk index key must end with " + IndexExtension)
+	ErrChunkIndex     = errors.New("invalid chunk index")
+	ErrChunkMismatch  = errors.New("chunk content does not match its hash")
+)
+
+// Chunk store layout
+//
+//	chunks/<sha256>          plain chunk
+//	chunks/<keyID>-<sha256>  chunk encrypted with keyID
+//	worlds/<ts>.index.json   per-backup index listing each file's chunks
+//
+// Chunks are named by the SHA-256 of their plaintext, so unchanged data is stored once
+// and shared by every backup that contains it. The index stays unencrypted so retention
+// can count references without keys; it holds file names and chunk hashes only
+const (
+	ChunkPrefix    = "chunks/"
+	IndexExtension = ".index.json"
+	ChunkSize      = 1024 * 1024 // Region files change in place, so fixed offsets dedup well
+	indexVersion   = 1
+)
+
+// ChunkIndex describes a directory snapshot rebuilt from content-addressed chunks
+type ChunkIndex struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	KeyID     string       `json:"key_id,omitempty"` // Key that encrypted the chunks, empty if unencrypted
+	Entries   []IndexEntry `json:"entries"`
+}
+
+// IndexEntry is a directory or file in a chunk index
+type IndexEntry struct {
+	Path   string   `json:"path"` // Slash-separated, prefixed with the source directory name
+	Dir    bool     `json:"dir,omitempty"`
+	Mode   int64    `json:"mode"`
+	Size   int64    `json:"size,omitempty"`
+	Hash   string   `json:"hash,omitempty"`   // SHA-256 hex of the whole file
+	Chunks []string `json:"chunks,omitempty"` // SHA-256 hex of each plaintext chunk in order
+}
+
+// IsChunkIndex reports whether key names a chunk index rather than an archive
+func IsChunkIndex(key string) bool {
+	return strings.HasSuffix(key, IndexExtension)
+}
+
+// ParseChunkIndex decodes a chunk index object
+func ParseChunkIndex(data []byte) (*ChunkIndex, error) {
+	var index ChunkIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrChunkIndex, err)
+	}
+	if index.Version != indexVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrChunkIndex, index.Version)
+	}
+	return &index, nil
+}
+
+// ChunkKeys returns the object keys of every chunk the index references, without duplicates
+func (i *ChunkIndex) ChunkKeys() []string {
+	if i == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, entry := range i.Entries {
+		for _, hash := range entry.Chunks {
+			key := chunkKey(i.KeyID, hash)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// chunkKey returns the object key of a chunk
+// Encrypted chunks carry the key ID so a rotated key stores its own copy
+func chunkKey(keyID, hash string) string {
+	if keyID == "" {
+		return ChunkPrefix + hash
+	}
+	return ChunkPrefix + keyID + "-" + hash
+}
+
+// PushChunks uploads directories as content-addressed chunks and writes an index to cfg.Key
+// Chunks already listed under ChunkPrefix are not uploaded again
+// cfg.LocalPath is ignored; the returned size and checksum describe the index object
+func PushChunks(ctx context.Context, cfg PushConfig, uploader S3StreamUploader, lister S3StreamLister) (Result, error) {
+	if ctx == nil {
+		return Result{}, ErrPushContextNil
+	}
+	if cfg.Bucket == "" {
+		return Result{}, ErrPushBucketEmpty
+	}
+	if cfg.Key == "" {
+		return Result{}, ErrPushKeyEmpty
+	}
+	if !IsChunkIndex(cfg.Key) {
+		return Result{}, ErrChunkIndexKey
+	}
+	if len(cfg.Dirs) == 0 {
+		return Result{}, ErrPushDirsEmpty
+	}
+	if uploader == nil {
+		return Result{}, ErrPushUploaderNil
+	}
+	if lister == nil {
+		return Result{}, ErrChunkListerNil
+	}
+
+	codec := cfg.Codec
+	if codec == nil {
+		codec = NoCompression
+	}
+
+	stored, err := lister.List(ctx, ChunkPrefix)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to list stored chunks: %w", err)
+	}
+
+	pusher := &chunkPusher{
+		cfg:      cfg,
+		codec:    codec,
+		uploader: uploader,
+		stored:   make(map[string]bool, len(stored)),
+		buf:      make([]byte, ChunkSize),
+	}
+	for _, key := range stored {
+		pusher.stored[key] = true
+	}
+
+	index := ChunkIndex{Version: indexVersion, CreatedAt: time.Now(), KeyID: cfg.EncryptKey.ID()}
+	for _, dir := range cfg.Dirs {
+		entries, err := pusher.addDir(ctx, dir)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to chunk %s: %w", dir, err)
+		}
+		index.Entries = append(index.Entries, entries...)
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode chunk index: %w", err)
+	}
+	if _, err := uploader.Upload(ctx, cfg.Bucket, cfg.Key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return Result{}, fmt.Errorf("R2 upload of chunk index failed: %w", err)
+	}
+
+	ports.SendEvent(cfg.Events, ports.UpdateEvent{
+		Operation: "archive",
+		Message:   "Chunked upload complete",
+		Data: map[string]any{
+			"new_chunks":    pusher.uploaded,
+			"reused_chunks": pusher.reused,
+			"uploaded_mb":   fmt.Sprintf("%.2f", float64(pusher.uploadedBytes)/(1024*1024)),
+		},
+	})
+
+	return Result{
+		Size:      int64(len(data)),
+		Checksum:  fmt.Sprintf("%x", sha256.Sum256(data)),
+		Key:       cfg.Key,
+		KeyID:     index.KeyID,
+		NewChunks: pusher.uploaded,
+	}, nil
+}
+
+// chunkPusher splits files into chunks and uploads the ones not yet stored
+type chunkPusher struct {
+	cfg           PushConfig
+	codec         Codec
+	uploader      S3StreamUploader
+	stored        map[string]bool
+	buf           []byte
+	uploaded      int
+	reused        int
+	uploadedBytes int64
+}
+
+// addDir walks root and returns its index entries, named like addDirToTar names tar entries
+func (p *chunkPusher) addDir(ctx context.Context, root string) ([]IndexEntry, error) {
+	baseName := filepath.Base(root)
+
+	var entries []IndexEntry
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry := IndexEntry{
+			Path: filepath.ToSlash(filepath.Join(baseName, relPath)),
+			Mode: int64(info.Mode().Perm()),
+		}
+		if p.cfg.Exclude != nil && relPath != "." && p.cfg.Exclude(entry.Path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			entry.Dir = true
+			entries = append(entries, entry)
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		entry.Size = info.Size()
+		entry.Chunks, entry.Hash, err = p.addFile(ctx, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// addFile uploads the chunks of one file and returns their hashes and the hash of the file
+func (p *chunkPusher) addFile(ctx context.Context, path string) ([]string, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	fileHash := sha256.New()
+	var hashes []string
+	for {
+		n, err := io.ReadFull(file, p.buf)
+		if n > 0 {
+			fileHash.Write(p.buf[:n])
+			sum := sha256.Sum256(p.buf[:n])
+			hash := hex.EncodeToString(sum[:])
+			if err := p.upload(ctx, hash, p.buf[:n]); err != nil {
+				return nil, "", err
+			}
+			hashes = append(hashes, hash)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return hashes, hex.EncodeToString(fileHash.Sum(nil)), nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+// upload stores one chunk unless an identical chunk is already stored
+func (p *chunkPusher) upload(ctx context.Context, hash string, chunk []byte) error {
+	key := chunkKey(p.cfg.EncryptKey.ID(), hash)
+	if p.stored[key] {
+		p.reused++
+		return nil
+	}
+
+	encoded, err := encodeChunk(chunk, p.codec, p.cfg.EncryptKey)
+	if err != nil {
+		return err
+	}
+	if _, err := p.uploader.Upload(ctx, p.cfg.Bucket, key, bytes.NewReader(encoded), int64(len(encoded))); err != nil {
+		return fmt.Errorf("R2 upload of chunk %s failed: %w", hash, err)
+	}
+
+	p.stored[key] = true
+	p.uploaded++
+	p.uploadedBytes += int64(len(encoded))
+	return nil
+}
+
+// encodeChunk compresses and optionally encrypts a chunk, the same way Push encodes archives
+func encodeChunk(chunk []byte, codec Codec, key *Key) ([]byte, error) {
+	var buf bytes.Buffer
+	sealer := io.WriteCloser(nopWriteCloser{&buf})
+	if key != nil {
+		var err error
+		sealer, err = newEncryptWriter(&buf, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	encoder, err := codec.NewWriter(sealer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s encoder: %w", codec.Name(), err)
+	}
+	if _, err := encoder.Write(chunk); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s encoder: %w", codec.Name(), err)
+	}
+	if err := sealer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to seal chunk: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pullIndex rebuilds the directories described by an index into destAbs
+// Every chunk is checked against its hash before it is written
+func pullIndex(ctx context.Context, cfg PullConfig, destAbs string, r io.Reader, downloader S3StreamDownloader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read chunk index: %w", err)
+	}
+	index, err := ParseChunkIndex(data)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range index.Entries {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if cfg.Filter != nil && !cfg.Filter(entry.Path) {
+			continue
+		}
+
+		targetPath := filepath.Join(destAbs, filepath.FromSlash(entry.Path))
+		if !isPathSafe(destAbs, targetPath) {
+			return fmt.Errorf("%w: %s", ErrPathTraversal, entry.Path)
+		}
+
+		// Entries go through the same conflict and extraction path as tar members
+		header := &tar.Header{Name: entry.Path, Mode: entry.Mode, Size: entry.Size, Typeflag: tar.TypeReg}
+		if entry.Dir {
+			header.Typeflag = tar.TypeDir
+		}
+
+		err := handleConflict(targetPath, header, cfg.Conflict)
+		if err == errSkipFile {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry.Dir {
+			if err := os.MkdirAll(targetPath, os.FileMode(entry.Mode)); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
+			}
+			continue
+		}
+
+		content := &chunkReader{ctx: ctx, cfg: cfg, keyID: index.KeyID, hashes: entry.Chunks, downloader: downloader}
+		if err := extractFile(ctx, targetPath, content, header); err != nil {
+			return fmt.Errorf("failed to extract file %s: %w", targetPath, err)
+		}
+	}
+
+	return nil
+}
+
+// chunkReader streams the chunks of one file in order
+type chunkReader struct {
+	ctx        context.Context
+	cfg        PullConfig
+	keyID      string
+	hashes     []string
+	downloader S3StreamDownloader
+	chunk      []byte
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.chunk) == 0 {
+		if len(c.hashes) == 0 {
+			return 0, io.EOF
+		}
+		chunk, err := fetchChunk(c.ctx, c.cfg, c.keyID, c.hashes[0], c.downloader)
+		if err != nil {
+			return 0, err
+		}
+		c.chunk = chunk
+		c.hashes = c.hashes[1:]
+	}
+
+	n := copy(p, c.chunk)
+	c.chunk = c.chunk[n:]
+	return n, nil
+}
+
+// fetchChunk downloads, decodes and verifies one chunk
+func fetchChunk(ctx context.Context, cfg PullConfig, keyID, hash string, downloader S3StreamDownloader) ([]byte, error) {
+	key := chunkKey(keyID, hash)
+	body, err := downloader.Download(ctx, cfg.Bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("R2 download of chunk %s failed: %w", hash, err)
+	}
+	defer body.Close()
+
+	buffered := bufio.NewReader(body)
+	if isEncrypted(buffered) {
+		plain, _, err := newDecryptReader(buffered, cfg.Keys)
+		if err != nil {
+			return nil, err
+		}
+		buffered = bufio.NewReader(plain)
+	}
+
+	codec, err := detectCodec(key, buffered)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := codec.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s decoder: %w", codec.Name(), err)
+	}
+	defer decoder.Close()
+
+	chunk, err := io.ReadAll(io.LimitReader(decoder, ChunkSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk %s: %w", hash, err)
+	}
+	sum := sha256.Sum256(chunk)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, fmt.Errorf("%w: %s", ErrChunkMismatch, key)
+	}
+	return chunk, nil
+}
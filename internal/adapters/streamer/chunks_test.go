@@ -0,0 +1,211 @@
+package streamer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChunkStore is an in-memory object store for chunked push and pull
+type mockChunkStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads []string
+}
+
+func newMockChunkStore() *mockChunkStore {
+	return &mockChunkStore{objects: make(map[string][]byte)}
+}
+
+func (m *mockChunkStore) Upload(ctx context.Context, bucket, key string, body io.Reader, _ int64) (int64, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	m.uploads = append(m.uploads, key)
+	return int64(len(data)), nil
+}
+
+func (m *mockChunkStore) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("key not found: %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *mockChunkStore) List(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// chunkUploads returns how many chunk objects were uploaded
+func (m *mockChunkStore) chunkUploads() int {
+	count := 0
+	for _, key := range m.uploads {
+		if strings.HasPrefix(key, ChunkPrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// setupChunkWorld creates a world with a multi-chunk region file and a small level.dat
+func setupChunkWorld(t *testing.T) (string, []byte) {
+	t.Helper()
+	worldDir := filepath.Join(t.TempDir(), "world")
+	require.NoError(t, os.MkdirAll(filepath.Join(worldDir, "region"), 0755))
+
+	region := make([]byte, 3*ChunkSize+100)
+	for i := range region {
+		region[i] = byte(i / ChunkSize)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "region", "r.0.0.mca"), region, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "level.dat"), []byte("level data"), 0644))
+	return worldDir, region
+}
+
+func TestPushChunks_Validation(t *testing.T) {
+	store := newMockChunkStore()
+	ctx := context.Background()
+	cfg := PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{t.TempDir()}}
+
+	_, err := PushChunks(nil, cfg, store, store)
+	assert.ErrorIs(t, err, ErrPushContextNil)
+
+	bad := cfg
+	bad.Key = "worlds/1.tar"
+	_, err = PushChunks(ctx, bad, store, store)
+	assert.ErrorIs(t, err, ErrChunkIndexKey)
+
+	_, err = PushChunks(ctx, cfg, nil, store)
+	assert.ErrorIs(t, err, ErrPushUploaderNil)
+
+	_, err = PushChunks(ctx, cfg, store, nil)
+	assert.ErrorIs(t, err, ErrChunkListerNil)
+}
+
+func TestPushChunks_Deduplicates(t *testing.T) {
+	worldDir, region := setupChunkWorld(t)
+	store := newMockChunkStore()
+	ctx := context.Background()
+
+	first, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{worldDir}, Codec: Gzip}, store, store)
+	require.NoError(t, err)
+	// Region chunks 0-2 are distinct, plus the tail and level.dat
+	assert.Equal(t, 5, first.NewChunks)
+	assert.Equal(t, sha256Hex(store.objects["worlds/1.index.json"]), first.Checksum)
+
+	// Change only the second region chunk
+	original := bytes.Clone(region)
+	region[ChunkSize+10] ^= 0xff
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "region", "r.0.0.mca"), region, 0644))
+
+	uploadsBefore := store.chunkUploads()
+	second, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/2.index.json", Dirs: []string{worldDir}, Codec: Gzip}, store, store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.NewChunks)
+	assert.Equal(t, uploadsBefore+1, store.chunkUploads())
+
+	// Both snapshots rebuild to their own content
+	for key, expected := range map[string][]byte{"worlds/1.index.json": original, "worlds/2.index.json": region} {
+		destDir := t.TempDir()
+		require.NoError(t, Pull(ctx, PullConfig{Bucket: "b", Key: key, Dest: destDir}, store))
+
+		content, err := os.ReadFile(filepath.Join(destDir, "world", "region", "r.0.0.mca"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, content)
+		level, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "level data", string(level))
+	}
+}
+
+func TestChunkIndex_ChunkKeys(t *testing.T) {
+	worldDir, _ := setupChunkWorld(t)
+	store := newMockChunkStore()
+	ctx := context.Background()
+
+	_, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{worldDir}}, store, store)
+	require.NoError(t, err)
+
+	index, err := ParseChunkIndex(store.objects["worlds/1.index.json"])
+	require.NoError(t, err)
+
+	stored, err := store.List(ctx, ChunkPrefix)
+	require.NoError(t, err)
+	keys := index.ChunkKeys()
+	sort.Strings(keys)
+	assert.Equal(t, stored, keys)
+
+	_, err = ParseChunkIndex([]byte("not json"))
+	assert.ErrorIs(t, err, ErrChunkIndex)
+	_, err = ParseChunkIndex([]byte(`{"version": 99}`))
+	assert.ErrorIs(t, err, ErrChunkIndex)
+}
+
+func TestPullIndex_Failures(t *testing.T) {
+	worldDir, _ := setupChunkWorld(t)
+	ctx := context.Background()
+
+	t.Run("corrupted chunk is rejected", func(t *testing.T) {
+		store := newMockChunkStore()
+		_, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{worldDir}}, store, store)
+		require.NoError(t, err)
+
+		chunks, err := store.List(ctx, ChunkPrefix)
+		require.NoError(t, err)
+		for _, key := range chunks {
+			store.objects[key] = append([]byte("x"), store.objects[key][1:]...)
+		}
+
+		err = Pull(ctx, PullConfig{Bucket: "b", Key: "worlds/1.index.json", Dest: t.TempDir()}, store)
+		assert.ErrorIs(t, err, ErrChunkMismatch)
+	})
+
+	t.Run("encrypted chunks need the key", func(t *testing.T) {
+		store := newMockChunkStore()
+		key := testKey(t)
+		result, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{worldDir}, Codec: Gzip, EncryptKey: key}, store, store)
+		require.NoError(t, err)
+		assert.Equal(t, key.ID(), result.KeyID)
+
+		chunks, err := store.List(ctx, ChunkPrefix+key.ID())
+		require.NoError(t, err)
+		assert.Len(t, chunks, result.NewChunks)
+
+		err = Pull(ctx, PullConfig{Bucket: "b", Key: "worlds/1.index.json", Dest: t.TempDir()}, store)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+
+		keys, err := NewKeyring(key)
+		require.NoError(t, err)
+		destDir := t.TempDir()
+		require.NoError(t, Pull(ctx, PullConfig{Bucket: "b", Key: "worlds/1.index.json", Dest: destDir, Keys: keys}, store))
+		level, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "level data", string(level))
+	})
+}
@@ -0,0 +1,167 @@
+package streamer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Codec error constants
+var (
+	ErrCodecNil     = errors.New("codec cannot be nil")
+	ErrCodecUnknown = errors.New("unknown codec")
+	ErrCodecExists  = errors.New("codec already registered")
+)
+
+// Codec compresses and decompresses archive streams
+// Implementations must be safe for concurrent use
+type Codec interface {
+	// Name identifies the codec in configuration (e.g. "gzip")
+	Name() string
+	// Extension is appended to the archive key after ".tar" (e.g. ".gz"); empty for no compression
+	Extension() string
+	// Magic returns the leading bytes of every encoded stream; nil if streams cannot be detected
+	Magic() []byte
+	// NewWriter returns a writer that encodes into w; Close flushes without closing w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader that decodes r
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Built-in codecs
+var (
+	NoCompression Codec = noneCodec{}
+	Gzip          Codec = gzipCodec{level: gzip.DefaultCompression}
+)
+
+// codecRegistry holds the codecs Pull can detect, in detection order
+var (
+	codecMu       sync.RWMutex
+	codecRegistry = []Codec{Gzip}
+)
+
+// RegisterCodec makes a codec available to CodecByName and Pull detection
+func RegisterCodec(codec Codec) error {
+	if codec == nil {
+		return ErrCodecNil
+	}
+
+	codecMu.Lock()
+	defer codecMu.Unlock()
+
+	for _, registered := range codecRegistry {
+		if registered.Name() == codec.Name() {
+			return fmt.Errorf("%w: %s", ErrCodecExists, codec.Name())
+		}
+	}
+	codecRegistry = append(codecRegistry, codec)
+	return nil
+}
+
+// CodecByName returns the registered codec with the given name
+// An empty name or "none" selects NoCompression
+func CodecByName(name string) (Codec, error) {
+	if name == "" || name == NoCompression.Name() {
+		return NoCompression, nil
+	}
+
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+
+	for _, codec := range codecRegistry {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrCodecUnknown, name)
+}
+
+// CodecForKey returns the codec whose extension ends key
+// Returns NoCompression if no registered extension matches
+func CodecForKey(key string) Codec {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+
+	for _, codec := range codecRegistry {
+		if ext := codec.Extension(); ext != "" && strings.HasSuffix(key, ext) {
+			return codec
+		}
+	}
+	return NoCompression
+}
+
+// TrimCodecExtension removes a registered codec extension from key
+// "worlds/x.tar.gz" becomes "worlds/x.tar"; keys without one are returned unchanged
+func TrimCodecExtension(key string) string {
+	return strings.TrimSuffix(key, CodecForKey(key).Extension())
+}
+
+// detectCodec picks the codec for a stream from the key extension, falling back to magic bytes
+// Streams without a known extension or magic are treated as uncompressed
+func detectCodec(key string, r *bufio.Reader) (Codec, error) {
+	if codec := CodecForKey(key); codec != NoCompression {
+		return codec, nil
+	}
+
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+
+	for _, codec := range codecRegistry {
+		magic := codec.Magic()
+		if len(magic) == 0 {
+			continue
+		}
+		head, err := r.Peek(len(magic))
+		if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("failed to read archive header: %w", err)
+		}
+		if bytes.Equal(head, magic) {
+			return codec, nil
+		}
+	}
+	return NoCompression, nil
+}
+
+// noneCodec passes streams through unchanged
+type noneCodec struct{}
+
+func (noneCodec) Name() string      { return "none" }
+func (noneCodec) Extension() string { return "" }
+func (noneCodec) Magic() []byte     { return nil }
+
+func (noneCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+func (noneCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+// gzipCodec compresses streams with gzip at the given level
+type gzipCodec struct {
+	level int
+}
+
+func (gzipCodec) Name() string      { return "gzip" }
+func (gzipCodec) Extension() string { return ".gz" }
+func (gzipCodec) Magic() []byte     { return []byte{0x1f, 0x8b} }
+
+func (c gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// nopWriteCloser adds a no-op Close to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,143 @@
+package streamer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecByName(t *testing.T) {
+	codec, err := CodecByName("")
+	require.NoError(t, err)
+	assert.Equal(t, NoCompression, codec)
+
+	codec, err = CodecByName("none")
+	require.NoError(t, err)
+	assert.Equal(t, NoCompression, codec)
+
+	codec, err = CodecByName("gzip")
+	require.NoError(t, err)
+	assert.Equal(t, Gzip, codec)
+
+	_, err = CodecByName("brotli")
+	assert.ErrorIs(t, err, ErrCodecUnknown)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	assert.ErrorIs(t, RegisterCodec(nil), ErrCodecNil)
+	assert.ErrorIs(t, RegisterCodec(Gzip), ErrCodecExists)
+}
+
+func TestCodecForKey(t *testing.T) {
+	assert.Equal(t, Gzip, CodecForKey("worlds/20250101.tar.gz"))
+	assert.Equal(t, NoCompression, CodecForKey("worlds/20250101.tar"))
+	assert.Equal(t, NoCompression, CodecForKey("instance.tar"))
+
+	assert.Equal(t, "worlds/20250101.tar", TrimCodecExtension("worlds/20250101.tar.gz"))
+	assert.Equal(t, "instance.tar", TrimCodecExtension("instance.tar"))
+}
+
+func TestDetectCodec(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, err := w.Write([]byte("payload"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	t.Run("magic bytes without extension", func(t *testing.T) {
+		codec, err := detectCodec("backup.tar", bufio.NewReader(bytes.NewReader(gz.Bytes())))
+		require.NoError(t, err)
+		assert.Equal(t, Gzip, codec)
+	})
+
+	t.Run("plain tar falls back to none", func(t *testing.T) {
+		archive := createTestArchive(t, map[string][]byte{"world/level.dat": []byte("data")})
+		codec, err := detectCodec("backup.tar", bufio.NewReader(bytes.NewReader(archive)))
+		require.NoError(t, err)
+		assert.Equal(t, NoCompression, codec)
+	})
+
+	t.Run("short stream falls back to none", func(t *testing.T) {
+		codec, err := detectCodec("backup.tar", bufio.NewReader(bytes.NewReader([]byte{0x1f})))
+		require.NoError(t, err)
+		assert.Equal(t, NoCompression, codec)
+	})
+}
+
+func TestPushPull_Gzip(t *testing.T) {
+	worldDir := filepath.Join(t.TempDir(), "world")
+	require.NoError(t, os.MkdirAll(filepath.Join(worldDir, "region"), 0755))
+	level := bytes.Repeat([]byte("level data "), 1000)
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "level.dat"), level, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "region", "r.0.0.mca"), []byte("region data"), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := Push(context.Background(), PushConfig{
+		Bucket: "test-bucket",
+		Key:    "backups/test.tar.gz",
+		Dirs:   []string{worldDir},
+		Codec:  Gzip,
+	}, &mockUploader{buf: buf})
+	require.NoError(t, err)
+
+	// Size and checksum describe the compressed object
+	uploaded := buf.Bytes()
+	assert.Equal(t, Gzip.Magic(), uploaded[:2])
+	assert.Equal(t, int64(len(uploaded)), result.Size)
+	assert.Less(t, result.Size, int64(len(level)))
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(uploaded)), result.Checksum)
+
+	for _, key := range []string{"backups/test.tar.gz", "backups/test.tar"} {
+		t.Run("pull "+key, func(t *testing.T) {
+			destDir := t.TempDir()
+			err := Pull(context.Background(), PullConfig{
+				Bucket: "test-bucket",
+				Key:    key,
+				Dest:   destDir,
+			}, &mockDownloader{data: uploaded})
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+			require.NoError(t, err)
+			assert.Equal(t, level, content)
+			content, err = os.ReadFile(filepath.Join(destDir, "world", "region", "r.0.0.mca"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("region data"), content)
+		})
+	}
+}
+
+func TestPull_CorruptGzip(t *testing.T) {
+	err := Pull(context.Background(), PullConfig{
+		Bucket: "test-bucket",
+		Key:    "backups/test.tar.gz",
+		Dest:   t.TempDir(),
+	}, &mockDownloader{data: []byte("not gzip at all")})
+	assert.Error(t, err)
+}
+
+func TestGzipCodec_RoundTrip(t *testing.T) {
+	var encoded bytes.Buffer
+	w, err := Gzip.NewWriter(&encoded)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := Gzip.NewReader(&encoded)
+	require.NoError(t, err)
+	defer r.Close()
+	decoded, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(decoded))
+}
@@ -0,0 +1,342 @@
+package streamer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Encryption error constants
+var (
+	ErrKeySize          = errors.New("encryption key must be 32 bytes")
+	ErrPassphraseEmpty  = errors.New("passphrase cannot be empty")
+	ErrKeyringEmpty     = errors.New("keyring needs at least one key")
+	ErrKeyNotFound      = errors.New("encryption key not available")
+	ErrDecryptFailed    = errors.New("archive decryption failed")
+	ErrEncryptedHeader  = errors.New("invalid encrypted archive header")
+	ErrEncryptedTrailer = errors.New("unexpected data after final encrypted chunk")
+)
+
+// Encrypted stream layout
+//
+//	header: magic | key ID length (1 byte) | key ID | nonce prefix (7 bytes)
+//	chunks: AES-256-GCM sealed plaintext of up to encryptChunkSize bytes
+//
+// Each chunk nonce is the prefix, a 4-byte big-endian counter and a final-chunk flag,
+// with the header as additional data. Reordered, dropped or truncated chunks fail to open
+const (
+	encryptChunkSize  = 64 * 1024
+	noncePrefixSize   = 7
+	passphraseRounds  = 600000
+	passphraseSaltTag = "ritual archive key"
+)
+
+// encryptMagic opens every encrypted archive stream
+var encryptMagic = []byte("RITENC\x00\x01")
+
+// Key is a 256-bit archive encryption key identified by its fingerprint
+type Key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewKey creates a key from 32 secret bytes
+// The ID is derived from the secret, so the same key always has the same ID
+func NewKey(secret []byte) (*Key, error) {
+	if len(secret) != 32 {
+		return nil, ErrKeySize
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	fingerprint := sha256.Sum256(secret)
+	return &Key{id: hex.EncodeToString(fingerprint[:8]), aead: aead}, nil
+}
+
+// KeyFromPassphrase derives a key from a passphrase shared by the group
+// The salt is fixed so every host derives the same key from the same passphrase
+func KeyFromPassphrase(passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseEmpty
+	}
+
+	secret, err := pbkdf2.Key(sha256.New, passphrase, []byte(passphraseSaltTag), passphraseRounds, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return NewKey(secret)
+}
+
+// LoadKeyFile reads a key file
+// A file holding 64 hex characters is a raw key; any other content is used as a passphrase
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if secret, err := hex.DecodeString(content); err == nil && len(secret) == 32 {
+		return NewKey(secret)
+	}
+	return KeyFromPassphrase(content)
+}
+
+// ID returns the key fingerprint recorded in archive headers and manifests
+func (k *Key) ID() string {
+	if k == nil {
+		return ""
+	}
+	return k.id
+}
+
+// Keyring holds the key used for new archives and older keys kept for decryption
+type Keyring struct {
+	current *Key
+	keys    map[string]*Key
+}
+
+// NewKeyring creates a keyring that encrypts with current and decrypts with any key
+func NewKeyring(current *Key, retired ...*Key) (*Keyring, error) {
+	if current == nil {
+		return nil, ErrKeyringEmpty
+	}
+
+	keys := map[string]*Key{current.id: current}
+	for _, key := range retired {
+		if key != nil {
+			keys[key.id] = key
+		}
+	}
+	return &Keyring{current: current, keys: keys}, nil
+}
+
+// Current returns the key used for new archives, nil for a nil keyring
+func (r *Keyring) Current() *Key {
+	if r == nil {
+		return nil
+	}
+	return r.current
+}
+
+// Key returns the key with the given ID
+func (r *Keyring) Key(id string) (*Key, error) {
+	if r != nil {
+		if key, ok := r.keys[id]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: archive was encrypted with key %s", ErrKeyNotFound, id)
+}
+
+// encryptWriter seals a stream into GCM chunks
+// The last buffered chunk is only sealed on Close so it can carry the final flag
+type encryptWriter struct {
+	w       io.Writer
+	key     *Key
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	closed  bool
+}
+
+// newEncryptWriter writes the stream header and returns a writer that encrypts into w
+// Close seals the final chunk without closing w
+func newEncryptWriter(w io.Writer, key *Key) (io.WriteCloser, error) {
+	if key == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, len(encryptMagic)+1+len(key.id)+noncePrefixSize)
+	header = append(header, encryptMagic...)
+	header = append(header, byte(len(key.id)))
+	header = append(header, key.id...)
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write encryption header: %w", err)
+	}
+
+	return &encryptWriter{
+		w:      w,
+		key:    key,
+		header: header,
+		prefix: prefix,
+		buf:    make([]byte, 0, encryptChunkSize),
+		out:    make([]byte, 0, encryptChunkSize+key.aead.Overhead()),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is sealed only once more data proves it is not the last chunk
+		if len(e.buf) == encryptChunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):encryptChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the final chunk
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	if e.counter == math.MaxUint32 {
+		return errors.New("encrypted stream too long")
+	}
+
+	e.out = e.key.aead.Seal(e.out[:0], chunkNonce(e.prefix, e.counter, last), e.buf, e.header)
+	if _, err := e.w.Write(e.out); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader opens GCM chunks written by encryptWriter
+type decryptReader struct {
+	r       *bufio.Reader
+	key     *Key
+	header  []byte
+	prefix  []byte
+	counter uint32
+	sealed  []byte
+	plain   []byte
+	pos     int
+	done    bool
+}
+
+// isEncrypted reports whether the stream starts with the encryption magic
+func isEncrypted(r *bufio.Reader) bool {
+	head, _ := r.Peek(len(encryptMagic))
+	return bytes.Equal(head, encryptMagic)
+}
+
+// newDecryptReader reads the stream header and returns a reader of the plaintext
+// The key is looked up in keys by the ID stored in the header
+func newDecryptReader(r *bufio.Reader, keys *Keyring) (io.Reader, string, error) {
+	fixed := make([]byte, len(encryptMagic)+1)
+	if _, err := io.ReadFull(r, fixed); err != nil || !bytes.Equal(fixed[:len(encryptMagic)], encryptMagic) {
+		return nil, "", ErrEncryptedHeader
+	}
+
+	rest := make([]byte, int(fixed[len(encryptMagic)])+noncePrefixSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, "", ErrEncryptedHeader
+	}
+	keyID := string(rest[:len(rest)-noncePrefixSize])
+
+	key, err := keys.Key(keyID)
+	if err != nil {
+		return nil, keyID, err
+	}
+
+	return &decryptReader{
+		r:      r,
+		key:    key,
+		header: append(fixed, rest...),
+		prefix: rest[len(rest)-noncePrefixSize:],
+		sealed: make([]byte, encryptChunkSize+key.aead.Overhead()),
+	}, keyID, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for d.pos == len(d.plain) {
+		if d.done {
+			if _, err := d.r.Peek(1); err != io.EOF {
+				return 0, ErrEncryptedTrailer
+			}
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain[d.pos:])
+	d.pos += n
+	return n, nil
+}
+
+// open reads and authenticates the next chunk
+// A short chunk, or a full one followed by EOF, must carry the final flag
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.sealed)
+	last := false
+	switch {
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err == io.EOF:
+		return fmt.Errorf("%w: stream ends before final chunk", ErrDecryptFailed)
+	case err != nil:
+		return err
+	default:
+		if _, peekErr := d.r.Peek(1); peekErr == io.EOF {
+			last = true
+		}
+	}
+
+	plain, err := d.key.aead.Open(d.plain[:0], chunkNonce(d.prefix, d.counter, last), d.sealed[:n], d.header)
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d failed authentication", ErrDecryptFailed, d.counter)
+	}
+	d.plain = plain
+	d.pos = 0
+	d.counter++
+	d.done = last
+	return nil
+}
+
+// chunkNonce builds the nonce for chunk counter
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, noncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
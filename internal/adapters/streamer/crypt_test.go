@@ -0,0 +1,210 @@
+package streamer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey creates a random key
+func testKey(t *testing.T) *Key {
+	t.Helper()
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	key, err := NewKey(secret)
+	require.NoError(t, err)
+	return key
+}
+
+// encryptBytes seals data with key
+func encryptBytes(t *testing.T, key *Key, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newEncryptWriter(&buf, key)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// decryptBytes opens sealed data with keys
+func decryptBytes(sealed []byte, keys *Keyring) ([]byte, error) {
+	r, _, err := newDecryptReader(bufio.NewReader(bytes.NewReader(sealed)), keys)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestNewKey(t *testing.T) {
+	_, err := NewKey([]byte("short"))
+	assert.ErrorIs(t, err, ErrKeySize)
+
+	secret := bytes.Repeat([]byte{7}, 32)
+	a, err := NewKey(secret)
+	require.NoError(t, err)
+	b, err := NewKey(secret)
+	require.NoError(t, err)
+	assert.Equal(t, a.ID(), b.ID())
+	assert.Len(t, a.ID(), 16)
+	assert.NotEqual(t, a.ID(), testKey(t).ID())
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	secret := bytes.Repeat([]byte{9}, 32)
+	hexPath := filepath.Join(dir, "hex.key")
+	require.NoError(t, os.WriteFile(hexPath, []byte(hex.EncodeToString(secret)+"\n"), 0600))
+	passPath := filepath.Join(dir, "pass.key")
+	require.NoError(t, os.WriteFile(passPath, []byte("correct horse battery staple\n"), 0600))
+
+	fromHex, err := LoadKeyFile(hexPath)
+	require.NoError(t, err)
+	expected, err := NewKey(secret)
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID(), fromHex.ID())
+
+	fromFile, err := LoadKeyFile(passPath)
+	require.NoError(t, err)
+	fromPassphrase, err := KeyFromPassphrase("correct horse battery staple")
+	require.NoError(t, err)
+	assert.Equal(t, fromPassphrase.ID(), fromFile.ID())
+
+	_, err = KeyFromPassphrase("")
+	assert.ErrorIs(t, err, ErrPassphraseEmpty)
+	_, err = LoadKeyFile(filepath.Join(dir, "missing.key"))
+	assert.Error(t, err)
+}
+
+func TestKeyring(t *testing.T) {
+	_, err := NewKeyring(nil)
+	assert.ErrorIs(t, err, ErrKeyringEmpty)
+
+	current, retired := testKey(t), testKey(t)
+	keys, err := NewKeyring(current, retired, nil)
+	require.NoError(t, err)
+	assert.Equal(t, current, keys.Current())
+
+	found, err := keys.Key(retired.ID())
+	require.NoError(t, err)
+	assert.Equal(t, retired, found)
+
+	_, err = keys.Key("unknown")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	var nilKeys *Keyring
+	assert.Nil(t, nilKeys.Current())
+	_, err = nilKeys.Key(current.ID())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestEncryptedStream_RoundTrip(t *testing.T) {
+	key := testKey(t)
+	keys, err := NewKeyring(key)
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, encryptChunkSize - 1, encryptChunkSize, encryptChunkSize + 1, 3 * encryptChunkSize} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		sealed := encryptBytes(t, key, data)
+		assert.True(t, isEncrypted(bufio.NewReader(bytes.NewReader(sealed))))
+
+		plain, err := decryptBytes(sealed, keys)
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, data, plain, "size %d", size)
+	}
+}
+
+func TestEncryptedStream_Tampering(t *testing.T) {
+	key := testKey(t)
+	keys, err := NewKeyring(key)
+	require.NoError(t, err)
+	data := bytes.Repeat([]byte("world data "), encryptChunkSize/4)
+	sealed := encryptBytes(t, key, data)
+	headerLen := len(encryptMagic) + 1 + len(key.ID()) + noncePrefixSize
+	fullChunk := encryptChunkSize + key.aead.Overhead()
+
+	t.Run("flipped byte fails", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		tampered[headerLen+10] ^= 0xff
+		_, err := decryptBytes(tampered, keys)
+		assert.ErrorIs(t, err, ErrDecryptFailed)
+	})
+
+	t.Run("dropped final chunk fails", func(t *testing.T) {
+		_, err := decryptBytes(sealed[:headerLen+fullChunk], keys)
+		assert.ErrorIs(t, err, ErrDecryptFailed)
+	})
+
+	t.Run("trailing data fails", func(t *testing.T) {
+		_, err := decryptBytes(append(bytes.Clone(sealed), 0), keys)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown key fails", func(t *testing.T) {
+		other, err := NewKeyring(testKey(t))
+		require.NoError(t, err)
+		_, err = decryptBytes(sealed, other)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
+
+func TestPushPull_Encrypted(t *testing.T) {
+	worldDir := filepath.Join(t.TempDir(), "world")
+	require.NoError(t, os.MkdirAll(worldDir, 0755))
+	level := bytes.Repeat([]byte("level data "), 10000)
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "level.dat"), level, 0644))
+
+	oldKey, newKey := testKey(t), testKey(t)
+	buf := &bytes.Buffer{}
+	result, err := Push(context.Background(), PushConfig{
+		Bucket:     "test-bucket",
+		Key:        "backups/test.tar.gz",
+		Dirs:       []string{worldDir},
+		Codec:      Gzip,
+		EncryptKey: oldKey,
+	}, &mockUploader{buf: buf})
+	require.NoError(t, err)
+	assert.Equal(t, oldKey.ID(), result.KeyID)
+	assert.NotContains(t, buf.String(), "level data")
+
+	pull := func(keys *Keyring) (string, error) {
+		destDir := t.TempDir()
+		err := Pull(context.Background(), PullConfig{
+			Bucket:   "test-bucket",
+			Key:      "backups/test.tar.gz",
+			Dest:     destDir,
+			Checksum: result.Checksum,
+			Keys:     keys,
+		}, &mockDownloader{data: buf.Bytes()})
+		return destDir, err
+	}
+
+	t.Run("rotated keyring still opens old backups", func(t *testing.T) {
+		keys, err := NewKeyring(newKey, oldKey)
+		require.NoError(t, err)
+		destDir, err := pull(keys)
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, level, content)
+	})
+
+	t.Run("missing key is rejected", func(t *testing.T) {
+		_, err := pull(nil)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
@@ -2,6 +2,8 @@ package streamer
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -24,7 +26,11 @@ var (
 // errSkipFile is a sentinel error for skipping files
 var errSkipFile = errors.New("skip file")
 
-// Pull downloads and extracts a tar archive from R2
+// Pull downloads and extracts a tar archive from R2, decompressing it if a codec is detected
+// A key ending in IndexExtension is rebuilt from its content-addressed chunks instead
+// With cfg.Staged the existing top-level entries are only replaced once extraction succeeded
+// An expected size or checksum is verified while the archive is extracted into a staging directory,
+// so dest is only changed by an archive that matched
 func Pull(ctx context.Context, cfg PullConfig, downloader S3StreamDownloader) error {
 	if ctx == nil {
 		return ErrPullContextNil
@@ -53,6 +59,19 @@ func Pull(ctx context.Context, cfg PullConfig, downloader S3StreamDownloader) er
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
+	if cfg.Staged {
+		return pullStaged(ctx, cfg, destAbs, downloader)
+	}
+	if cfg.Size > 0 || cfg.Checksum != "" {
+		return pullVerified(ctx, cfg, destAbs, downloader)
+	}
+	return extract(ctx, cfg, destAbs, downloader)
+}
+
+// extract downloads the archive and extracts it into destAbs
+// An expected size or checksum is checked once the whole archive was read; files already
+// extracted are left in place on a mismatch, so callers extract into a staging directory
+func extract(ctx context.Context, cfg PullConfig, destAbs string, downloader S3StreamDownloader) error {
 	// Download from R2
 	body, err := downloader.Download(ctx, cfg.Bucket, cfg.Key)
 	if err != nil {
@@ -60,8 +79,62 @@ func Pull(ctx context.Context, cfg PullConfig, downloader S3StreamDownloader) er
 	}
 	defer body.Close()
 
-	// Create tar reader directly (no gzip)
-	tarReader := tar.NewReader(body)
+	digest := newDigestReader(body)
+	if IsChunkIndex(cfg.Key) {
+		// The index is small; it is read in full and checked before any chunk is fetched
+		data, err := io.ReadAll(digest)
+		if err != nil {
+			return fmt.Errorf("failed to read chunk index: %w", err)
+		}
+		if err := digest.verify(cfg.Key, cfg.Size, cfg.Checksum); err != nil {
+			return err
+		}
+		return pullIndex(ctx, cfg, destAbs, bytes.NewReader(data), downloader)
+	}
+
+	err = extractArchive(ctx, cfg, destAbs, digest)
+	if cfg.Size <= 0 && cfg.Checksum == "" {
+		return err
+	}
+
+	// Hash what the extractor left unread; a corrupt download usually fails extraction first
+	// and is reported as the mismatch it is
+	if _, drainErr := copyWithContext(ctx, io.Discard, digest); drainErr != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to download %s: %w", cfg.Key, drainErr)
+	}
+	if verifyErr := digest.verify(cfg.Key, cfg.Size, cfg.Checksum); verifyErr != nil {
+		return verifyErr
+	}
+	return err
+}
+
+// extractArchive decodes the tar archive read from r into destAbs
+func extractArchive(ctx context.Context, cfg PullConfig, destAbs string, r io.Reader) error {
+	// Encrypted archives name their key in the header; decrypt before detecting the codec
+	buffered := bufio.NewReader(r)
+	if isEncrypted(buffered) {
+		plain, _, err := newDecryptReader(buffered, cfg.Keys)
+		if err != nil {
+			return err
+		}
+		buffered = bufio.NewReader(plain)
+	}
+
+	// Pick the decoder from the key extension or the stream's magic bytes
+	codec, err := detectCodec(cfg.Key, buffered)
+	if err != nil {
+		return err
+	}
+	decoder, err := codec.NewReader(buffered)
+	if err != nil {
+		return fmt.Errorf("failed to create %s decoder: %w", codec.Name(), err)
+	}
+	defer decoder.Close()
+
+	tarReader := tar.NewReader(decoder)
 
 	// Extract files sequentially (tar.Reader is inherently sequential)
 	for {
@@ -107,7 +180,7 @@ func Pull(ctx context.Context, cfg PullConfig, downloader S3StreamDownloader) er
 				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
 			}
 		case tar.TypeReg:
-			if err := extractFile(targetPath, tarReader, header); err != nil {
+			if err := extractFile(ctx, targetPath, tarReader, header); err != nil {
 				return fmt.Errorf("failed to extract file %s: %w", targetPath, err)
 			}
 		}
@@ -181,7 +254,10 @@ func handleConflict(path string, header *tar.Header, strategy ConflictStrategy)
 }
 
 // extractFile extracts a single file from tar to disk
-func extractFile(path string, reader io.Reader, header *tar.Header) error {
+func extractFile(ctx context.Context, path string, reader io.Reader, header *tar.Header) error {
+	if ctx == nil {
+		return ErrPullContextNil
+	}
 	if reader == nil {
 		return errors.New("reader cannot be nil")
 	}
@@ -202,8 +278,8 @@ func extractFile(path string, reader io.Reader, header *tar.Header) error {
 	}
 	defer file.Close()
 
-	// Copy content
-	if _, err := io.Copy(file, reader); err != nil {
+	// Copy content, aborting mid-file on cancellation
+	if _, err := copyWithContext(ctx, file, reader); err != nil {
 		os.Remove(path) // Cleanup partial file
 		return err
 	}
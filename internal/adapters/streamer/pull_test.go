@@ -381,6 +381,58 @@ func TestPull_ContextCancellation(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// cancelAfterReader cancels a context once limit bytes have been read
+type cancelAfterReader struct {
+	r      io.Reader
+	limit  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfterReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	if c.read >= c.limit {
+		c.cancel()
+	}
+	return n, err
+}
+
+// cancellingDownloader serves data through a cancelAfterReader
+type cancellingDownloader struct {
+	data   []byte
+	limit  int
+	cancel context.CancelFunc
+}
+
+func (d *cancellingDownloader) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return io.NopCloser(&cancelAfterReader{r: bytes.NewReader(d.data), limit: d.limit, cancel: d.cancel}), nil
+}
+
+func TestPull_ContextCancellationMidFile(t *testing.T) {
+	archive := createTestArchive(t, map[string][]byte{
+		"world/region.mca": bytes.Repeat([]byte("x"), 4*1024*1024),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tempDir := t.TempDir()
+	downloader := &cancellingDownloader{data: archive, limit: 64 * 1024, cancel: cancel}
+	cfg := PullConfig{
+		Bucket: "test-bucket",
+		Key:    "test.tar",
+		Dest:   tempDir,
+	}
+
+	err := Pull(ctx, cfg, downloader)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Partial file is removed
+	_, statErr := os.Stat(filepath.Join(tempDir, "world", "region.mca"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
 func TestPull_DownloadFailure(t *testing.T) {
 	tempDir := t.TempDir()
 	downloader := &mockDownloader{downloadErr: assert.AnError}
@@ -42,7 +42,7 @@ func calculateDirSize(dirs []string) int64 {
 	return totalSize
 }
 
-// Push streams directories to R2 as tar, optionally saving local copy
+// Push streams directories to R2 as tar, compressed with cfg.Codec, optionally saving local copy
 func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Result, error) {
 	if ctx == nil {
 		return Result{}, ErrPushContextNil
@@ -60,9 +60,20 @@ func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Resul
 		return Result{}, ErrPushUploaderNil
 	}
 
-	// Calculate estimated size for progress reporting (no compression, so ~100% of source)
+	codec := cfg.Codec
+	if codec == nil {
+		codec = NoCompression
+	}
+
+	// Calculate estimated size for progress reporting (archive progress counts uncompressed bytes)
 	estimatedSize := calculateDirSize(cfg.Dirs)
 
+	// Compressed size is unknown up front, so upload progress omits the percentage
+	uploadEstimate := estimatedSize
+	if codec != NoCompression {
+		uploadEstimate = 0
+	}
+
 	// Evaluate local backup condition BEFORE streaming starts
 	doLocalBackup := cfg.LocalPath != "" && (cfg.ShouldBackup == nil || cfg.ShouldBackup())
 
@@ -80,10 +91,10 @@ func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Resul
 	var bytesWritten int64
 	countWriter := &countingWriter{w: hashWriter, n: &bytesWritten}
 
-	// Progress writer wraps countWriter to emit archive progress events
-	var tarWriter io.Writer = countWriter
+	// Progress writer sits in front of the codec to report uncompressed archive progress
+	var progress *progressWriter
 	if cfg.Events != nil {
-		tarWriter = newProgressWriter(countWriter, estimatedSize, cfg.Events)
+		progress = newProgressWriter(nil, estimatedSize, cfg.Events)
 	}
 
 	var producerErr error
@@ -101,7 +112,7 @@ func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Resul
 			}
 		}()
 
-		producerErr = runProducer(ctx, cfg.Dirs, tarWriter, pipeWriter)
+		producerErr = runProducer(ctx, cfg.Dirs, codec, cfg.EncryptKey, progress, countWriter, pipeWriter)
 		if producerErr != nil {
 			pipeWriter.CloseWithError(producerErr)
 		}
@@ -112,7 +123,7 @@ func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Resul
 	go func() {
 		defer wg.Done()
 		var localPath string
-		localPath, consumerErr = runConsumer(ctx, cfg, pipeReader, uploader, doLocalBackup, estimatedSize)
+		localPath, consumerErr = runConsumer(ctx, cfg, pipeReader, uploader, doLocalBackup, uploadEstimate)
 		if consumerErr == nil {
 			result.LocalPath = localPath
 		}
@@ -131,27 +142,55 @@ func Push(ctx context.Context, cfg PushConfig, uploader S3StreamUploader) (Resul
 
 	result.Size = bytesWritten
 	result.Checksum = fmt.Sprintf("%x", hashWriter.Sum(nil))
+	result.KeyID = cfg.EncryptKey.ID()
 
 	return result, nil
 }
 
 // runProducer handles the producer goroutine logic
-func runProducer(ctx context.Context, dirs []string, countWriter io.Writer, pipeWriter *io.PipeWriter) error {
+// progress and key may be nil; countWriter sees the encoded bytes that are uploaded
+func runProducer(ctx context.Context, dirs []string, codec Codec, key *Key, progress *progressWriter, countWriter io.Writer, pipeWriter *io.PipeWriter) error {
 	if ctx == nil {
 		return ErrPushContextNil
 	}
+	if codec == nil {
+		return ErrCodecNil
+	}
 	if pipeWriter == nil {
 		return errors.New("pipeWriter cannot be nil")
 	}
 
-	// Chain: tarWriter -> multiWriter(countWriter, pipeWriter)
+	// Chain: tarWriter -> [progress] -> codec -> [encrypt] -> multiWriter(countWriter, pipeWriter)
+	// Compression runs before encryption; ciphertext does not compress
 	multiW := io.MultiWriter(countWriter, pipeWriter)
-	tarWriter := tar.NewWriter(multiW)
+	sealer := io.WriteCloser(nopWriteCloser{multiW})
+	if key != nil {
+		var err error
+		sealer, err = newEncryptWriter(multiW, key)
+		if err != nil {
+			pipeWriter.Close()
+			return err
+		}
+	}
+
+	encoder, err := codec.NewWriter(sealer)
+	if err != nil {
+		pipeWriter.Close()
+		return fmt.Errorf("failed to create %s encoder: %w", codec.Name(), err)
+	}
+
+	var archiveW io.Writer = encoder
+	if progress != nil {
+		progress.w = encoder
+		archiveW = progress
+	}
+	tarWriter := tar.NewWriter(archiveW)
 
 	// Walk all directories and write to tar
 	for _, dir := range dirs {
 		if err := addDirToTar(ctx, tarWriter, dir); err != nil {
 			tarWriter.Close()
+			encoder.Close()
 			pipeWriter.Close()
 			return fmt.Errorf("failed to add %s to tar: %w", dir, err)
 		}
@@ -159,10 +198,21 @@ func runProducer(ctx context.Context, dirs []string, countWriter io.Writer, pipe
 
 	// Close tar writer
 	if err := tarWriter.Close(); err != nil {
+		encoder.Close()
 		pipeWriter.Close()
 		return fmt.Errorf("failed to close tar writer: %w", err)
 	}
 
+	// Flush the codec trailer and seal the final chunk before signalling EOF
+	if err := encoder.Close(); err != nil {
+		pipeWriter.Close()
+		return fmt.Errorf("failed to close %s encoder: %w", codec.Name(), err)
+	}
+	if err := sealer.Close(); err != nil {
+		pipeWriter.Close()
+		return fmt.Errorf("failed to seal encrypted archive: %w", err)
+	}
+
 	pipeWriter.Close()
 	return nil
 }
@@ -270,7 +320,8 @@ func addDirToTar(ctx context.Context, tw *tar.Writer, root string) error {
 			}
 			defer file.Close()
 
-			if _, err := io.Copy(tw, file); err != nil {
+			// Large files are copied in chunks so cancellation is not delayed
+			if _, err := copyWithContext(ctx, tw, file); err != nil {
 				return err
 			}
 		}
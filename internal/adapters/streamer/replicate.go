@@ -0,0 +1,140 @@
+package streamer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"ritual/internal/core/ports"
+)
+
+// Replicate error constants
+var (
+	ErrReplicateContextNil   = errors.New("context cannot be nil")
+	ErrReplicateKeyEmpty     = errors.New("key cannot be empty")
+	ErrReplicateSourceNil    = errors.New("source downloader cannot be nil")
+	ErrReplicateUploaderNil  = errors.New("target uploader cannot be nil")
+	ErrReplicateVerifierNil  = errors.New("target downloader cannot be nil")
+	ErrReplicateExpectedNone = errors.New("size or checksum must be set")
+)
+
+// ReplicateConfig configures copying a stored backup to another storage target
+type ReplicateConfig struct {
+	SourceBucket string             // Bucket holding the verified backup
+	Bucket       string             // Target bucket
+	Key          string             // Object key, identical on source and target
+	Size         int64              // Expected object size in bytes. 0 = not checked
+	Checksum     string             // Expected SHA-256 hex checksum. Empty = not checked
+	Events       chan<- ports.Event // Optional: channel for progress events
+}
+
+// Replicate copies a backup object from source to a target and verifies the target copy
+// A chunk index is copied after every chunk it references; chunks the target lister
+// already reports are skipped. lister is optional, nil copies every chunk
+func Replicate(
+	ctx context.Context,
+	cfg ReplicateConfig,
+	source S3StreamDownloader,
+	uploader S3StreamUploader,
+	downloader S3StreamDownloader,
+	lister S3StreamLister,
+) error {
+	if ctx == nil {
+		return ErrReplicateContextNil
+	}
+	if cfg.Key == "" {
+		return ErrReplicateKeyEmpty
+	}
+	if cfg.Size <= 0 && cfg.Checksum == "" {
+		return ErrReplicateExpectedNone
+	}
+	if source == nil {
+		return ErrReplicateSourceNil
+	}
+	if uploader == nil {
+		return ErrReplicateUploaderNil
+	}
+	if downloader == nil {
+		return ErrReplicateVerifierNil
+	}
+
+	if IsChunkIndex(cfg.Key) {
+		if err := replicateIndex(ctx, cfg, source, uploader, lister); err != nil {
+			return err
+		}
+	} else if err := copyObject(ctx, cfg, cfg.Key, cfg.Size, source, uploader); err != nil {
+		return err
+	}
+
+	return Verify(ctx, VerifyConfig{Bucket: cfg.Bucket, Key: cfg.Key, Size: cfg.Size, Checksum: cfg.Checksum}, downloader)
+}
+
+// replicateIndex copies the chunks an index references, then the index itself
+// The index is checked against the expected digest before any chunk is copied
+func replicateIndex(ctx context.Context, cfg ReplicateConfig, source S3StreamDownloader, uploader S3StreamUploader, lister S3StreamLister) error {
+	body, err := source.Download(ctx, cfg.SourceBucket, cfg.Key)
+	if err != nil {
+		return fmt.Errorf("failed to download chunk index: %w", err)
+	}
+	digest := newDigestReader(body)
+	data, err := io.ReadAll(digest)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read chunk index: %w", err)
+	}
+	if err := digest.verify(cfg.Key, cfg.Size, cfg.Checksum); err != nil {
+		return err
+	}
+	index, err := ParseChunkIndex(data)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string]bool)
+	if lister != nil {
+		keys, err := lister.List(ctx, ChunkPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to list target chunks: %w", err)
+		}
+		for _, key := range keys {
+			stored[key] = true
+		}
+	}
+
+	copied := 0
+	for _, key := range index.ChunkKeys() {
+		if stored[key] {
+			continue
+		}
+		if err := copyObject(ctx, cfg, key, 0, source, uploader); err != nil {
+			return err
+		}
+		copied++
+	}
+	ports.SendEvent(cfg.Events, ports.UpdateEvent{Operation: "replicate", Message: "Chunks replicated", Data: map[string]any{
+		"key":    cfg.Key,
+		"copied": copied,
+		"reused": len(index.ChunkKeys()) - copied,
+	}})
+
+	if _, err := uploader.Upload(ctx, cfg.Bucket, cfg.Key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to upload chunk index: %w", err)
+	}
+	return nil
+}
+
+// copyObject streams one object from source to the target
+func copyObject(ctx context.Context, cfg ReplicateConfig, key string, size int64, source S3StreamDownloader, uploader S3StreamUploader) error {
+	body, err := source.Download(ctx, cfg.SourceBucket, key)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer body.Close()
+
+	if _, err := uploader.Upload(ctx, cfg.Bucket, key, body, size); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package streamer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicate_Validation(t *testing.T) {
+	store := newMockChunkStore()
+	ctx := context.Background()
+	cfg := ReplicateConfig{Key: "worlds/1.tar", Size: 10}
+
+	assert.ErrorIs(t, Replicate(nil, cfg, store, store, store, nil), ErrReplicateContextNil)
+	assert.ErrorIs(t, Replicate(ctx, ReplicateConfig{Size: 10}, store, store, store, nil), ErrReplicateKeyEmpty)
+	assert.ErrorIs(t, Replicate(ctx, ReplicateConfig{Key: "worlds/1.tar"}, store, store, store, nil), ErrReplicateExpectedNone)
+	assert.ErrorIs(t, Replicate(ctx, cfg, nil, store, store, nil), ErrReplicateSourceNil)
+	assert.ErrorIs(t, Replicate(ctx, cfg, store, nil, store, nil), ErrReplicateUploaderNil)
+	assert.ErrorIs(t, Replicate(ctx, cfg, store, store, nil, nil), ErrReplicateVerifierNil)
+}
+
+func TestReplicate_Archive(t *testing.T) {
+	ctx := context.Background()
+	archive := createTestArchive(t, map[string][]byte{"world/level.dat": []byte("level")})
+	source := newMockChunkStore()
+	source.objects["worlds/1.tar"] = archive
+
+	t.Run("copies and verifies", func(t *testing.T) {
+		target := newMockChunkStore()
+		cfg := ReplicateConfig{SourceBucket: "primary", Bucket: "replica", Key: "worlds/1.tar", Size: int64(len(archive)), Checksum: sha256Hex(archive)}
+		require.NoError(t, Replicate(ctx, cfg, source, target, target, target))
+		assert.Equal(t, archive, target.objects["worlds/1.tar"])
+	})
+
+	t.Run("source differing from the record fails verification", func(t *testing.T) {
+		target := newMockChunkStore()
+		cfg := ReplicateConfig{Key: "worlds/1.tar", Checksum: sha256Hex([]byte("other"))}
+		assert.ErrorIs(t, Replicate(ctx, cfg, source, target, target, target), ErrChecksumMismatch)
+	})
+
+	t.Run("missing source object", func(t *testing.T) {
+		target := newMockChunkStore()
+		cfg := ReplicateConfig{Key: "worlds/2.tar", Size: 10}
+		assert.Error(t, Replicate(ctx, cfg, source, target, target, target))
+		assert.Empty(t, target.objects)
+	})
+}
+
+func TestReplicate_ChunkIndex(t *testing.T) {
+	ctx := context.Background()
+	worldDir, region := setupChunkWorld(t)
+	source := newMockChunkStore()
+
+	result, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "worlds/1.index.json", Dirs: []string{worldDir}, Codec: Gzip}, source, source)
+	require.NoError(t, err)
+
+	target := newMockChunkStore()
+	// A chunk the target already holds is not copied again
+	index, err := ParseChunkIndex(source.objects["worlds/1.index.json"])
+	require.NoError(t, err)
+	existing := index.ChunkKeys()[0]
+	target.objects[existing] = source.objects[existing]
+
+	cfg := ReplicateConfig{Key: result.Key, Size: result.Size, Checksum: result.Checksum}
+	require.NoError(t, Replicate(ctx, cfg, source, target, target, target))
+
+	assert.Equal(t, len(index.ChunkKeys())-1, target.chunkUploads())
+	assert.NotContains(t, target.uploads, existing)
+	assert.Equal(t, "worlds/1.index.json", target.uploads[len(target.uploads)-1], "index is written after its chunks")
+
+	// The replica rebuilds the world on its own
+	destDir := t.TempDir()
+	require.NoError(t, Pull(ctx, PullConfig{Bucket: "b", Key: result.Key, Dest: destDir, Checksum: result.Checksum}, target))
+	content, err := os.ReadFile(filepath.Join(destDir, "world", "region", "r.0.0.mca"))
+	require.NoError(t, err)
+	assert.Equal(t, region, content)
+}
@@ -0,0 +1,241 @@
+package streamer
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Staged extraction error constants
+var (
+	ErrStagedEmpty = errors.New("staged archive is empty")
+)
+
+// Prefixes of the staged extraction directories inside the destination
+// They are hidden so backups and world scans never pick them up
+const (
+	stagingPrefix  = ".staging-"  // archive is extracted here first
+	previousPrefix = ".previous-" // replaced entries wait here until the swap succeeds
+	discardPrefix  = ".discard-"  // replaced entries after a successful swap, being deleted
+)
+
+// pullStaged extracts the archive into a staging directory inside destAbs and then swaps
+// its top-level entries in with renames. Replaced entries are moved aside first and put
+// back if extraction or any rename fails, so dest never holds a half-extracted archive
+func pullStaged(ctx context.Context, cfg PullConfig, destAbs string, downloader S3StreamDownloader) error {
+	if err := recoverStaged(destAbs); err != nil {
+		return err
+	}
+
+	staging, err := os.MkdirTemp(destAbs, stagingPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	inner := cfg
+	inner.Dest = staging
+	inner.Conflict = Replace
+	inner.Staged = false
+	if err := extract(ctx, inner, staging, downloader); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return fmt.Errorf("failed to read staging directory: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("%w: %s", ErrStagedEmpty, cfg.Key)
+	}
+
+	// Last point at which cancellation leaves dest untouched
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return swapIn(destAbs, staging, names)
+}
+
+// pullVerified extracts the archive into a staging directory inside destAbs while verifying it,
+// then moves the extracted files into destAbs under cfg.Conflict
+// A download that does not match is discarded with the staging directory
+func pullVerified(ctx context.Context, cfg PullConfig, destAbs string, downloader S3StreamDownloader) error {
+	if err := recoverStaged(destAbs); err != nil {
+		return err
+	}
+
+	staging, err := os.MkdirTemp(destAbs, stagingPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	inner := cfg
+	inner.Dest = staging
+	inner.Conflict = Replace
+	if err := extract(ctx, inner, staging, downloader); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	return mergeIn(destAbs, staging, cfg.Conflict)
+}
+
+// mergeIn moves every file extracted into staging to the same path in destAbs
+// Existing files are handled by strategy as they would be by a direct extraction
+func mergeIn(destAbs, staging string, strategy ConflictStrategy) error {
+	return filepath.WalkDir(staging, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		target := filepath.Join(destAbs, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			return nil
+		}
+
+		header := &tar.Header{Name: filepath.ToSlash(rel), Mode: int64(info.Mode().Perm()), Typeflag: tar.TypeReg}
+		err = handleConflict(target, header, strategy)
+		if err == errSkipFile {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(path, target); err != nil {
+			return fmt.Errorf("failed to move %s into place: %w", target, err)
+		}
+		return nil
+	})
+}
+
+// swapIn renames each staged entry into destAbs, moving any existing entry aside first
+// On failure every entry swapped so far is rolled back
+func swapIn(destAbs, staging string, names []string) error {
+	previous, err := os.MkdirTemp(destAbs, previousPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create directory for replaced entries: %w", err)
+	}
+
+	// An entry counts as swapped once its original is out of the way
+	var swapped []string
+	for _, name := range names {
+		target := filepath.Join(destAbs, name)
+		if _, err = os.Lstat(target); err == nil {
+			if err = os.Rename(target, filepath.Join(previous, name)); err != nil {
+				err = fmt.Errorf("failed to move %s aside: %w", name, err)
+				break
+			}
+		} else if !os.IsNotExist(err) {
+			err = fmt.Errorf("failed to stat %s: %w", name, err)
+			break
+		}
+		swapped = append(swapped, name)
+
+		if err = os.Rename(filepath.Join(staging, name), target); err != nil {
+			err = fmt.Errorf("failed to move staged %s into place: %w", name, err)
+			break
+		}
+	}
+
+	if err != nil {
+		if rollbackErr := rollbackSwap(destAbs, previous, swapped); rollbackErr != nil {
+			return fmt.Errorf("failed to swap in staged archive: %w; rollback failed, replaced entries are kept in %s: %w", err, previous, rollbackErr)
+		}
+		return fmt.Errorf("failed to swap in staged archive: %w", err)
+	}
+
+	// Rename before deleting so an interrupted delete is never mistaken for an interrupted swap
+	discard := filepath.Join(destAbs, discardPrefix+strings.TrimPrefix(filepath.Base(previous), previousPrefix))
+	if err := os.Rename(previous, discard); err != nil {
+		return fmt.Errorf("failed to retire replaced entries: %w", err)
+	}
+	if err := os.RemoveAll(discard); err != nil {
+		return fmt.Errorf("failed to remove replaced entries: %w", err)
+	}
+	return nil
+}
+
+// rollbackSwap removes the swapped-in entries and restores the replaced ones from previous
+func rollbackSwap(destAbs, previous string, swapped []string) error {
+	var errs []error
+	for i := len(swapped) - 1; i >= 0; i-- {
+		name := swapped[i]
+		target := filepath.Join(destAbs, name)
+		saved := filepath.Join(previous, name)
+
+		if err := os.RemoveAll(target); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := os.Lstat(saved); os.IsNotExist(err) {
+			continue // entry is new in this archive
+		}
+		if err := os.Rename(saved, target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return os.RemoveAll(previous)
+}
+
+// recoverStaged cleans up after a staged pull that was interrupted
+// An unfinished swap is rolled back in full, so dest again holds the previous archive
+func recoverStaged(destAbs string) error {
+	for _, prefix := range []string{stagingPrefix, discardPrefix} {
+		stale, _ := filepath.Glob(filepath.Join(destAbs, prefix+"*"))
+		for _, dir := range stale {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("failed to remove stale %s: %w", dir, err)
+			}
+		}
+	}
+
+	interrupted, _ := filepath.Glob(filepath.Join(destAbs, previousPrefix+"*"))
+	for _, previous := range interrupted {
+		entries, err := os.ReadDir(previous)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", previous, err)
+		}
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		if err := rollbackSwap(destAbs, previous, names); err != nil {
+			return fmt.Errorf("failed to recover interrupted swap from %s: %w", previous, err)
+		}
+	}
+	return nil
+}
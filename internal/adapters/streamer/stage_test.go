@@ -0,0 +1,137 @@
+package streamer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTestFile returns the content of a slash path under root
+func readTestFile(t *testing.T, root, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// assertNoStagingLeft checks that no staging, previous or discard directory remains in dest
+func assertNoStagingLeft(t *testing.T, dest string) {
+	t.Helper()
+	for _, prefix := range []string{stagingPrefix, previousPrefix, discardPrefix} {
+		leftover, err := filepath.Glob(filepath.Join(dest, prefix+"*"))
+		require.NoError(t, err)
+		assert.Empty(t, leftover, "%s directories should be removed", prefix)
+	}
+}
+
+func TestPull_StagedReplacesEntries(t *testing.T) {
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{
+		"world/level.dat":   "old level",
+		"world/stale.dat":   "only in the old world",
+		"server.properties": "motd=kept",
+	})
+
+	archive := createTestArchive(t, map[string][]byte{
+		"world/level.dat":        []byte("new level"),
+		"world_nether/level.dat": []byte("new nether"),
+	})
+	cfg := PullConfig{Bucket: "b", Key: "worlds/1.tar", Dest: dest, Staged: true, Checksum: sha256Hex(archive)}
+	require.NoError(t, Pull(context.Background(), cfg, &mockDownloader{data: archive}))
+
+	assert.Equal(t, "new level", readTestFile(t, dest, "world/level.dat"))
+	assert.Equal(t, "new nether", readTestFile(t, dest, "world_nether/level.dat"))
+	assert.Equal(t, "motd=kept", readTestFile(t, dest, "server.properties"))
+
+	// The world is replaced as a whole, not merged
+	_, err := os.Stat(filepath.Join(dest, "world", "stale.dat"))
+	assert.True(t, os.IsNotExist(err))
+	assertNoStagingLeft(t, dest)
+}
+
+func TestPull_StagedFailureKeepsEntries(t *testing.T) {
+	archive := createTestArchive(t, map[string][]byte{
+		"world/region.mca": bytes.Repeat([]byte("x"), 4*1024*1024),
+	})
+
+	t.Run("interrupted download", func(t *testing.T) {
+		dest := t.TempDir()
+		writeFiles(t, dest, map[string]string{"world/region.mca": "old region"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		downloader := &cancellingDownloader{data: archive, limit: 64 * 1024, cancel: cancel}
+
+		err := Pull(ctx, PullConfig{Bucket: "b", Key: "worlds/1.tar", Dest: dest, Staged: true}, downloader)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "old region", readTestFile(t, dest, "world/region.mca"))
+		assertNoStagingLeft(t, dest)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		dest := t.TempDir()
+		writeFiles(t, dest, map[string]string{"world/region.mca": "old region"})
+
+		cfg := PullConfig{Bucket: "b", Key: "worlds/1.tar", Dest: dest, Staged: true, Checksum: sha256Hex([]byte("other"))}
+		err := Pull(context.Background(), cfg, &mockDownloader{data: archive})
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+		assert.Equal(t, "old region", readTestFile(t, dest, "world/region.mca"))
+		assertNoStagingLeft(t, dest)
+	})
+
+	t.Run("empty archive", func(t *testing.T) {
+		dest := t.TempDir()
+		writeFiles(t, dest, map[string]string{"world/region.mca": "old region"})
+
+		empty := createTestArchive(t, map[string][]byte{})
+		err := Pull(context.Background(), PullConfig{Bucket: "b", Key: "worlds/1.tar", Dest: dest, Staged: true}, &mockDownloader{data: empty})
+		assert.ErrorIs(t, err, ErrStagedEmpty)
+		assert.Equal(t, "old region", readTestFile(t, dest, "world/region.mca"))
+	})
+}
+
+func TestSwapIn_RollsBackOnFailure(t *testing.T) {
+	dest := t.TempDir()
+	staging := filepath.Join(dest, stagingPrefix+"test")
+	writeFiles(t, dest, map[string]string{
+		"world/level.dat":                              "old level",
+		stagingPrefix + "test/world/level.dat":         "new level",
+		stagingPrefix + "test/world_the_end/level.dat": "new end",
+	})
+
+	// The second entry was never staged, so its rename fails after world was swapped in
+	err := swapIn(dest, staging, []string{"world", "world_nether", "world_the_end"})
+	require.Error(t, err)
+
+	assert.Equal(t, "old level", readTestFile(t, dest, "world/level.dat"))
+	for _, name := range []string{"world_nether", "world_the_end"} {
+		_, err := os.Stat(filepath.Join(dest, name))
+		assert.True(t, os.IsNotExist(err), "%s should not be left behind", name)
+	}
+	leftover, err := filepath.Glob(filepath.Join(dest, previousPrefix+"*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftover)
+}
+
+func TestRecoverStaged(t *testing.T) {
+	dest := t.TempDir()
+
+	// A swap interrupted after world was replaced but before it finished
+	writeFiles(t, dest, map[string]string{
+		"world/level.dat":                      "new level",
+		previousPrefix + "1/world/level.dat":   "old level",
+		stagingPrefix + "1/world_nether/x.dat": "staged",
+		discardPrefix + "2/world/level.dat":    "older level",
+		"server.properties":                    "motd=kept",
+	})
+
+	require.NoError(t, recoverStaged(dest))
+	assert.Equal(t, "old level", readTestFile(t, dest, "world/level.dat"))
+	assert.Equal(t, "motd=kept", readTestFile(t, dest, "server.properties"))
+	assertNoStagingLeft(t, dest)
+}
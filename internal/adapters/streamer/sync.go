@@ -0,0 +1,264 @@
+package streamer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sync error constants
+var (
+	ErrSyncContextNil    = errors.New("context cannot be nil")
+	ErrSyncKeyEmpty      = errors.New("key cannot be empty")
+	ErrSyncDestEmpty     = errors.New("dest cannot be empty")
+	ErrSyncDownloaderNil = errors.New("downloader cannot be nil")
+)
+
+// SyncConfig configures the Sync operation
+type SyncConfig struct {
+	Bucket    string                 // R2 bucket name
+	Key       string                 // R2 object key of the chunk index
+	Dest      string                 // Directory the index paths are relative to
+	Size      int64                  // Optional: expected index size in bytes. 0 = not checked
+	Checksum  string                 // Optional: expected index SHA-256 hex checksum. Empty = not checked
+	Keys      *Keyring               // Optional: keys for encrypted chunks
+	Protected func(path string) bool // Optional: index-style paths that are never replaced or deleted
+}
+
+// SyncResult contains Sync operation results
+type SyncResult struct {
+	Fetched   int // Files downloaded because they were missing or changed
+	Unchanged int // Files whose local size and hash already matched
+	Deleted   int // Local files and directories removed because the index no longer lists them
+}
+
+// Sync makes the index's top-level directories under cfg.Dest match a chunk index
+// Only files whose size or hash differ are downloaded; each is written to a temporary
+// file and renamed into place. Files the index does not list are deleted, except protected ones
+func Sync(ctx context.Context, cfg SyncConfig, downloader S3StreamDownloader) (SyncResult, error) {
+	if ctx == nil {
+		return SyncResult{}, ErrSyncContextNil
+	}
+	if cfg.Key == "" {
+		return SyncResult{}, ErrSyncKeyEmpty
+	}
+	if cfg.Dest == "" {
+		return SyncResult{}, ErrSyncDestEmpty
+	}
+	if downloader == nil {
+		return SyncResult{}, ErrSyncDownloaderNil
+	}
+
+	destAbs, err := filepath.Abs(cfg.Dest)
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("failed to get absolute path for dest: %w", err)
+	}
+
+	index, err := fetchIndex(ctx, cfg, downloader)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	protected := func(path string) bool {
+		return cfg.Protected != nil && cfg.Protected(path)
+	}
+	pullCfg := PullConfig{Bucket: cfg.Bucket, Key: cfg.Key, Keys: cfg.Keys}
+
+	var result SyncResult
+	listed := make(map[string]bool, len(index.Entries))
+	roots := make(map[string]bool)
+	for _, entry := range index.Entries {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		listed[entry.Path] = true
+		if !strings.Contains(entry.Path, "/") {
+			roots[entry.Path] = true
+		}
+		if protected(entry.Path) {
+			continue
+		}
+
+		targetPath := filepath.Join(destAbs, filepath.FromSlash(entry.Path))
+		if !isPathSafe(destAbs, targetPath) {
+			return result, fmt.Errorf("%w: %s", ErrPathTraversal, entry.Path)
+		}
+
+		if entry.Dir {
+			if err := os.MkdirAll(targetPath, os.FileMode(entry.Mode)); err != nil {
+				return result, fmt.Errorf("failed to create directory %s: %w", targetPath, err)
+			}
+			continue
+		}
+
+		if fileMatches(targetPath, entry) {
+			result.Unchanged++
+			continue
+		}
+		content := &chunkReader{ctx: ctx, cfg: pullCfg, keyID: index.KeyID, hashes: entry.Chunks, downloader: downloader}
+		if err := replaceFile(ctx, targetPath, content, entry); err != nil {
+			return result, fmt.Errorf("failed to update %s: %w", entry.Path, err)
+		}
+		result.Fetched++
+	}
+
+	deleted, err := removeUnlisted(ctx, destAbs, roots, listed, protected)
+	result.Deleted = deleted
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// fetchIndex downloads, verifies and parses a chunk index
+func fetchIndex(ctx context.Context, cfg SyncConfig, downloader S3StreamDownloader) (*ChunkIndex, error) {
+	body, err := downloader.Download(ctx, cfg.Bucket, cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("R2 download failed: %w", err)
+	}
+	defer body.Close()
+
+	digest := newDigestReader(body)
+	data, err := io.ReadAll(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk index: %w", err)
+	}
+	if err := digest.verify(cfg.Key, cfg.Size, cfg.Checksum); err != nil {
+		return nil, err
+	}
+	return ParseChunkIndex(data)
+}
+
+// fileMatches reports whether the file at path has the entry's size and hash
+// Entries without a hash never match, so they are always downloaded
+func fileMatches(path string, entry IndexEntry) bool {
+	if entry.Hash == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != entry.Size {
+		return false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return false
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == entry.Hash
+}
+
+// replaceFile writes content next to path and renames it into place
+// A failed download leaves the existing file untouched
+func replaceFile(ctx context.Context, path string, content io.Reader, entry IndexEntry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".sync-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	written, err := copyWithContext(ctx, io.MultiWriter(tmp, hash), content)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if written != entry.Size {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, entry.Path, written, entry.Size)
+	}
+	if entry.Hash != "" && hex.EncodeToString(hash.Sum(nil)) != entry.Hash {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, entry.Path)
+	}
+
+	if err := os.Chmod(tmp.Name(), os.FileMode(entry.Mode)); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// removeUnlisted deletes files and directories under roots that are not listed
+// Protected paths and everything below them are kept
+// Returns the number of removed entries
+func removeUnlisted(ctx context.Context, destAbs string, roots, listed map[string]bool, protected func(path string) bool) (int, error) {
+	var files, dirs []string
+	for root := range roots {
+		rootPath := filepath.Join(destAbs, filepath.FromSlash(root))
+		err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(destAbs, path)
+			if err != nil {
+				return err
+			}
+			slashPath := filepath.ToSlash(relPath)
+
+			if protected(slashPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if listed[slashPath] {
+				return nil
+			}
+			if info.IsDir() {
+				dirs = append(dirs, path)
+			} else {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+	}
+
+	removed := 0
+	for _, path := range files {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed++
+	}
+
+	// Deepest directories first; one still holding a protected path is kept
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, path := range dirs {
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
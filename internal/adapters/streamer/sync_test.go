@@ -0,0 +1,127 @@
+package streamer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files under root from a map of slash paths to content
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+}
+
+func TestSync_Validation(t *testing.T) {
+	store := newMockChunkStore()
+	ctx := context.Background()
+
+	_, err := Sync(nil, SyncConfig{Key: "k", Dest: "d"}, store)
+	assert.ErrorIs(t, err, ErrSyncContextNil)
+	_, err = Sync(ctx, SyncConfig{Dest: "d"}, store)
+	assert.ErrorIs(t, err, ErrSyncKeyEmpty)
+	_, err = Sync(ctx, SyncConfig{Key: "k"}, store)
+	assert.ErrorIs(t, err, ErrSyncDestEmpty)
+	_, err = Sync(ctx, SyncConfig{Key: "k", Dest: "d"}, nil)
+	assert.ErrorIs(t, err, ErrSyncDownloaderNil)
+}
+
+func TestSync_AppliesDelta(t *testing.T) {
+	ctx := context.Background()
+	store := newMockChunkStore()
+
+	// Published instance, without its world
+	published := filepath.Join(t.TempDir(), "instance")
+	writeFiles(t, published, map[string]string{
+		"paper.jar":              "paper v2",
+		"plugins/Essentials.jar": "essentials",
+		"plugins/NewPlugin.jar":  "new plugin",
+		"server.properties":      "motd=v2",
+		"world/level.dat":        "publisher world",
+	})
+	isWorld := func(path string) bool {
+		return path == "instance/world" || strings.HasPrefix(path, "instance/world/")
+	}
+	result, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "instance.index.json", Dirs: []string{published}, Exclude: isWorld}, store, store)
+	require.NoError(t, err)
+
+	// Local instance from the previous version
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{
+		"instance/paper.jar":              "paper v1",
+		"instance/plugins/Essentials.jar": "essentials",
+		"instance/plugins/OldPlugin.jar":  "old plugin",
+		"instance/plugins/Old/config.yml": "old config",
+		"instance/server.properties":      "motd=v2",
+		"instance/world/level.dat":        "local world",
+	})
+
+	synced, err := Sync(ctx, SyncConfig{
+		Bucket:    "b",
+		Key:       "instance.index.json",
+		Dest:      dest,
+		Checksum:  result.Checksum,
+		Protected: isWorld,
+	}, store)
+	require.NoError(t, err)
+	assert.Equal(t, 2, synced.Fetched)   // paper.jar changed, NewPlugin.jar added
+	assert.Equal(t, 2, synced.Unchanged) // Essentials.jar, server.properties
+	assert.Equal(t, 3, synced.Deleted)   // OldPlugin.jar, Old/config.yml, Old/
+
+	read := func(path string) string {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(path)))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "paper v2", read("instance/paper.jar"))
+	assert.Equal(t, "new plugin", read("instance/plugins/NewPlugin.jar"))
+	assert.Equal(t, "local world", read("instance/world/level.dat"))
+
+	for _, gone := range []string{"instance/plugins/OldPlugin.jar", "instance/plugins/Old"} {
+		_, err := os.Stat(filepath.Join(dest, filepath.FromSlash(gone)))
+		assert.True(t, os.IsNotExist(err), "%s should be removed", gone)
+	}
+
+	// A second sync has nothing to do
+	again, err := Sync(ctx, SyncConfig{Bucket: "b", Key: "instance.index.json", Dest: dest, Protected: isWorld}, store)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Unchanged: 4}, again)
+}
+
+func TestSync_FailedDownloadKeepsFile(t *testing.T) {
+	ctx := context.Background()
+	store := newMockChunkStore()
+
+	published := filepath.Join(t.TempDir(), "instance")
+	writeFiles(t, published, map[string]string{"paper.jar": "paper v2"})
+	_, err := PushChunks(ctx, PushConfig{Bucket: "b", Key: "instance.index.json", Dirs: []string{published}}, store, store)
+	require.NoError(t, err)
+
+	chunks, err := store.List(ctx, ChunkPrefix)
+	require.NoError(t, err)
+	for _, key := range chunks {
+		delete(store.objects, key)
+	}
+
+	dest := t.TempDir()
+	writeFiles(t, dest, map[string]string{"instance/paper.jar": "paper v1"})
+
+	_, err = Sync(ctx, SyncConfig{Bucket: "b", Key: "instance.index.json", Dest: dest}, store)
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dest, "instance", "paper.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "paper v1", string(data))
+	entries, err := os.ReadDir(filepath.Join(dest, "instance"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file should be cleaned up")
+}
@@ -19,12 +19,15 @@ const (
 
 // PushConfig configures the Push operation
 type PushConfig struct {
-	Dirs         []string           // Source directories to archive
-	Bucket       string             // R2 bucket name
-	Key          string             // R2 object key (path/filename.tar.gz)
-	LocalPath    string             // Optional: local backup path. Empty = no local backup
-	ShouldBackup func() bool        // Condition for local backup. Evaluated once before streaming.
-	Events       chan<- ports.Event // Optional: channel for progress events
+	Dirs         []string               // Source directories to archive
+	Bucket       string                 // R2 bucket name
+	Key          string                 // R2 object key (path/filename.tar.gz)
+	LocalPath    string                 // Optional: local backup path. Empty = no local backup
+	ShouldBackup func() bool            // Condition for local backup. Evaluated once before streaming.
+	Events       chan<- ports.Event     // Optional: channel for progress events
+	Codec        Codec                  // Optional: archive compression. nil = plain tar
+	EncryptKey   *Key                   // Optional: AES-GCM encryption key. nil = unencrypted
+	Exclude      func(path string) bool // Optional: entries PushChunks leaves out of the index. nil = include all
 }
 
 // PullConfig configures the Pull operation
@@ -34,14 +37,20 @@ type PullConfig struct {
 	Dest     string                 // Destination directory
 	Conflict ConflictStrategy       // How to handle existing files
 	Filter   func(name string) bool // Optional: filter files to extract. nil = extract all
+	Size     int64                  // Optional: expected archive size in bytes. 0 = not checked
+	Checksum string                 // Optional: expected SHA-256 hex checksum. Empty = not checked
+	Keys     *Keyring               // Optional: keys for encrypted archives. nil = encrypted archives fail
+	Staged   bool                   // Optional: extract into a staging directory and swap top-level entries in with renames
 }
 
 // Result contains Push operation results
 type Result struct {
-	Size      int64  // Total bytes uploaded
-	Checksum  string // SHA-256 checksum of the archive
+	Size      int64  // Total bytes uploaded (after compression)
+	Checksum  string // SHA-256 checksum of the uploaded archive
 	Key       string // R2 object key
 	LocalPath string // Local backup path (empty if backup skipped)
+	KeyID     string // ID of the key that encrypted the archive (empty if unencrypted)
+	NewChunks int    // Chunks uploaded by PushChunks; chunks already stored are reused
 }
 
 // S3StreamUploader interface for R2 streaming uploads
@@ -54,3 +63,8 @@ type S3StreamUploader interface {
 type S3StreamDownloader interface {
 	Download(ctx context.Context, bucket, key string) (io.ReadCloser, error)
 }
+
+// S3StreamLister interface for listing stored objects by key prefix
+type S3StreamLister interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+}
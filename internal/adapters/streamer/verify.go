@@ -0,0 +1,91 @@
+package streamer
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Verify error constants
+var (
+	ErrVerifyContextNil    = errors.New("context cannot be nil")
+	ErrVerifyKeyEmpty      = errors.New("key cannot be empty")
+	ErrVerifyExpectedEmpty = errors.New("size or checksum must be set")
+	ErrVerifyDownloaderNil = errors.New("downloader cannot be nil")
+	ErrChecksumMismatch    = errors.New("archive checksum mismatch")
+	ErrSizeMismatch        = errors.New("archive size mismatch")
+)
+
+// VerifyConfig configures the Verify operation
+type VerifyConfig struct {
+	Bucket   string // R2 bucket name
+	Key      string // R2 object key
+	Size     int64  // Expected object size in bytes. 0 = not checked
+	Checksum string // Expected SHA-256 hex checksum. Empty = not checked
+}
+
+// Verify downloads an object and checks its size and checksum without extracting it
+func Verify(ctx context.Context, cfg VerifyConfig, downloader S3StreamDownloader) error {
+	if ctx == nil {
+		return ErrVerifyContextNil
+	}
+	if cfg.Key == "" {
+		return ErrVerifyKeyEmpty
+	}
+	if cfg.Size <= 0 && cfg.Checksum == "" {
+		return ErrVerifyExpectedEmpty
+	}
+	if downloader == nil {
+		return ErrVerifyDownloaderNil
+	}
+
+	body, err := downloader.Download(ctx, cfg.Bucket, cfg.Key)
+	if err != nil {
+		return fmt.Errorf("R2 download failed: %w", err)
+	}
+	defer body.Close()
+
+	digest := newDigestReader(body)
+	if _, err := copyWithContext(ctx, io.Discard, digest); err != nil {
+		return fmt.Errorf("failed to read %s: %w", cfg.Key, err)
+	}
+
+	return digest.verify(cfg.Key, cfg.Size, cfg.Checksum)
+}
+
+// digestReader hashes and counts the bytes read through it
+type digestReader struct {
+	r    io.Reader
+	hash hash.Hash
+	n    int64
+}
+
+func newDigestReader(r io.Reader) *digestReader {
+	return &digestReader{r: r, hash: sha256.New()}
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if n > 0 {
+		d.hash.Write(p[:n])
+		d.n += int64(n)
+	}
+	return n, err
+}
+
+// verify compares the bytes read so far against the expected size and checksum
+// A zero size or empty checksum is not checked
+func (d *digestReader) verify(key string, size int64, checksum string) error {
+	if size > 0 && d.n != size {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, key, d.n, size)
+	}
+	if checksum != "" {
+		if actual := fmt.Sprintf("%x", d.hash.Sum(nil)); actual != checksum {
+			return fmt.Errorf("%w: %s has %s, expected %s", ErrChecksumMismatch, key, actual, checksum)
+		}
+	}
+	return nil
+}
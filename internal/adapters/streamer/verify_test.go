@@ -0,0 +1,124 @@
+package streamer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256Hex returns the hex SHA-256 checksum of data
+func sha256Hex(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func TestVerify(t *testing.T) {
+	data := []byte("archive bytes")
+	downloader := &mockDownloader{data: data}
+	ctx := context.Background()
+
+	t.Run("validates inputs", func(t *testing.T) {
+		assert.ErrorIs(t, Verify(nil, VerifyConfig{Key: "k", Size: 1}, downloader), ErrVerifyContextNil)
+		assert.ErrorIs(t, Verify(ctx, VerifyConfig{Size: 1}, downloader), ErrVerifyKeyEmpty)
+		assert.ErrorIs(t, Verify(ctx, VerifyConfig{Key: "k"}, downloader), ErrVerifyExpectedEmpty)
+		assert.ErrorIs(t, Verify(ctx, VerifyConfig{Key: "k", Size: 1}, nil), ErrVerifyDownloaderNil)
+	})
+
+	t.Run("matching object passes", func(t *testing.T) {
+		err := Verify(ctx, VerifyConfig{Key: "k", Size: int64(len(data)), Checksum: sha256Hex(data)}, downloader)
+		assert.NoError(t, err)
+	})
+
+	t.Run("size mismatch fails", func(t *testing.T) {
+		err := Verify(ctx, VerifyConfig{Key: "k", Size: int64(len(data)) + 1}, downloader)
+		assert.ErrorIs(t, err, ErrSizeMismatch)
+	})
+
+	t.Run("checksum mismatch fails", func(t *testing.T) {
+		err := Verify(ctx, VerifyConfig{Key: "k", Checksum: sha256Hex([]byte("other"))}, downloader)
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+}
+
+func TestPull_VerifiesChecksum(t *testing.T) {
+	archive := createTestArchive(t, map[string][]byte{"world/level.dat": []byte("level data")})
+
+	t.Run("matching archive is extracted", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "instance")
+		err := Pull(context.Background(), PullConfig{
+			Bucket:   "b",
+			Key:      "worlds/test.tar",
+			Dest:     destDir,
+			Size:     int64(len(archive)),
+			Checksum: sha256Hex(archive),
+		}, &mockDownloader{data: archive})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "level data", string(content))
+
+		// The staging directory is removed after extraction
+		assertNoStagingLeft(t, destDir)
+	})
+
+	t.Run("verified archive is merged into existing files", func(t *testing.T) {
+		destDir := t.TempDir()
+		writeFiles(t, destDir, map[string]string{
+			"world/level.dat":  "old level",
+			"world/region.mca": "kept region",
+		})
+
+		err := Pull(context.Background(), PullConfig{
+			Bucket:   "b",
+			Key:      "worlds/test.tar",
+			Dest:     destDir,
+			Conflict: Backup,
+			Checksum: sha256Hex(archive),
+		}, &mockDownloader{data: archive})
+		require.NoError(t, err)
+
+		assert.Equal(t, "level data", readTestFile(t, destDir, "world/level.dat"))
+		assert.Equal(t, "old level", readTestFile(t, destDir, "world/level.dat.bak"))
+		assert.Equal(t, "kept region", readTestFile(t, destDir, "world/region.mca"), "files outside the archive are kept")
+		assertNoStagingLeft(t, destDir)
+	})
+
+	t.Run("mismatched archive is not extracted", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "instance")
+		require.NoError(t, os.MkdirAll(filepath.Join(destDir, "world"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(destDir, "world", "level.dat"), []byte("original"), 0644))
+
+		err := Pull(context.Background(), PullConfig{
+			Bucket:   "b",
+			Key:      "worlds/test.tar",
+			Dest:     destDir,
+			Checksum: sha256Hex([]byte("something else")),
+		}, &mockDownloader{data: archive})
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+		content, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+		assertNoStagingLeft(t, destDir)
+	})
+
+	t.Run("truncated archive is not extracted", func(t *testing.T) {
+		destDir := filepath.Join(t.TempDir(), "instance")
+		err := Pull(context.Background(), PullConfig{
+			Bucket: "b",
+			Key:    "worlds/test.tar",
+			Dest:   destDir,
+			Size:   int64(len(archive)),
+		}, &mockDownloader{data: archive[:len(archive)/2]})
+		assert.ErrorIs(t, err, ErrSizeMismatch)
+
+		_, err = os.Stat(filepath.Join(destDir, "world"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
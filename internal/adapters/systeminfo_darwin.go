@@ -0,0 +1,97 @@
+//go:build darwin
+
+package adapters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"ritual/internal/core/services"
+	"syscall"
+)
+
+// Page counters reported by the macOS kernel
+const (
+	sysctlFreePages        = "vm.page_free_count"
+	sysctlSpeculativePages = "vm.page_speculative_count"
+	sysctlFileCachePages   = "vm.page_pageable_external_count" // File-backed pages the kernel drops under pressure
+)
+
+// DarwinSystemInfo provides system information on macOS
+type DarwinSystemInfo struct{}
+
+// Compile-time checks to ensure DarwinSystemInfo implements the required interfaces
+var _ services.SystemInfoProvider = (*DarwinSystemInfo)(nil)
+var _ services.DiskInfoProvider = (*DarwinSystemInfo)(nil)
+
+// NewDarwinSystemInfo creates a new DarwinSystemInfo instance
+func NewDarwinSystemInfo() *DarwinSystemInfo {
+	return &DarwinSystemInfo{}
+}
+
+// NewSystemInfo creates the system info provider for the current platform
+func NewSystemInfo() *DarwinSystemInfo {
+	return NewDarwinSystemInfo()
+}
+
+// GetFreeRAMMB returns the available free RAM in megabytes
+// Counts free and speculative pages, plus the file cache where the kernel reports it
+func (d *DarwinSystemInfo) GetFreeRAMMB() (int, error) {
+	var pages uint64
+	for _, name := range []string{sysctlFreePages, sysctlSpeculativePages} {
+		count, err := sysctlUint(name, 4)
+		if err != nil {
+			return 0, err
+		}
+		pages += count
+	}
+	// Older kernels do not report the file cache; free pages alone are a safe lower bound
+	if count, err := sysctlUint(sysctlFileCachePages, 4); err == nil {
+		pages += count
+	}
+
+	// Convert pages to megabytes
+	return int(pages * uint64(os.Getpagesize()) / (1024 * 1024)), nil
+}
+
+// GetFreeDiskMB returns the available free disk space in megabytes for the given path
+func (d *DarwinSystemInfo) GetFreeDiskMB(path string) (int, error) {
+	if path == "" {
+		return 0, errors.New("path cannot be empty")
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+
+	// Bavail is the block count available to unprivileged users
+	freeBytes := stat.Bavail * uint64(stat.Bsize)
+
+	// Convert bytes to megabytes
+	return int(freeBytes / (1024 * 1024)), nil
+}
+
+// sysctlUint reads an unsigned integer sysctl of size bytes
+func sysctlUint(name string, size int) (uint64, error) {
+	value, err := syscall.Sysctl(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sysctl %s: %w", name, err)
+	}
+	return decodeSysctlUint(name, []byte(value), size)
+}
+
+// decodeSysctlUint decodes a little-endian sysctl value of size bytes
+// syscall.Sysctl drops a trailing zero byte, so short values are padded back to size
+func decodeSysctlUint(name string, value []byte, size int) (uint64, error) {
+	if len(value) > size || (size != 4 && size != 8) {
+		return 0, fmt.Errorf("unexpected %d-byte value for sysctl %s", len(value), name)
+	}
+	padded := make([]byte, size)
+	copy(padded, value)
+	if size == 4 {
+		return uint64(binary.LittleEndian.Uint32(padded)), nil
+	}
+	return binary.LittleEndian.Uint64(padded), nil
+}
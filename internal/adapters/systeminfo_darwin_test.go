@@ -0,0 +1,39 @@
+//go:build darwin
+
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSysctlUint(t *testing.T) {
+	value, err := decodeSysctlUint("vm.page_free_count", []byte{0x10, 0x27, 0x00, 0x00}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10000), value)
+
+	// 0x00010000 arrives as three bytes once the trailing zero is dropped
+	value, err = decodeSysctlUint("vm.page_free_count", []byte{0x00, 0x00, 0x01}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(65536), value)
+
+	_, err = decodeSysctlUint("vm.page_free_count", make([]byte, 8), 4)
+	assert.Error(t, err)
+}
+
+func TestDarwinSystemInfo(t *testing.T) {
+	info := NewSystemInfo()
+
+	ram, err := info.GetFreeRAMMB()
+	require.NoError(t, err)
+	assert.Positive(t, ram)
+
+	disk, err := info.GetFreeDiskMB(t.TempDir())
+	require.NoError(t, err)
+	assert.Positive(t, disk)
+
+	_, err = info.GetFreeDiskMB("")
+	assert.Error(t, err)
+}
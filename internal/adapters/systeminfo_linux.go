@@ -0,0 +1,99 @@
+//go:build linux
+
+package adapters
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"ritual/internal/core/services"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// memInfoPath is the kernel memory statistics file
+const memInfoPath = "/proc/meminfo"
+
+// LinuxSystemInfo provides system information on Linux
+type LinuxSystemInfo struct{}
+
+// Compile-time checks to ensure LinuxSystemInfo implements the required interfaces
+var _ services.SystemInfoProvider = (*LinuxSystemInfo)(nil)
+var _ services.DiskInfoProvider = (*LinuxSystemInfo)(nil)
+
+// NewLinuxSystemInfo creates a new LinuxSystemInfo instance
+func NewLinuxSystemInfo() *LinuxSystemInfo {
+	return &LinuxSystemInfo{}
+}
+
+// NewSystemInfo creates the system info provider for the current platform
+func NewSystemInfo() *LinuxSystemInfo {
+	return NewLinuxSystemInfo()
+}
+
+// GetFreeRAMMB returns the available free RAM in megabytes
+// Uses MemAvailable from /proc/meminfo (includes reclaimable page cache)
+func (l *LinuxSystemInfo) GetFreeRAMMB() (int, error) {
+	file, err := os.Open(memInfoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", memInfoPath, err)
+	}
+	defer file.Close()
+
+	availableKB, err := parseMemAvailableKB(file)
+	if err != nil {
+		return 0, err
+	}
+
+	// Convert kilobytes to megabytes
+	return int(availableKB / 1024), nil
+}
+
+// GetFreeDiskMB returns the available free disk space in megabytes for the given path
+func (l *LinuxSystemInfo) GetFreeDiskMB(path string) (int, error) {
+	if path == "" {
+		return 0, errors.New("path cannot be empty")
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+
+	// Bavail is the block count available to unprivileged users
+	freeBytes := stat.Bavail * uint64(stat.Bsize)
+
+	// Convert bytes to megabytes
+	return int(freeBytes / (1024 * 1024)), nil
+}
+
+// parseMemAvailableKB extracts the MemAvailable value in kilobytes from /proc/meminfo content
+// Expected line format: "MemAvailable:   12345678 kB"
+func parseMemAvailableKB(r io.Reader) (uint64, error) {
+	if r == nil {
+		return 0, errors.New("reader cannot be nil")
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse MemAvailable value %q: %w", fields[1], err)
+		}
+		return value, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+
+	return 0, errors.New("MemAvailable not found in meminfo")
+}
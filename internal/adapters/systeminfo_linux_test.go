@@ -0,0 +1,82 @@
+//go:build linux
+
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMemAvailableKB(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantKB  uint64
+		wantErr bool
+	}{
+		{
+			name: "typical meminfo",
+			input: `MemTotal:       16303892 kB
+MemFree:         1234567 kB
+MemAvailable:    8388608 kB
+Buffers:          345678 kB
+Cached:          4567890 kB`,
+			wantKB:  8388608,
+			wantErr: false,
+		},
+		{
+			name:    "MemAvailable missing",
+			input:   "MemTotal:       16303892 kB\nMemFree:         1234567 kB\n",
+			wantKB:  0,
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			input:   "MemAvailable:    lots kB\n",
+			wantKB:  0,
+			wantErr: true,
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantKB:  0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb, err := parseMemAvailableKB(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantKB, kb)
+			}
+		})
+	}
+}
+
+func TestLinuxSystemInfo_GetFreeRAMMB(t *testing.T) {
+	info := NewSystemInfo()
+
+	ram, err := info.GetFreeRAMMB()
+	assert.NoError(t, err)
+	assert.Greater(t, ram, 0)
+}
+
+func TestLinuxSystemInfo_GetFreeDiskMB(t *testing.T) {
+	info := NewSystemInfo()
+
+	disk, err := info.GetFreeDiskMB(t.TempDir())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, disk, 0)
+
+	_, err = info.GetFreeDiskMB("")
+	assert.Error(t, err)
+
+	_, err = info.GetFreeDiskMB("/nonexistent/path/for/statfs")
+	assert.Error(t, err)
+}
@@ -37,6 +37,11 @@ func NewWindowsSystemInfo() *WindowsSystemInfo {
 	return &WindowsSystemInfo{}
 }
 
+// NewSystemInfo creates the system info provider for the current platform
+func NewSystemInfo() *WindowsSystemInfo {
+	return NewWindowsSystemInfo()
+}
+
 // GetFreeRAMMB returns the available free RAM in megabytes
 func (w *WindowsSystemInfo) GetFreeRAMMB() (int, error) {
 	var memStatus memoryStatusEx
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Version info (single source of truth)
@@ -37,23 +38,65 @@ const (
 // File names and keys
 const (
 	ManifestFilename    = "manifest.json"
+	LockFilename        = "lock.json"
+	LockAuditKey        = "lock_audit.jsonl"
 	InstanceArchiveKey  = "instance.tar"
+	InstanceIndexKey    = "instance.index.json" // File index of a published instance, used for delta updates
 	RemoteBinaryKey     = "ritual.exe"
 	ManualWorldFilename = "manual.tar"
 	ServerJarFilename   = "paper.jar"
 	ServerLogFilename   = "server.log"
 )
 
-// Backup configuration
-const (
+// Backup retention limits
+// Defaults; the runtime configuration may override them (see runtime.go)
+var (
 	R2MaxBackups    = 2
 	LocalMaxBackups = 2
-	MaxFiles        = 1000
 	MaxLogFiles     = 10
+)
+
+// Backup configuration
+const (
+	MaxFiles = 1000
 
 	TimestampFormat = "20060102150405"
 	BackupExtension = ".tar"
+	BackupCodec     = "gzip" // Streamer codec name for new world backups; "none" keeps plain tar
 	LogExtension    = ".log"
+	MaxChunks       = 1000000 // Upper bound on stored chunks examined by chunk garbage collection
+
+	BackupNoteTimeoutSec = 30 // Time given to enter a backup note before the backup is recorded without one
+)
+
+// InstanceProtectedPaths are instance paths that delta updates never replace or delete
+// They hold state the server creates at runtime; world directories from the manifest are protected too
+// Publishing leaves them out, so server.properties with the host address and RCON password stays local
+var InstanceProtectedPaths = []string{
+	"logs",
+	"crash-reports",
+	"cache",
+	"libraries",
+	"versions",
+	"usercache.json",
+	"ops.json",
+	"whitelist.json",
+	"banned-players.json",
+	"banned-ips.json",
+	"server.properties",
+}
+
+// Backup modes selected by the manifest
+const (
+	BackupModeArchive = "archive" // Full archive of all world directories per backup (default)
+	BackupModeChunked = "chunked" // Content-addressed chunks shared between backups, plus a per-backup index
+)
+
+// Backup replication
+// Verified backups are copied from the primary remote to each additional target
+const (
+	PrimaryReplica   = "primary"       // Target name of the remote that holds the manifest
+	ReplicasFilename = "replicas.json" // Additional backup targets, read from the root directory
 )
 
 // Default manifest thresholds
@@ -69,6 +112,26 @@ const (
 	CleanupFlag = "--cleanup-update"
 )
 
+// CLI subcommands
+const (
+	UnlockCommand    = "unlock"
+	RestoreCommand   = "restore"
+	PublishCommand   = "publish"
+	ConfigCommand    = "config"
+	RetentionCommand = "retention"
+	PinCommand       = "pin"
+	UnpinCommand     = "unpin"
+	AnnotateCommand  = "annotate"
+	ListCommand      = "list"
+
+	RetentionPlanAction = "plan" // ritual retention plan
+)
+
+// Lock audit log configuration
+const (
+	LockAuditAppendAttempts = 5 // Conditional append retries when another host writes concurrently
+)
+
 // Update process timing
 const (
 	UpdateProcessDelayMs = 500
@@ -80,20 +143,98 @@ const (
 	UpdateFileGlob    = "ritual_update_*.exe"
 )
 
+// Server process configuration
+const (
+	ServerStopCommand    = "stop"
+	ServerStopTimeoutSec = 60 // Time to wait for a graceful stop before killing the process
+)
+
+// RCON configuration
+// With RCON enabled the server runner sets a new password for every run
+const (
+	RconPortOffset        = 10 // RCON listens this far above the game port (25575 for 25565)
+	RconPasswordBytes     = 24 // Random bytes in a generated password, hex encoded
+	RconDialTimeoutSec    = 5
+	RconCommandTimeoutSec = 10 // Time allowed to send a command and read its whole response
+)
+
+// Server List Ping health probe configuration
+// The probe polls the running server's status to report players and notice when it stops answering
+const (
+	HealthProbeInterval         = 30 * time.Second
+	HealthProbeTimeoutSec       = 5                // Time allowed to connect and read the status response
+	HealthProbeFailureThreshold = 3                // Consecutive failed probes before the server counts as down
+	HealthProbeStartupTimeout   = 10 * time.Minute // Time the server has to answer its first probe
+)
+
+// In-session checkpoint configuration
+// Checkpoints back up the world while the server runs; the session's final backup supersedes them
+const (
+	ServerSaveOffCommand   = "save-off"       // Stops the server writing world files during a checkpoint
+	ServerSaveAllCommand   = "save-all flush" // Writes every loaded chunk to disk
+	ServerSaveOnCommand    = "save-on"        // Resumes writing world files
+	CheckpointFlushWaitSec = 10               // Time given to save-all before the world is archived
+	CheckpointMinInterval  = time.Minute      // Shortest interval; backup keys have one-second resolution
+)
+
+// CheckpointInterval is the time between in-session checkpoints; 0 disables them
+// The runtime configuration may override it (see runtime.go)
+var CheckpointInterval = time.Hour
+
+// RconEnabled makes the server runner enable RCON and send console commands over it
+// The runtime configuration may override it (see runtime.go)
+var RconEnabled = false
+
 // Lock ID format
 const (
 	LockIDSeparator = "::"
 )
 
+// Session lease configuration
+const (
+	LeaseDurationSec  = 300 // Lease lifetime without a heartbeat
+	LeaseHeartbeatSec = 60  // Interval between lease renewals while the server runs
+)
+
+// Archive encryption configuration
+// Encryption is enabled when a current key is available; otherwise archives are stored in the clear
+const (
+	ArchiveKeyFilename = "archive.key" // Current key: 64 hex characters or a passphrase
+	RetiredKeysDir     = "keys"        // Older keys kept to open archives written before a rotation
+	KeyFileExtension   = ".key"
+	PassphraseEnvVar   = "RITUAL_PASSPHRASE" // Overrides archive.key when set
+)
+
 // S3/R2 configuration
 const (
-	S3PartSize    = 5 * 1024 * 1024 // 5 MB parts for multipart upload
+	S3MinPartSize = 5 * 1024 * 1024 // Smallest part S3 accepts for multipart upload
 	S3Concurrency = 1               // Sequential upload to minimize memory
 )
 
-// R2 endpoint format
+// S3PartSize is the multipart upload part size; the runtime configuration may override it
+var S3PartSize int64 = S3MinPartSize
+
+// Runtime configuration
+// Values are layered: defaults, build-time values, the config file, environment, then flags
+const (
+	RuntimeConfigFilename = "config.json" // Read from the root directory
+	RuntimeEnvPrefix      = "RITUAL_"     // Environment variable name = prefix + upper-case key
+	RuntimeFlagPrefix     = "--"          // Flag name = prefix + key with dashes, e.g. --r2-max-backups
+)
+
+// R2 retry policy for Get, Put, List, Copy and interrupted downloads
+const (
+	R2RetryAttempts    = 5     // Attempts per operation; for downloads, consecutive failures without progress
+	R2RetryBaseDelayMs = 1000  // Delay before the first retry, doubled for each further attempt
+	R2RetryMaxDelayMs  = 30000 // Upper bound on a single retry delay
+)
+
+// Remote backend defaults
+// Without an explicit endpoint the remote is the Cloudflare R2 account named at build time
 const (
 	R2EndpointFormat = "https://%s.r2.cloudflarestorage.com"
+	R2Region         = "auto"
+	S3DefaultRegion  = "us-east-1" // Signing region for backends that ignore it (MinIO, Garage)
 )
 
 // File permissions
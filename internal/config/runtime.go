@@ -0,0 +1,409 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Runtime configuration error constants
+var (
+	ErrRuntimeUnknownKey  = errors.New("unknown configuration key")
+	ErrRuntimeInvalid     = errors.New("invalid configuration value")
+	ErrRuntimeRootInFile  = errors.New("root cannot be set in the config file")
+	ErrRuntimeFlagMissing = errors.New("flag requires a value")
+)
+
+// Source names the layer an effective configuration value came from
+type Source string
+
+// Configuration layers, lowest precedence first
+const (
+	SourceDefault Source = "default"
+	SourceBuild   Source = "build"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Runtime configuration keys
+// Keys name the value in the config file; flags and environment variables are derived from them
+const (
+	KeyRoot            = "root"
+	KeyAccountID       = "account_id"
+	KeyAccessKeyID     = "access_key_id"
+	KeySecretAccessKey = "secret_access_key"
+	KeyBucket          = "bucket"
+	KeyEndpoint        = "endpoint"
+	KeyRegion          = "region"
+	KeyPathStyle       = "path_style"
+	KeyTLSInsecure     = "tls_insecure"
+	KeyCACertFile      = "ca_cert_file"
+	KeyR2MaxBackups    = "r2_max_backups"
+	KeyLocalMaxBackups = "local_max_backups"
+	KeyMaxLogFiles     = "max_log_files"
+	KeyS3PartSize      = "s3_part_size"
+	KeyCheckpoint      = "checkpoint_interval"
+	KeyRcon            = "rcon"
+)
+
+// Runtime holds the effective configuration and the layer each value came from
+type Runtime struct {
+	Root               string // Directory holding instance, backups, logs and the config file
+	AccountID          string // Cloudflare R2 account; used when Endpoint is empty
+	AccessKeyID        string
+	SecretAccessKey    string
+	Bucket             string
+	Endpoint           string // S3-compatible endpoint URL; empty = R2
+	Region             string
+	PathStyle          bool
+	TLSInsecure        bool
+	CACertFile         string
+	R2MaxBackups       int
+	LocalMaxBackups    int
+	MaxLogFiles        int
+	S3PartSize         int64
+	CheckpointInterval time.Duration // Interval between in-session backups; 0 = only back up at exit
+	Rcon               bool          // Send server commands over RCON instead of the console
+
+	sources map[string]Source
+}
+
+// RuntimeValue is one effective configuration value as reported to the operator
+type RuntimeValue struct {
+	Key    string
+	Value  string // Secrets are masked
+	Source Source
+	Origin string // File path, environment variable or flag that set the value; empty for defaults
+}
+
+// RuntimeLoader reads the configuration layers
+type RuntimeLoader struct {
+	Build     map[string]string               // Values injected at build time; empty values are ignored
+	Args      []string                        // Command-line arguments without the program name
+	LookupEnv func(key string) (string, bool) // nil = os.LookupEnv
+}
+
+// runtimeField describes how one key is parsed into and formatted from Runtime
+type runtimeField struct {
+	key    string
+	secret bool
+	set    func(r *Runtime, value string) error
+	get    func(r *Runtime) string
+}
+
+// runtimeFields lists every configuration key in report order
+var runtimeFields = []runtimeField{
+	{key: KeyRoot, set: setString(func(r *Runtime) *string { return &r.Root }), get: func(r *Runtime) string { return r.Root }},
+	{key: KeyAccountID, set: setString(func(r *Runtime) *string { return &r.AccountID }), get: func(r *Runtime) string { return r.AccountID }},
+	{key: KeyAccessKeyID, set: setString(func(r *Runtime) *string { return &r.AccessKeyID }), get: func(r *Runtime) string { return r.AccessKeyID }},
+	{key: KeySecretAccessKey, secret: true, set: setString(func(r *Runtime) *string { return &r.SecretAccessKey }), get: func(r *Runtime) string { return r.SecretAccessKey }},
+	{key: KeyBucket, set: setString(func(r *Runtime) *string { return &r.Bucket }), get: func(r *Runtime) string { return r.Bucket }},
+	{key: KeyEndpoint, set: setString(func(r *Runtime) *string { return &r.Endpoint }), get: func(r *Runtime) string { return r.Endpoint }},
+	{key: KeyRegion, set: setString(func(r *Runtime) *string { return &r.Region }), get: func(r *Runtime) string { return r.Region }},
+	{key: KeyPathStyle, set: setBool(func(r *Runtime) *bool { return &r.PathStyle }), get: func(r *Runtime) string { return strconv.FormatBool(r.PathStyle) }},
+	{key: KeyTLSInsecure, set: setBool(func(r *Runtime) *bool { return &r.TLSInsecure }), get: func(r *Runtime) string { return strconv.FormatBool(r.TLSInsecure) }},
+	{key: KeyCACertFile, set: setString(func(r *Runtime) *string { return &r.CACertFile }), get: func(r *Runtime) string { return r.CACertFile }},
+	{key: KeyR2MaxBackups, set: setCount(func(r *Runtime) *int { return &r.R2MaxBackups }), get: func(r *Runtime) string { return strconv.Itoa(r.R2MaxBackups) }},
+	{key: KeyLocalMaxBackups, set: setCount(func(r *Runtime) *int { return &r.LocalMaxBackups }), get: func(r *Runtime) string { return strconv.Itoa(r.LocalMaxBackups) }},
+	{key: KeyMaxLogFiles, set: setCount(func(r *Runtime) *int { return &r.MaxLogFiles }), get: func(r *Runtime) string { return strconv.Itoa(r.MaxLogFiles) }},
+	{key: KeyS3PartSize, set: setPartSize, get: func(r *Runtime) string { return strconv.FormatInt(r.S3PartSize, 10) }},
+	{key: KeyCheckpoint, set: setCheckpoint, get: func(r *Runtime) string { return r.CheckpointInterval.String() }},
+	{key: KeyRcon, set: setBool(func(r *Runtime) *bool { return &r.Rcon }), get: func(r *Runtime) string { return strconv.FormatBool(r.Rcon) }},
+}
+
+// DefaultRuntime returns the configuration before any layer is applied
+func DefaultRuntime() *Runtime {
+	r := &Runtime{
+		Root:               RootPath,
+		R2MaxBackups:       R2MaxBackups,
+		LocalMaxBackups:    LocalMaxBackups,
+		MaxLogFiles:        MaxLogFiles,
+		S3PartSize:         S3PartSize,
+		CheckpointInterval: CheckpointInterval,
+		Rcon:               RconEnabled,
+		sources:            make(map[string]Source, len(runtimeFields)),
+	}
+	for _, field := range runtimeFields {
+		r.sources[field.key] = SourceDefault
+	}
+	return r
+}
+
+// EnvName returns the environment variable that sets key
+func EnvName(key string) string {
+	return RuntimeEnvPrefix + strings.ToUpper(key)
+}
+
+// FlagName returns the command-line flag that sets key
+func FlagName(key string) string {
+	return RuntimeFlagPrefix + strings.ReplaceAll(key, "_", "-")
+}
+
+// ConfigFilePath returns the config file read for root
+func ConfigFilePath(root string) string {
+	return filepath.Join(root, RuntimeConfigFilename)
+}
+
+// Load applies the layers in precedence order and returns the effective configuration
+// Recognized flags are removed from the returned arguments; all other arguments keep their order
+// The root is resolved from environment and flags first because the config file lives under it
+func (l RuntimeLoader) Load() (*Runtime, []string, error) {
+	lookupEnv := l.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	flags, rest, err := parseRuntimeFlags(l.Args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := DefaultRuntime()
+	for _, field := range runtimeFields {
+		if value := l.Build[field.key]; value != "" {
+			if err := r.apply(field, value, SourceBuild); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	root := r.Root
+	if value, ok := lookupEnv(EnvName(KeyRoot)); ok && value != "" {
+		root = value
+	}
+	if value, ok := flags[KeyRoot]; ok {
+		root = value
+	}
+
+	fileValues, err := readRuntimeFile(ConfigFilePath(root))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, field := range runtimeFields {
+		if value, ok := fileValues[field.key]; ok {
+			if err := r.apply(field, value, SourceFile); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", ConfigFilePath(root), err)
+			}
+		}
+	}
+
+	for _, field := range runtimeFields {
+		if value, ok := lookupEnv(EnvName(field.key)); ok && value != "" {
+			if err := r.apply(field, value, SourceEnv); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", EnvName(field.key), err)
+			}
+		}
+	}
+
+	for _, field := range runtimeFields {
+		if value, ok := flags[field.key]; ok {
+			if err := r.apply(field, value, SourceFlag); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", FlagName(field.key), err)
+			}
+		}
+	}
+
+	return r, rest, nil
+}
+
+// apply parses value into field and records its source
+func (r *Runtime) apply(field runtimeField, value string, source Source) error {
+	if err := field.set(r, value); err != nil {
+		return fmt.Errorf("%w for %s from %s: %w", ErrRuntimeInvalid, field.key, source, err)
+	}
+	r.sources[field.key] = source
+	return nil
+}
+
+// Source returns the layer the effective value of key came from
+func (r *Runtime) Source(key string) Source {
+	if source, ok := r.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Report lists every effective value with its source, masking secrets
+func (r *Runtime) Report() []RuntimeValue {
+	values := make([]RuntimeValue, 0, len(runtimeFields))
+	for _, field := range runtimeFields {
+		value := field.get(r)
+		if field.secret && value != "" {
+			value = "********"
+		}
+		source := r.Source(field.key)
+		values = append(values, RuntimeValue{Key: field.key, Value: value, Source: source, Origin: r.origin(field.key, source)})
+	}
+	return values
+}
+
+// origin names the file, variable or flag behind a source
+func (r *Runtime) origin(key string, source Source) string {
+	switch source {
+	case SourceFile:
+		return ConfigFilePath(r.Root)
+	case SourceEnv:
+		return EnvName(key)
+	case SourceFlag:
+		return FlagName(key)
+	case SourceBuild:
+		return "ldflags"
+	default:
+		return ""
+	}
+}
+
+// Apply makes the root and tunables effective for the packages that read them from config
+// Call once at startup, before any service is created
+func (r *Runtime) Apply() {
+	RootPath = r.Root
+	R2MaxBackups = r.R2MaxBackups
+	LocalMaxBackups = r.LocalMaxBackups
+	MaxLogFiles = r.MaxLogFiles
+	S3PartSize = r.S3PartSize
+	CheckpointInterval = r.CheckpointInterval
+	RconEnabled = r.Rcon
+}
+
+// parseRuntimeFlags extracts --key=value and --key value flags for known keys
+// Unknown arguments, including other flags, are returned unchanged
+func parseRuntimeFlags(args []string) (map[string]string, []string, error) {
+	known := make(map[string]string, len(runtimeFields))
+	for _, field := range runtimeFields {
+		known[FlagName(field.key)] = field.key
+	}
+
+	flags := make(map[string]string)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		key, ok := known[name]
+		if !ok {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("%w: %s", ErrRuntimeFlagMissing, name)
+			}
+			i++
+			value = args[i]
+		}
+		flags[key] = value
+	}
+	return flags, rest, nil
+}
+
+// readRuntimeFile reads the config file as a flat JSON object of strings, numbers and booleans
+// A missing file yields no values
+func readRuntimeFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]bool, len(runtimeFields))
+	for _, field := range runtimeFields {
+		known[field.key] = true
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make(map[string]string, len(raw))
+	for _, key := range keys {
+		if key == KeyRoot {
+			return nil, fmt.Errorf("%s: %w", path, ErrRuntimeRootInFile)
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("%s: %w %q", path, ErrRuntimeUnknownKey, key)
+		}
+		message := bytes.TrimSpace(raw[key])
+		var text string
+		if err := json.Unmarshal(message, &text); err == nil {
+			values[key] = text
+			continue
+		}
+		if len(message) == 0 || message[0] == '{' || message[0] == '[' || string(message) == "null" {
+			return nil, fmt.Errorf("%s: %w for %s: expected a string, number or boolean", path, ErrRuntimeInvalid, key)
+		}
+		values[key] = string(message)
+	}
+	return values, nil
+}
+
+// setString returns a setter for a string field
+func setString(field func(r *Runtime) *string) func(r *Runtime, value string) error {
+	return func(r *Runtime, value string) error {
+		*field(r) = value
+		return nil
+	}
+}
+
+// setBool returns a setter for a boolean field
+func setBool(field func(r *Runtime) *bool) func(r *Runtime, value string) error {
+	return func(r *Runtime, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*field(r) = parsed
+		return nil
+	}
+}
+
+// setCount returns a setter for a positive integer field
+func setCount(field func(r *Runtime) *int) func(r *Runtime, value string) error {
+	return func(r *Runtime, value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("%q is not a positive integer", value)
+		}
+		*field(r) = parsed
+		return nil
+	}
+}
+
+// setPartSize parses the multipart part size in bytes
+func setPartSize(r *Runtime, value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < S3MinPartSize {
+		return fmt.Errorf("%q must be a byte count of at least %d", value, S3MinPartSize)
+	}
+	r.S3PartSize = parsed
+	return nil
+}
+
+// setCheckpoint parses the checkpoint interval as a duration such as "30m"
+// "0" disables checkpoints
+func setCheckpoint(r *Runtime, value string) error {
+	if value == "0" {
+		r.CheckpointInterval = 0
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < CheckpointMinInterval {
+		return fmt.Errorf("%q must be 0 or a duration of at least %s", value, CheckpointMinInterval)
+	}
+	r.CheckpointInterval = parsed
+	return nil
+}
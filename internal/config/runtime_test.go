@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnv returns a LookupEnv over a fixed set of variables
+func testEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+// writeConfigFile writes content as the config file under root
+func writeConfigFile(t *testing.T, root, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(root, RuntimeConfigFilename), []byte(content), 0644))
+}
+
+func TestRuntimeLoader_Precedence(t *testing.T) {
+	root := t.TempDir()
+	writeConfigFile(t, root, `{
+		"bucket": "file-bucket",
+		"region": "file-region",
+		"endpoint": "http://file:9000",
+		"path_style": true,
+		"r2_max_backups": 5,
+		"max_log_files": "7"
+	}`)
+
+	loader := RuntimeLoader{
+		Build: map[string]string{
+			KeyAccountID: "build-account",
+			KeyBucket:    "build-bucket",
+			KeyRegion:    "build-region",
+		},
+		Args: []string{"--root", root, "restore", "--region=flag-region", "extra"},
+		LookupEnv: testEnv(map[string]string{
+			"RITUAL_REGION":            "env-region",
+			"RITUAL_LOCAL_MAX_BACKUPS": "4",
+			"RITUAL_ENDPOINT":          "",
+		}),
+	}
+
+	rt, rest, err := loader.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"restore", "extra"}, rest)
+
+	assert.Equal(t, root, rt.Root)
+	assert.Equal(t, SourceFlag, rt.Source(KeyRoot))
+	assert.Equal(t, "build-account", rt.AccountID)
+	assert.Equal(t, SourceBuild, rt.Source(KeyAccountID))
+	assert.Equal(t, "file-bucket", rt.Bucket)
+	assert.Equal(t, SourceFile, rt.Source(KeyBucket))
+	assert.Equal(t, "http://file:9000", rt.Endpoint, "empty environment values do not override")
+	assert.True(t, rt.PathStyle)
+	assert.Equal(t, 5, rt.R2MaxBackups)
+	assert.Equal(t, 7, rt.MaxLogFiles)
+	assert.Equal(t, 4, rt.LocalMaxBackups)
+	assert.Equal(t, SourceEnv, rt.Source(KeyLocalMaxBackups))
+	assert.Equal(t, "flag-region", rt.Region)
+	assert.Equal(t, SourceFlag, rt.Source(KeyRegion))
+	assert.Equal(t, int64(S3PartSize), rt.S3PartSize)
+	assert.Equal(t, SourceDefault, rt.Source(KeyS3PartSize))
+}
+
+func TestRuntimeLoader_RootFromEnvironment(t *testing.T) {
+	root := t.TempDir()
+	writeConfigFile(t, root, `{"bucket": "group-b"}`)
+
+	rt, _, err := RuntimeLoader{LookupEnv: testEnv(map[string]string{"RITUAL_ROOT": root})}.Load()
+	require.NoError(t, err)
+	assert.Equal(t, root, rt.Root)
+	assert.Equal(t, "group-b", rt.Bucket)
+}
+
+func TestRuntimeLoader_Errors(t *testing.T) {
+	cases := map[string]struct {
+		file string
+		env  map[string]string
+		args []string
+		err  error
+		text string
+	}{
+		"unknown file key":      {file: `{"buckett": "x"}`, err: ErrRuntimeUnknownKey, text: "buckett"},
+		"root in file":          {file: `{"root": "/elsewhere"}`, err: ErrRuntimeRootInFile},
+		"nested file value":     {file: `{"bucket": {"name": "x"}}`, err: ErrRuntimeInvalid},
+		"invalid file count":    {file: `{"r2_max_backups": 0}`, err: ErrRuntimeInvalid, text: "from file"},
+		"invalid env boolean":   {env: map[string]string{"RITUAL_PATH_STYLE": "sometimes"}, err: ErrRuntimeInvalid, text: "RITUAL_PATH_STYLE"},
+		"part size below limit": {args: []string{"--s3-part-size=1024"}, err: ErrRuntimeInvalid, text: "--s3-part-size"},
+		"flag without value":    {args: []string{"--bucket"}, err: ErrRuntimeFlagMissing},
+		"checkpoint too often":  {args: []string{"--checkpoint-interval=30s"}, err: ErrRuntimeInvalid, text: "--checkpoint-interval"},
+		"checkpoint not a time": {file: `{"checkpoint_interval": 30}`, err: ErrRuntimeInvalid, text: "from file"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.file != "" {
+				writeConfigFile(t, root, tc.file)
+			}
+			loader := RuntimeLoader{Args: append([]string{"--root=" + root}, tc.args...), LookupEnv: testEnv(tc.env)}
+
+			_, _, err := loader.Load()
+			assert.ErrorIs(t, err, tc.err)
+			if tc.text != "" {
+				assert.Contains(t, err.Error(), tc.text)
+			}
+		})
+	}
+}
+
+func TestRuntime_Report(t *testing.T) {
+	root := t.TempDir()
+	loader := RuntimeLoader{
+		Args:      []string{"--root", root},
+		LookupEnv: testEnv(map[string]string{"RITUAL_SECRET_ACCESS_KEY": "hunter2"}),
+	}
+	rt, _, err := loader.Load()
+	require.NoError(t, err)
+
+	report := make(map[string]RuntimeValue)
+	for _, value := range rt.Report() {
+		report[value.Key] = value
+	}
+	require.Len(t, report, len(runtimeFields))
+
+	assert.Equal(t, RuntimeValue{Key: KeySecretAccessKey, Value: "********", Source: SourceEnv, Origin: "RITUAL_SECRET_ACCESS_KEY"}, report[KeySecretAccessKey])
+	assert.Equal(t, RuntimeValue{Key: KeyRoot, Value: root, Source: SourceFlag, Origin: "--root"}, report[KeyRoot])
+	assert.Equal(t, SourceDefault, report[KeyMaxLogFiles].Source)
+	assert.Empty(t, report[KeyMaxLogFiles].Origin)
+}
+
+func TestRuntime_Apply(t *testing.T) {
+	saved := *DefaultRuntime()
+	t.Cleanup(saved.Apply)
+
+	rt := DefaultRuntime()
+	rt.Root = t.TempDir()
+	rt.R2MaxBackups = 9
+	rt.LocalMaxBackups = 8
+	rt.MaxLogFiles = 3
+	rt.S3PartSize = 16 * 1024 * 1024
+	rt.CheckpointInterval = 30 * time.Minute
+	rt.Rcon = true
+	rt.Apply()
+
+	assert.Equal(t, rt.Root, RootPath)
+	assert.Equal(t, 9, R2MaxBackups)
+	assert.Equal(t, 8, LocalMaxBackups)
+	assert.Equal(t, 3, MaxLogFiles)
+	assert.Equal(t, int64(16*1024*1024), S3PartSize)
+	assert.Equal(t, 30*time.Minute, CheckpointInterval)
+	assert.True(t, RconEnabled)
+}
+
+func TestRuntimeLoader_CheckpointInterval(t *testing.T) {
+	root := t.TempDir()
+	rt, _, err := RuntimeLoader{Args: []string{"--root", root}, LookupEnv: testEnv(nil)}.Load()
+	require.NoError(t, err)
+	assert.Equal(t, CheckpointInterval, rt.CheckpointInterval)
+
+	writeConfigFile(t, root, `{"checkpoint_interval": "45m"}`)
+	rt, _, err = RuntimeLoader{Args: []string{"--root", root}, LookupEnv: testEnv(nil)}.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, rt.CheckpointInterval)
+
+	rt, _, err = RuntimeLoader{Args: []string{"--root", root, "--checkpoint-interval", "0"}, LookupEnv: testEnv(nil)}.Load()
+	require.NoError(t, err)
+	assert.Zero(t, rt.CheckpointInterval, "0 disables checkpoints")
+}
+
+func TestRuntimeLoader_Rcon(t *testing.T) {
+	root := t.TempDir()
+	rt, _, err := RuntimeLoader{Args: []string{"--root", root}, LookupEnv: testEnv(nil)}.Load()
+	require.NoError(t, err)
+	assert.False(t, rt.Rcon, "RCON is opt-in")
+
+	writeConfigFile(t, root, `{"rcon": true}`)
+	rt, _, err = RuntimeLoader{Args: []string{"--root", root}, LookupEnv: testEnv(nil)}.Load()
+	require.NoError(t, err)
+	assert.True(t, rt.Rcon)
+	assert.Equal(t, SourceFile, rt.Source(KeyRcon))
+
+	_, _, err = RuntimeLoader{Args: []string{"--root", root}, LookupEnv: testEnv(map[string]string{"RITUAL_RCON": "maybe"})}.Load()
+	assert.Error(t, err)
+}
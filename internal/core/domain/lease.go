@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lease represents the remote lock object held while a session runs
+// It expires unless the holder keeps renewing it with heartbeats
+type Lease struct {
+	Owner      string    `json:"owner"`      // hostname of the lock holder
+	SessionID  string    `json:"session_id"` // lock ID of the session, matches Manifest.LockedBy
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewLease creates a new Lease instance with validation
+func NewLease(owner string, sessionID string, duration time.Duration) (*Lease, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("owner cannot be empty")
+	}
+	if sessionID == "" {
+		return nil, fmt.Errorf("session ID cannot be empty")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("lease duration must be positive")
+	}
+
+	now := time.Now()
+	return &Lease{
+		Owner:      owner,
+		SessionID:  sessionID,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(duration),
+	}, nil
+}
+
+// IsExpired returns true if the lease expired at or before now
+func (l *Lease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// Renew extends the lease to expire duration after now
+func (l *Lease) Renew(now time.Time, duration time.Duration) {
+	l.ExpiresAt = now.Add(duration)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLease(t *testing.T) {
+	lease, err := NewLease("host-a", "host-a::123", time.Minute)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "host-a", lease.Owner)
+	assert.Equal(t, "host-a::123", lease.SessionID)
+	assert.False(t, lease.AcquiredAt.IsZero(), "Expected AcquiredAt to be set")
+	assert.Equal(t, time.Minute, lease.ExpiresAt.Sub(lease.AcquiredAt))
+}
+
+func TestNewLeaseValidation(t *testing.T) {
+	_, err := NewLease("", "host-a::123", time.Minute)
+	assert.ErrorContains(t, err, "owner cannot be empty")
+
+	_, err = NewLease("host-a", "", time.Minute)
+	assert.ErrorContains(t, err, "session ID cannot be empty")
+
+	_, err = NewLease("host-a", "host-a::123", 0)
+	assert.ErrorContains(t, err, "lease duration must be positive")
+}
+
+func TestLeaseExpiryAndRenew(t *testing.T) {
+	lease, err := NewLease("host-a", "host-a::123", time.Minute)
+	assert.NoError(t, err)
+
+	assert.False(t, lease.IsExpired(lease.AcquiredAt))
+	assert.True(t, lease.IsExpired(lease.ExpiresAt))
+	assert.True(t, lease.IsExpired(lease.AcquiredAt.Add(2*time.Minute)))
+
+	later := lease.AcquiredAt.Add(50 * time.Second)
+	lease.Renew(later, time.Minute)
+	assert.Equal(t, later.Add(time.Minute), lease.ExpiresAt)
+	assert.False(t, lease.IsExpired(lease.AcquiredAt.Add(100*time.Second)))
+}
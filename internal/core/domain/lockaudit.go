@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// LockAuditRecord describes a manually broken lock
+// Records are appended to the remote audit log as JSON lines
+type LockAuditRecord struct {
+	LockID       string    `json:"lock_id"`   // lock identifier that was broken
+	Holder       string    `json:"holder"`    // hostname that held the lock
+	LockedAt     time.Time `json:"locked_at"` // zero if the lock ID could not be parsed
+	BrokenBy     string    `json:"broken_by"` // hostname that broke the lock
+	BrokenAt     time.Time `json:"broken_at"`
+	LocalCleared bool      `json:"local_cleared"` // true if the local manifest of the holder was unlocked too
+}
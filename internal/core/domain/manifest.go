@@ -1,23 +1,33 @@
 package domain
 
 import (
+	"fmt"
+	"path"
 	"ritual/internal/config"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Manifest represents the central manifest tracking instance/worlds versions, locks, and metadata
 type Manifest struct {
-	ManifestVersion string    `json:"manifest_version"`
-	RitualVersion   string    `json:"ritual_version"`
-	LockedBy        string    `json:"locked_by"` // {hostname}::{nanosecond timestamp}, or empty string if not locked
-	InstanceVersion string    `json:"instance_version"`
-	StartScript     string    `json:"start_script"` // path to bat file that starts the server (relative to ritual root)
-	WorldDirs       []string  `json:"world_dirs"`   // directories to archive (relative to instance dir)
-	Backups         []World   `json:"backups"`      // queue of latest backups
-	UpdatedAt       time.Time `json:"updated_at"`
-	MinRAMMB        int       `json:"min_ram_mb"`       // minimum free RAM in MB required to run (0 = use config default)
-	MinDiskMB       int       `json:"min_disk_mb"`      // minimum free disk space in MB required (0 = use config default)
-	MinJavaVersion  int       `json:"min_java_version"` // minimum Java version required (0 = use config default)
+	ManifestVersion  string           `json:"manifest_version"`
+	RitualVersion    string           `json:"ritual_version"`
+	LockedBy         string           `json:"locked_by"` // {hostname}::{nanosecond timestamp}, or empty string if not locked
+	InstanceVersion  string           `json:"instance_version"`
+	InstanceChecksum string           `json:"instance_checksum,omitempty"` // SHA-256 hex of instance archive or index, verified on download when set
+	InstanceIndex    string           `json:"instance_index,omitempty"`    // key of the published instance file index (empty = full instance archive)
+	ProtectedPaths   []string         `json:"protected_paths,omitempty"`   // extra instance paths kept by delta updates (relative to instance dir)
+	StartScript      string           `json:"start_script"`                // path to bat file that starts the server (relative to ritual root)
+	WorldDirs        []string         `json:"world_dirs"`                  // directories to archive (relative to instance dir)
+	BackupMode       string           `json:"backup_mode,omitempty"`       // "archive" or "chunked" (empty = archive)
+	Backups          []World          `json:"backups"`                     // queue of latest backups
+	Retention        *RetentionPolicy `json:"retention,omitempty"`         // backups kept by retention (nil = keep the newest config.R2MaxBackups/LocalMaxBackups)
+	UpdatedAt        time.Time        `json:"updated_at"`
+	MinRAMMB         int              `json:"min_ram_mb"`       // minimum free RAM in MB required to run (0 = use config default)
+	MinDiskMB        int              `json:"min_disk_mb"`      // minimum free disk space in MB required (0 = use config default)
+	MinJavaVersion   int              `json:"min_java_version"` // minimum Java version required (0 = use config default)
 }
 
 // IsLocked returns true if the manifest is currently locked
@@ -37,13 +47,28 @@ func (m *Manifest) Unlock() {
 	m.UpdatedAt = time.Now()
 }
 
+// ParseLockID splits a lock identifier into the holder hostname and lock time
+func ParseLockID(lockID string) (string, time.Time, error) {
+	idx := strings.LastIndex(lockID, config.LockIDSeparator)
+	if idx <= 0 {
+		return "", time.Time{}, fmt.Errorf("invalid lock ID %q: missing hostname or separator", lockID)
+	}
+
+	nanos, err := strconv.ParseInt(lockID[idx+len(config.LockIDSeparator):], 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid lock ID %q: %w", lockID, err)
+	}
+
+	return lockID[:idx], time.Unix(0, nanos), nil
+}
+
 // AddWorld adds a new world to the stored worlds queue
 func (m *Manifest) AddWorld(world World) {
 	m.Backups = append(m.Backups, world)
 	m.UpdatedAt = time.Now()
 }
 
-// GetLatestWorld returns the most recently created world
+// GetLatestWorld returns the current world: the most recently created or restored one
 func (m *Manifest) GetLatestWorld() *World {
 	if len(m.Backups) == 0 {
 		return nil
@@ -51,13 +76,29 @@ func (m *Manifest) GetLatestWorld() *World {
 
 	var latest *World
 	for i := range m.Backups {
-		if latest == nil || m.Backups[i].CreatedAt.After(latest.CreatedAt) {
+		if latest == nil || m.Backups[i].CurrentSince().After(latest.CurrentSince()) {
 			latest = &m.Backups[i]
 		}
 	}
 	return latest
 }
 
+// FindBackup returns the backup entry matching ref, or nil
+// ref is the backup URI, its file name, or the timestamp the file name starts with
+func (m *Manifest) FindBackup(ref string) *World {
+	if ref == "" {
+		return nil
+	}
+	for i := range m.Backups {
+		name := path.Base(m.Backups[i].URI)
+		stamp, _, _ := strings.Cut(name, ".")
+		if m.Backups[i].URI == ref || name == ref || stamp == ref {
+			return &m.Backups[i]
+		}
+	}
+	return nil
+}
+
 // Clone creates a deep copy of the manifest
 func (m *Manifest) Clone() *Manifest {
 	if m == nil {
@@ -65,24 +106,58 @@ func (m *Manifest) Clone() *Manifest {
 	}
 
 	clone := &Manifest{
-		ManifestVersion: m.ManifestVersion,
-		RitualVersion:   m.RitualVersion,
-		LockedBy:        m.LockedBy,
-		InstanceVersion: m.InstanceVersion,
-		StartScript:     m.StartScript,
-		WorldDirs:       make([]string, len(m.WorldDirs)),
-		Backups:    make([]World, len(m.Backups)),
-		UpdatedAt:       time.Now(),
-		MinRAMMB:        m.MinRAMMB,
-		MinDiskMB:       m.MinDiskMB,
-		MinJavaVersion:  m.MinJavaVersion,
+		ManifestVersion:  m.ManifestVersion,
+		RitualVersion:    m.RitualVersion,
+		LockedBy:         m.LockedBy,
+		InstanceVersion:  m.InstanceVersion,
+		InstanceChecksum: m.InstanceChecksum,
+		InstanceIndex:    m.InstanceIndex,
+		ProtectedPaths:   append([]string(nil), m.ProtectedPaths...),
+		StartScript:      m.StartScript,
+		WorldDirs:        make([]string, len(m.WorldDirs)),
+		BackupMode:       m.BackupMode,
+		Backups:          make([]World, len(m.Backups)),
+		UpdatedAt:        time.Now(),
+		MinRAMMB:         m.MinRAMMB,
+		MinDiskMB:        m.MinDiskMB,
+		MinJavaVersion:   m.MinJavaVersion,
 	}
 
 	copy(clone.WorldDirs, m.WorldDirs)
 	copy(clone.Backups, m.Backups)
+	for i := range clone.Backups {
+		clone.Backups[i].Replicas = slices.Clone(m.Backups[i].Replicas)
+		clone.Backups[i].Labels = slices.Clone(m.Backups[i].Labels)
+		clone.Backups[i].WorldDirs = slices.Clone(m.Backups[i].WorldDirs)
+		clone.Backups[i].Players = slices.Clone(m.Backups[i].Players)
+	}
+	if m.Retention != nil {
+		policy := *m.Retention
+		clone.Retention = &policy
+	}
 	return clone
 }
 
+// RemoveIntermediateWorlds removes unpinned checkpoint entries superseded by a final backup
+// Returns the removed entries; their archives are left for retention to delete as dangling
+func (m *Manifest) RemoveIntermediateWorlds() []World {
+	var removed []World
+	kept := m.Backups[:0]
+	for _, world := range m.Backups {
+		if world.Intermediate && !world.Pinned {
+			removed = append(removed, world)
+			continue
+		}
+		kept = append(kept, world)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	m.Backups = kept
+	m.UpdatedAt = time.Now()
+	return removed
+}
+
 // RemoveOldestWorlds removes the oldest worlds from the manifest, keeping only the specified count
 func (m *Manifest) RemoveOldestWorlds(maxCount int) []World {
 	if maxCount <= 0 {
@@ -139,6 +214,20 @@ func (m *Manifest) GetMinJavaVersion() int {
 	return m.MinJavaVersion
 }
 
+// GetRetentionPolicy returns the manifest retention policy
+// Without one, the newest defaultLatest backups are kept
+func (m *Manifest) GetRetentionPolicy(defaultLatest int) RetentionPolicy {
+	if m == nil || m.Retention == nil {
+		return RetentionPolicy{Latest: defaultLatest}
+	}
+	return *m.Retention
+}
+
+// UsesChunkedBackups reports whether new backups are stored as deduplicated chunks
+func (m *Manifest) UsesChunkedBackups() bool {
+	return m.BackupMode == config.BackupModeChunked
+}
+
 // ApplyDefaults sets default values for fields that are zero
 func (m *Manifest) ApplyDefaults() {
 	if m.MinRAMMB <= 0 {
@@ -128,6 +128,16 @@ func TestManifest_GetLatestWorld(t *testing.T) {
 			},
 			expected: &World{URI: "world3", CreatedAt: time.Now()},
 		},
+		{
+			name: "restored older world",
+			manifest: Manifest{
+				Backups: []World{
+					{URI: "world2", CreatedAt: time.Now().Add(-time.Hour)},
+					{URI: "world1", CreatedAt: time.Now().Add(-2 * time.Hour), RestoredAt: time.Now()},
+				},
+			},
+			expected: &World{URI: "world1"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,3 +154,59 @@ func TestManifest_GetLatestWorld(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLockID(t *testing.T) {
+	lockedAt := time.Unix(1700000000, 123456789)
+
+	host, parsed, err := ParseLockID("PC123::1700000000123456789")
+	assert.NoError(t, err)
+	assert.Equal(t, "PC123", host)
+	assert.True(t, lockedAt.Equal(parsed))
+
+	host, _, err = ParseLockID("host::with::sep::1700000000123456789")
+	assert.NoError(t, err)
+	assert.Equal(t, "host::with::sep", host)
+
+	for _, invalid := range []string{"", "PC123", "::1700000000", "PC123::abc"} {
+		_, _, err := ParseLockID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestManifest_FindBackup(t *testing.T) {
+	manifest := &Manifest{Backups: []World{
+		{URI: "worlds/20240101120000.tar.gz"},
+		{URI: "worlds/20240102120000.index.json"},
+	}}
+
+	assert.Equal(t, "worlds/20240101120000.tar.gz", manifest.FindBackup("worlds/20240101120000.tar.gz").URI)
+	assert.Equal(t, "worlds/20240101120000.tar.gz", manifest.FindBackup("20240101120000.tar.gz").URI)
+	assert.Equal(t, "worlds/20240102120000.index.json", manifest.FindBackup("20240102120000").URI)
+	assert.Nil(t, manifest.FindBackup("20240103120000"))
+	assert.Nil(t, manifest.FindBackup(""))
+
+	// The entry is returned by reference
+	manifest.FindBackup("20240101120000").Pinned = true
+	assert.True(t, manifest.Backups[0].Pinned)
+
+	// Clones do not share labels
+	manifest.Backups[0].Labels = []string{"season-1"}
+	clone := manifest.Clone()
+	clone.Backups[0].Labels[0] = "changed"
+	assert.Equal(t, "season-1", manifest.Backups[0].Labels[0])
+}
+
+func TestManifest_RemoveIntermediateWorlds(t *testing.T) {
+	manifest := &Manifest{Backups: []World{
+		{URI: "worlds/1.tar"},
+		{URI: "worlds/2.tar", Intermediate: true},
+		{URI: "worlds/3.tar", Intermediate: true, Pinned: true},
+		{URI: "worlds/4.tar", Intermediate: true},
+	}}
+
+	removed := manifest.RemoveIntermediateWorlds()
+	assert.Equal(t, []World{{URI: "worlds/2.tar", Intermediate: true}, {URI: "worlds/4.tar", Intermediate: true}}, removed)
+	assert.Equal(t, []World{{URI: "worlds/1.tar"}, {URI: "worlds/3.tar", Intermediate: true, Pinned: true}}, manifest.Backups, "pinned checkpoints are kept")
+
+	assert.Nil(t, manifest.RemoveIntermediateWorlds())
+}
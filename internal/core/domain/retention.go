@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Retention rule names reported for kept backups
+const (
+	RetainLatest  = "latest"
+	RetainDaily   = "daily"
+	RetainWeekly  = "weekly"
+	RetainMonthly = "monthly"
+	RetainPinned  = "pinned" // pinned backups are kept and not counted by any rule
+)
+
+// RetentionPolicy is a grandfather-father-son policy for world backups
+// Each periodic rule keeps the newest backup of its most recent periods that contain a backup,
+// so sessions days apart still fill every slot
+type RetentionPolicy struct {
+	Latest  int `json:"latest"`            // newest backups kept regardless of age
+	Daily   int `json:"daily,omitempty"`   // days kept with one backup each
+	Weekly  int `json:"weekly,omitempty"`  // ISO weeks kept with one backup each
+	Monthly int `json:"monthly,omitempty"` // calendar months kept with one backup each
+}
+
+// retentionRule groups backups into periods and keeps the newest of the first count periods
+type retentionRule struct {
+	name   string
+	count  int
+	period func(t time.Time) string
+}
+
+// Keep returns the reasons each backup is kept, indexed like createdAt
+// A backup no rule keeps has no reasons; the newest backup is always kept
+// Backups created at the same time are ranked in the order given
+func (p RetentionPolicy) Keep(createdAt []time.Time) [][]string {
+	reasons := make([][]string, len(createdAt))
+	if len(createdAt) == 0 {
+		return reasons
+	}
+
+	order := make([]int, len(createdAt))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return createdAt[order[a]].After(createdAt[order[b]])
+	})
+
+	for rank, i := range order {
+		if rank < max(p.Latest, 1) {
+			reasons[i] = append(reasons[i], RetainLatest)
+		}
+	}
+
+	rules := []retentionRule{
+		{name: RetainDaily, count: p.Daily, period: func(t time.Time) string { return t.Format("2006-01-02") }},
+		{name: RetainWeekly, count: p.Weekly, period: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%04d-W%02d", year, week)
+		}},
+		{name: RetainMonthly, count: p.Monthly, period: func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		kept := 0
+		last := ""
+		for _, i := range order {
+			if kept >= rule.count {
+				break
+			}
+			period := rule.period(createdAt[i].Local())
+			if period == last {
+				continue
+			}
+			last = period
+			kept++
+			reasons[i] = append(reasons[i], rule.name+" "+period)
+		}
+	}
+
+	return reasons
+}
+
+// String describes the policy for logs
+func (p RetentionPolicy) String() string {
+	return fmt.Sprintf("latest=%d daily=%d weekly=%d monthly=%d", max(p.Latest, 1), p.Daily, p.Weekly, p.Monthly)
+}
+
+// Reasons a retention plan deletes an object
+const (
+	RetentionDangling     = "dangling"     // stored backup missing from the manifest
+	RetentionOverLimit    = "over limit"   // no retention rule keeps the backup
+	RetentionUnreferenced = "unreferenced" // chunk no retained backup or instance references
+)
+
+// RetentionItem is one stored object evaluated by a retention plan
+type RetentionItem struct {
+	Key     string   `json:"key"`
+	Size    int64    `json:"size"` // bytes, 0 if unknown
+	Delete  bool     `json:"delete"`
+	Reasons []string `json:"reasons"` // why it is deleted, or the rules that keep it
+}
+
+// RetentionPlan lists what a retention service keeps and deletes
+// Kept chunks are not listed
+type RetentionPlan struct {
+	Scope  string          `json:"scope"`  // storage the plan covers, e.g. "local" or "remote"
+	Policy string          `json:"policy"` // description of the evaluated policy
+	Items  []RetentionItem `json:"items"`
+}
+
+// Deletions returns the items the plan deletes
+func (p *RetentionPlan) Deletions() []RetentionItem {
+	var deletions []RetentionItem
+	for _, item := range p.Items {
+		if item.Delete {
+			deletions = append(deletions, item)
+		}
+	}
+	return deletions
+}
+
+// DeletedKeys returns the set of keys the plan deletes
+func (p *RetentionPlan) DeletedKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, item := range p.Deletions() {
+		keys[item.Key] = true
+	}
+	return keys
+}
+
+// ReclaimedBytes returns the bytes freed by the deletions, counting known sizes only
+func (p *RetentionPlan) ReclaimedBytes() int64 {
+	var total int64
+	for _, item := range p.Deletions() {
+		total += item.Size
+	}
+	return total
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kept returns the indexes that have at least one reason
+func kept(reasons [][]string) []int {
+	var indexes []int
+	for i, r := range reasons {
+		if len(r) > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func TestRetentionPolicy_Keep(t *testing.T) {
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.Local)
+	}
+
+	t.Run("latest only", func(t *testing.T) {
+		times := []time.Time{at(1, 1, 0), at(1, 3, 0), at(1, 2, 0)}
+		reasons := RetentionPolicy{Latest: 2}.Keep(times)
+		assert.Equal(t, []int{1, 2}, kept(reasons))
+		assert.Equal(t, []string{RetainLatest}, reasons[1])
+	})
+
+	t.Run("newest is always kept", func(t *testing.T) {
+		times := []time.Time{at(1, 1, 0), at(1, 2, 0)}
+		assert.Equal(t, []int{1}, kept(RetentionPolicy{}.Keep(times)))
+	})
+
+	t.Run("daily keeps the newest backup of each day", func(t *testing.T) {
+		// Two sessions on Jan 5, one on Jan 3, one on Jan 1
+		times := []time.Time{at(1, 5, 20), at(1, 5, 10), at(1, 3, 12), at(1, 1, 12)}
+		reasons := RetentionPolicy{Latest: 1, Daily: 2}.Keep(times)
+		assert.Equal(t, []int{0, 2}, kept(reasons))
+		assert.Equal(t, []string{RetainLatest, "daily 2024-01-05"}, reasons[0])
+		assert.Equal(t, []string{"daily 2024-01-03"}, reasons[2])
+	})
+
+	t.Run("weekly and monthly reach further back", func(t *testing.T) {
+		times := []time.Time{
+			at(3, 20, 12), // week 12
+			at(3, 18, 12), // week 12
+			at(3, 12, 12), // week 11
+			at(2, 10, 12), // week 6, February
+			at(1, 15, 12), // January
+		}
+		reasons := RetentionPolicy{Latest: 1, Weekly: 2, Monthly: 3}.Keep(times)
+		assert.Equal(t, []int{0, 2, 3, 4}, kept(reasons))
+		assert.Equal(t, []string{RetainLatest, "weekly 2024-W12", "monthly 2024-03"}, reasons[0])
+		assert.Equal(t, []string{"weekly 2024-W11"}, reasons[2])
+		assert.Equal(t, []string{"monthly 2024-02"}, reasons[3])
+		assert.Equal(t, []string{"monthly 2024-01"}, reasons[4])
+	})
+
+	t.Run("ties keep the given order", func(t *testing.T) {
+		same := at(1, 1, 0)
+		assert.Equal(t, []int{0}, kept(RetentionPolicy{Latest: 1}.Keep([]time.Time{same, same})))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, RetentionPolicy{Latest: 1}.Keep(nil))
+	})
+}
+
+func TestManifest_GetRetentionPolicy(t *testing.T) {
+	var missing *Manifest
+	assert.Equal(t, RetentionPolicy{Latest: 3}, missing.GetRetentionPolicy(3))
+	assert.Equal(t, RetentionPolicy{Latest: 3}, (&Manifest{}).GetRetentionPolicy(3))
+
+	policy := &RetentionPolicy{Latest: 1, Daily: 7, Weekly: 4, Monthly: 6}
+	manifest := &Manifest{Retention: policy}
+	assert.Equal(t, *policy, manifest.GetRetentionPolicy(3))
+
+	clone := manifest.Clone()
+	clone.Retention.Daily = 1
+	assert.Equal(t, 7, manifest.Retention.Daily, "clone must not share the policy")
+}
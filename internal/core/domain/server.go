@@ -4,14 +4,18 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+
+	"ritual/internal/config"
 )
 
 // Server represents a Minecraft server configuration
 type Server struct {
-	Address string `json:"address"`
-	IP      string `json:"ip"`
-	Port    int    `json:"port"`
-	Memory  int    `json:"memory"`
+	Address      string `json:"address"`
+	IP           string `json:"ip"`
+	Port         int    `json:"port"`
+	Memory       int    `json:"memory"`
+	RconPort     int    `json:"rcon_port,omitempty"` // 0 = config.RconPortOffset from the game port
+	RconPassword string `json:"-"`                   // Generated by the server runner for each run
 }
 
 // NewServer creates a new Server instance with address parsing
@@ -45,6 +49,47 @@ func NewServer(address string, memory int) (*Server, error) {
 	return server, nil
 }
 
+// RconListenPort returns the port the server listens on for RCON
+// Defaults to config.RconPortOffset above the game port, or below it near the top of the port range
+func (s *Server) RconListenPort() int {
+	if s.RconPort > 0 {
+		return s.RconPort
+	}
+	if s.Port+config.RconPortOffset > 65535 {
+		return s.Port - config.RconPortOffset
+	}
+	return s.Port + config.RconPortOffset
+}
+
+// RconAddress returns the address RCON clients connect to
+// A server bound to every interface is reached over loopback
+func (s *Server) RconAddress() string {
+	return net.JoinHostPort(s.dialHost(), strconv.Itoa(s.RconListenPort()))
+}
+
+// StatusAddress returns the game address status probes connect to
+// A server bound to every interface is reached over loopback
+func (s *Server) StatusAddress() string {
+	return net.JoinHostPort(s.dialHost(), strconv.Itoa(s.Port))
+}
+
+// dialHost returns the host local clients use to reach the server
+func (s *Server) dialHost() string {
+	if ip := net.ParseIP(s.IP); ip == nil || ip.IsUnspecified() {
+		return "127.0.0.1"
+	}
+	return s.IP
+}
+
+// ServerStatus is what a running server reports to a Server List Ping
+type ServerStatus struct {
+	Version       string // Version name, e.g. "1.21.1" or a modded server's brand
+	Protocol      int    // Protocol version number
+	OnlinePlayers int
+	MaxPlayers    int
+	MOTD          string // Message of the day with formatting codes removed
+}
+
 // parseAddress extracts IP and port from address string
 func parseAddress(address string) (string, int, error) {
 	if address == "" {
@@ -85,3 +85,24 @@ func TestNewServer(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_Rcon(t *testing.T) {
+	server := &Server{IP: "192.168.1.50", Port: 25565}
+	assert.Equal(t, 25575, server.RconListenPort())
+	assert.Equal(t, "192.168.1.50:25575", server.RconAddress())
+
+	server = &Server{IP: "0.0.0.0", Port: 65530}
+	assert.Equal(t, 65520, server.RconListenPort(), "stays within the port range")
+	assert.Equal(t, "127.0.0.1:65520", server.RconAddress(), "a server on every interface is reached over loopback")
+
+	server = &Server{IP: "::1", Port: 25565, RconPort: 30000}
+	assert.Equal(t, "[::1]:30000", server.RconAddress())
+}
+
+func TestServer_StatusAddress(t *testing.T) {
+	server := &Server{IP: "192.168.1.50", Port: 25565}
+	assert.Equal(t, "192.168.1.50:25565", server.StatusAddress())
+
+	server = &Server{IP: "0.0.0.0", Port: 25570}
+	assert.Equal(t, "127.0.0.1:25570", server.StatusAddress(), "a server on every interface is reached over loopback")
+}
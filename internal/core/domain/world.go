@@ -3,13 +3,37 @@ package domain
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"time"
+
+	"ritual/internal/config"
 )
 
 // World represents a world data entity
 type World struct {
 	URI       string    `json:"uri"`
 	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size,omitempty"`     // archive size in bytes (0 for entries recorded before checksums)
+	Checksum  string    `json:"checksum,omitempty"` // SHA-256 hex of the archive object
+	KeyID     string    `json:"key_id,omitempty"`   // ID of the key that encrypted the archive, empty if unencrypted
+	Replicas  []string  `json:"replicas,omitempty"` // Targets holding a verified copy; empty = primary only
+	Pinned    bool      `json:"pinned,omitempty"`   // Never deleted by retention
+	Labels    []string  `json:"labels,omitempty"`   // Short tags, e.g. "end-of-season-1"
+	Note      string    `json:"note,omitempty"`     // Free-form description of the session
+
+	// Session metadata recorded at backup time; empty for entries recorded before it was collected
+	Host             string   `json:"host,omitempty"`              // Hostname of the machine that ran the session
+	RitualVersion    string   `json:"ritual_version,omitempty"`    // Ritual version that made the backup
+	SessionSeconds   int64    `json:"session_seconds,omitempty"`   // How long the server ran
+	WorldDirs        []string `json:"world_dirs,omitempty"`        // World directories included in the archive
+	Players          []string `json:"players,omitempty"`           // Players who joined during the session
+	MinecraftVersion string   `json:"minecraft_version,omitempty"` // Detected from the server log
+
+	// Intermediate marks a checkpoint taken while the server ran; the session's final backup supersedes it
+	Intermediate bool `json:"intermediate,omitempty"`
+
+	// RestoredAt is when the backup was last restored as the current world; zero if never restored
+	RestoredAt time.Time `json:"restored_at,omitzero"`
 }
 
 // NewWorld creates a new World instance with validation
@@ -23,3 +47,67 @@ func NewWorld(uri string) (*World, error) {
 		CreatedAt: time.Now(),
 	}, nil
 }
+
+// HeldBy reports whether the named replication target holds a verified copy
+// Entries recorded before replication are held by the primary remote only
+func (w World) HeldBy(target string) bool {
+	if len(w.Replicas) == 0 {
+		return target == config.PrimaryReplica
+	}
+	return slices.Contains(w.Replicas, target)
+}
+
+// Equal reports whether both entries describe the same backup
+// Annotations (pin, labels, note) and session metadata do not change which backup an entry describes
+func (w World) Equal(other World) bool {
+	return w.URI == other.URI &&
+		w.CreatedAt.Equal(other.CreatedAt) &&
+		w.Size == other.Size &&
+		w.Checksum == other.Checksum &&
+		w.KeyID == other.KeyID &&
+		slices.Equal(w.Replicas, other.Replicas)
+}
+
+// CurrentSince returns when the backup became the current world: its restore time, or its creation time
+func (w World) CurrentSince() time.Time {
+	if w.RestoredAt.After(w.CreatedAt) {
+		return w.RestoredAt
+	}
+	return w.CreatedAt
+}
+
+// AddLabel adds label unless the entry already has it
+// Returns false if the label was present
+func (w *World) AddLabel(label string) bool {
+	if slices.Contains(w.Labels, label) {
+		return false
+	}
+	w.Labels = append(w.Labels, label)
+	return true
+}
+
+// RemoveLabel removes label from the entry
+// Returns false if the label was not present
+func (w *World) RemoveLabel(label string) bool {
+	index := slices.Index(w.Labels, label)
+	if index < 0 {
+		return false
+	}
+	w.Labels = slices.Delete(w.Labels, index, index+1)
+	if len(w.Labels) == 0 {
+		w.Labels = nil
+	}
+	return true
+}
+
+// SessionDuration returns how long the server ran before the backup
+func (w World) SessionDuration() time.Duration {
+	return time.Duration(w.SessionSeconds) * time.Second
+}
+
+// Annotate copies the pin, labels and note of other onto the entry
+func (w *World) Annotate(other World) {
+	w.Pinned = other.Pinned
+	w.Labels = slices.Clone(other.Labels)
+	w.Note = other.Note
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"ritual/internal/config"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,3 +38,69 @@ func TestNewWorldEmptyURI(t *testing.T) {
 	assert.Error(t, err, "Expected error for empty URI")
 	assert.Nil(t, world, "Expected world to be nil for empty URI")
 }
+
+func TestWorld_HeldBy(t *testing.T) {
+	legacy := World{URI: "worlds/1.tar"}
+	assert.True(t, legacy.HeldBy(config.PrimaryReplica), "entries without replicas are held by the primary remote")
+	assert.False(t, legacy.HeldBy("nas"))
+
+	replicated := World{URI: "worlds/2.tar", Replicas: []string{config.PrimaryReplica, "nas"}}
+	assert.True(t, replicated.HeldBy("nas"))
+	assert.False(t, replicated.HeldBy("offsite"))
+}
+
+func TestWorld_Equal(t *testing.T) {
+	createdAt := time.Now()
+	world := World{URI: "worlds/1.tar", CreatedAt: createdAt, Size: 10, Checksum: "abc", Replicas: []string{config.PrimaryReplica}}
+
+	same := world
+	same.CreatedAt = createdAt.Round(0)
+	assert.True(t, world.Equal(same))
+
+	other := world
+	other.Replicas = []string{config.PrimaryReplica, "nas"}
+	assert.False(t, world.Equal(other))
+
+	other = world
+	other.Checksum = "def"
+	assert.False(t, world.Equal(other))
+}
+
+func TestWorld_Labels(t *testing.T) {
+	world := World{URI: "worlds/1.tar"}
+
+	assert.True(t, world.AddLabel("season-1"))
+	assert.False(t, world.AddLabel("season-1"))
+	assert.True(t, world.AddLabel("dragon"))
+	assert.Equal(t, []string{"season-1", "dragon"}, world.Labels)
+
+	assert.True(t, world.RemoveLabel("season-1"))
+	assert.False(t, world.RemoveLabel("season-1"))
+	assert.True(t, world.RemoveLabel("dragon"))
+	assert.Nil(t, world.Labels)
+}
+
+func TestWorld_Annotate(t *testing.T) {
+	source := World{URI: "worlds/1.tar", Pinned: true, Labels: []string{"dragon"}, Note: "before the fight"}
+	target := World{URI: "worlds/1.tar", Size: 10}
+
+	target.Annotate(source)
+	assert.True(t, target.Pinned)
+	assert.Equal(t, []string{"dragon"}, target.Labels)
+	assert.Equal(t, "before the fight", target.Note)
+	assert.Equal(t, int64(10), target.Size)
+
+	target.Labels[0] = "changed"
+	assert.Equal(t, "dragon", source.Labels[0], "labels are copied")
+
+	// Annotations do not change which backup an entry describes
+	assert.True(t, target.Equal(World{URI: "worlds/1.tar", Size: 10}))
+}
+
+func TestWorld_SessionMetadata(t *testing.T) {
+	world := World{URI: "worlds/1.tar", SessionSeconds: 5400, Host: "server-pc", Players: []string{"owl"}}
+	assert.Equal(t, 90*time.Minute, world.SessionDuration())
+
+	// Session metadata does not change which backup an entry describes
+	assert.True(t, world.Equal(World{URI: "worlds/1.tar"}))
+}
@@ -1,5 +1,7 @@
 package ports
 
+import "time"
+
 // Event is the sealed interface for all event types
 type Event interface {
 	sealed()
@@ -34,6 +36,7 @@ type PromptEvent struct {
 	Prompt       string
 	DefaultValue string
 	ResponseChan chan<- any
+	Timeout      time.Duration // Optional: answer with DefaultValue once no input arrived in time. 0 = wait for input
 }
 
 func (StartEvent) sealed()  {}
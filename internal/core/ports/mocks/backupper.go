@@ -2,13 +2,15 @@ package mocks
 
 import (
 	"context"
+	"time"
 
+	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 )
 
 // MockBackupperService is a mock implementation of BackupperService for testing
 type MockBackupperService struct {
-	RunFunc func(ctx context.Context) (string, error)
+	RunFunc func(ctx context.Context) (*domain.World, error)
 }
 
 // NewMockBackupperService creates a new mock backupper service
@@ -17,9 +19,9 @@ func NewMockBackupperService() ports.BackupperService {
 }
 
 // Run executes the backup orchestration process
-func (m *MockBackupperService) Run(ctx context.Context) (string, error) {
+func (m *MockBackupperService) Run(ctx context.Context) (*domain.World, error) {
 	if m.RunFunc != nil {
 		return m.RunFunc(ctx)
 	}
-	return "mock-archive.zip", nil
+	return &domain.World{URI: "mock-archive.zip", CreatedAt: time.Now()}, nil
 }
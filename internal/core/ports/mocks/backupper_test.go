@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"testing"
+
+	"ritual/internal/core/domain"
 )
 
 func TestMockBackupperService_Run_Success(t *testing.T) {
@@ -21,8 +23,8 @@ func TestMockBackupperService_Run_WithFunction(t *testing.T) {
 	ctx := context.Background()
 	expectedError := errors.New("backup failed")
 
-	mock.RunFunc = func(ctx context.Context) (string, error) {
-		return "", expectedError
+	mock.RunFunc = func(ctx context.Context) (*domain.World, error) {
+		return nil, expectedError
 	}
 
 	_, err := mock.Run(ctx)
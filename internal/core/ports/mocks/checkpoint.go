@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"ritual/internal/core/ports"
+)
+
+// MockCheckpointService is a mock implementation of CheckpointService for testing
+type MockCheckpointService struct {
+	RunFunc func(ctx context.Context, lockID string)
+}
+
+// Compile-time check to ensure MockCheckpointService implements ports.CheckpointService
+var _ ports.CheckpointService = (*MockCheckpointService)(nil)
+
+// NewMockCheckpointService creates a new mock checkpoint service
+func NewMockCheckpointService() *MockCheckpointService {
+	return &MockCheckpointService{}
+}
+
+// Run takes checkpoints until ctx is cancelled
+// Without RunFunc it waits for ctx to be cancelled
+func (m *MockCheckpointService) Run(ctx context.Context, lockID string) {
+	if m.RunFunc != nil {
+		m.RunFunc(ctx, lockID)
+		return
+	}
+	<-ctx.Done()
+}
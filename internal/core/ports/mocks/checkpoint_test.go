@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMockCheckpointService_Run_WaitsForCancel(t *testing.T) {
+	mock := NewMockCheckpointService()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mock.Run(ctx, "lock-id")
+}
+
+func TestMockCheckpointService_Run_WithFunction(t *testing.T) {
+	mock := NewMockCheckpointService()
+	var gotLockID string
+	mock.RunFunc = func(ctx context.Context, lockID string) {
+		gotLockID = lockID
+	}
+
+	mock.Run(context.Background(), "lock-id")
+	if gotLockID != "lock-id" {
+		t.Errorf("Run() lockID = %q, want %q", gotLockID, "lock-id")
+	}
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// MockHealthProbeService is a mock implementation of HealthProbeService for testing
+type MockHealthProbeService struct {
+	RunFunc func(ctx context.Context, server *domain.Server)
+}
+
+// Compile-time check to ensure MockHealthProbeService implements ports.HealthProbeService
+var _ ports.HealthProbeService = (*MockHealthProbeService)(nil)
+
+// NewMockHealthProbeService creates a new mock health probe service
+func NewMockHealthProbeService() *MockHealthProbeService {
+	return &MockHealthProbeService{}
+}
+
+// Run polls the server until ctx is cancelled
+// Without RunFunc it waits for ctx to be cancelled
+func (m *MockHealthProbeService) Run(ctx context.Context, server *domain.Server) {
+	if m.RunFunc != nil {
+		m.RunFunc(ctx, server)
+		return
+	}
+	<-ctx.Done()
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"ritual/internal/core/domain"
+)
+
+func TestMockHealthProbeService_Run_WaitsForCancel(t *testing.T) {
+	mock := NewMockHealthProbeService()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mock.Run(ctx, &domain.Server{IP: "127.0.0.1", Port: 25565})
+}
+
+func TestMockHealthProbeService_Run_WithFunction(t *testing.T) {
+	mock := NewMockHealthProbeService()
+	server := &domain.Server{IP: "127.0.0.1", Port: 25565}
+	var gotServer *domain.Server
+	mock.RunFunc = func(ctx context.Context, s *domain.Server) {
+		gotServer = s
+	}
+
+	mock.Run(context.Background(), server)
+	if gotServer != server {
+		t.Errorf("Run() server = %v, want %v", gotServer, server)
+	}
+}
@@ -12,6 +12,9 @@ type MockLibrarianService struct {
 	GetRemoteManifestFunc  func(ctx context.Context) (*domain.Manifest, error)
 	SaveLocalManifestFunc  func(ctx context.Context, manifest *domain.Manifest) error
 	SaveRemoteManifestFunc func(ctx context.Context, manifest *domain.Manifest) error
+	GetRemoteLeaseFunc     func(ctx context.Context) (*domain.Lease, error)
+	SaveRemoteLeaseFunc    func(ctx context.Context, lease *domain.Lease) error
+	DeleteRemoteLeaseFunc  func(ctx context.Context) error
 }
 
 // NewMockLibrarianService creates a new mock Librarian service
@@ -50,3 +53,28 @@ func (m *MockLibrarianService) SaveRemoteManifest(ctx context.Context, manifest
 	}
 	return nil
 }
+
+// GetRemoteLease retrieves the remote lock object
+// Returns ports.ErrNotFound when no func is set
+func (m *MockLibrarianService) GetRemoteLease(ctx context.Context) (*domain.Lease, error) {
+	if m.GetRemoteLeaseFunc != nil {
+		return m.GetRemoteLeaseFunc(ctx)
+	}
+	return nil, ports.ErrNotFound
+}
+
+// SaveRemoteLease stores the remote lock object
+func (m *MockLibrarianService) SaveRemoteLease(ctx context.Context, lease *domain.Lease) error {
+	if m.SaveRemoteLeaseFunc != nil {
+		return m.SaveRemoteLeaseFunc(ctx, lease)
+	}
+	return nil
+}
+
+// DeleteRemoteLease removes the remote lock object
+func (m *MockLibrarianService) DeleteRemoteLease(ctx context.Context) error {
+	if m.DeleteRemoteLeaseFunc != nil {
+		return m.DeleteRemoteLeaseFunc(ctx)
+	}
+	return nil
+}
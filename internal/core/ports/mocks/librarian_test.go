@@ -68,4 +68,39 @@ func TestMockLibrarianService(t *testing.T) {
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
+
+	_, err = librarian.GetRemoteLease(context.Background())
+	if err != ports.ErrNotFound {
+		t.Errorf("Expected ErrNotFound without a lease, got %v", err)
+	}
+
+	testLease := &domain.Lease{Owner: "host", SessionID: "host::1"}
+	mockLibrarian.GetRemoteLeaseFunc = func(ctx context.Context) (*domain.Lease, error) {
+		return testLease, nil
+	}
+
+	lease, err := librarian.GetRemoteLease(context.Background())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if lease.SessionID != testLease.SessionID {
+		t.Errorf("Expected session %s, got %s", testLease.SessionID, lease.SessionID)
+	}
+
+	mockLibrarian.SaveRemoteLeaseFunc = func(ctx context.Context, lease *domain.Lease) error {
+		if lease.SessionID != testLease.SessionID {
+			t.Errorf("Expected session %s, got %s", testLease.SessionID, lease.SessionID)
+		}
+		return nil
+	}
+
+	err = librarian.SaveRemoteLease(context.Background(), testLease)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	err = librarian.DeleteRemoteLease(context.Background())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
 }
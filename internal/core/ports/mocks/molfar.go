@@ -1,15 +1,16 @@
 package mocks
 
 import (
+	"context"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 )
 
 // MockMolfarService is a mock implementation of MolfarService for testing
 type MockMolfarService struct {
-	PrepareFunc func() error
-	RunFunc     func(server *domain.Server) error
-	ExitFunc    func() error
+	PrepareFunc func(ctx context.Context) error
+	RunFunc     func(ctx context.Context, server *domain.Server) error
+	ExitFunc    func(ctx context.Context) error
 }
 
 // NewMockMolfarService creates a new mock Molfar service
@@ -18,25 +19,25 @@ func NewMockMolfarService() ports.MolfarService {
 }
 
 // Prepare initializes the environment and validates prerequisites
-func (m *MockMolfarService) Prepare() error {
+func (m *MockMolfarService) Prepare(ctx context.Context) error {
 	if m.PrepareFunc != nil {
-		return m.PrepareFunc()
+		return m.PrepareFunc(ctx)
 	}
 	return nil
 }
 
 // Run executes the main server orchestration process
-func (m *MockMolfarService) Run(server *domain.Server) error {
+func (m *MockMolfarService) Run(ctx context.Context, server *domain.Server) error {
 	if m.RunFunc != nil {
-		return m.RunFunc(server)
+		return m.RunFunc(ctx, server)
 	}
 	return nil
 }
 
 // Exit gracefully shuts down the server and cleans up resources
-func (m *MockMolfarService) Exit() error {
+func (m *MockMolfarService) Exit(ctx context.Context) error {
 	if m.ExitFunc != nil {
-		return m.ExitFunc()
+		return m.ExitFunc(ctx)
 	}
 	return nil
 }
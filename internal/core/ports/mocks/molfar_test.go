@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 	"testing"
@@ -14,17 +15,18 @@ func TestMockMolfarService(t *testing.T) {
 		t.Error("MockMolfarService does not implement MolfarService interface")
 	}
 
+	ctx := context.Background()
 	mockMolfar := mock.(*MockMolfarService)
-	mockMolfar.PrepareFunc = func() error {
+	mockMolfar.PrepareFunc = func(ctx context.Context) error {
 		return nil
 	}
 
-	err := molfar.Prepare()
+	err := molfar.Prepare(ctx)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	mockMolfar.RunFunc = func(server *domain.Server) error {
+	mockMolfar.RunFunc = func(ctx context.Context, server *domain.Server) error {
 		return nil
 	}
 
@@ -33,16 +35,16 @@ func TestMockMolfarService(t *testing.T) {
 		t.Errorf("Failed to create server: %v", err)
 	}
 
-	err = molfar.Run(server)
+	err = molfar.Run(ctx, server)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	mockMolfar.ExitFunc = func() error {
+	mockMolfar.ExitFunc = func(ctx context.Context) error {
 		return nil
 	}
 
-	err = molfar.Exit()
+	err = molfar.Exit(ctx)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
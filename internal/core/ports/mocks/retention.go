@@ -8,6 +8,7 @@ import (
 
 // MockRetentionService is a mock implementation of RetentionService for testing
 type MockRetentionService struct {
+	PlanFunc  func(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error)
 	ApplyFunc func(ctx context.Context, manifest *domain.Manifest) error
 }
 
@@ -19,6 +20,14 @@ func NewMockRetentionService() *MockRetentionService {
 	return &MockRetentionService{}
 }
 
+// Plan lists what Apply would delete
+func (m *MockRetentionService) Plan(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error) {
+	if m.PlanFunc != nil {
+		return m.PlanFunc(ctx, manifest)
+	}
+	return &domain.RetentionPlan{}, nil
+}
+
 // Apply removes old backups exceeding the retention limit
 func (m *MockRetentionService) Apply(ctx context.Context, manifest *domain.Manifest) error {
 	if m.ApplyFunc != nil {
@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockServerConsole is a mock implementation of ServerConsole interface
+type MockServerConsole struct {
+	mock.Mock
+}
+
+// NewMockServerConsole creates a new MockServerConsole instance
+func NewMockServerConsole() *MockServerConsole {
+	return &MockServerConsole{}
+}
+
+// SendCommand mocks the SendCommand method
+func (m *MockServerConsole) SendCommand(command string) error {
+	args := m.Called(command)
+	return args.Error(0)
+}
+
+// Stop mocks the Stop method
+func (m *MockServerConsole) Stop(timeout time.Duration) error {
+	args := m.Called(timeout)
+	return args.Error(0)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"testing"
+	"time"
+
+	"ritual/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Compile-time check to ensure MockServerConsole implements ports.ServerConsole
+var _ ports.ServerConsole = (*MockServerConsole)(nil)
+
+func TestMockServerConsole_SendCommand(t *testing.T) {
+	mockConsole := NewMockServerConsole()
+
+	mockConsole.On("SendCommand", "save-all").Return(nil)
+
+	err := mockConsole.SendCommand("save-all")
+	assert.NoError(t, err)
+
+	mockConsole.AssertExpectations(t)
+}
+
+func TestMockServerConsole_Stop(t *testing.T) {
+	mockConsole := NewMockServerConsole()
+
+	mockConsole.On("Stop", 30*time.Second).Return(assert.AnError)
+
+	err := mockConsole.Stop(30 * time.Second)
+	assert.Equal(t, assert.AnError, err)
+
+	mockConsole.AssertExpectations(t)
+}
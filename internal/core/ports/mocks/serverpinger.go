@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// MockServerPinger is a mock implementation of ServerPinger for testing
+type MockServerPinger struct {
+	PingFunc func(ctx context.Context, address string) (*domain.ServerStatus, error)
+}
+
+// Compile-time check to ensure MockServerPinger implements ports.ServerPinger
+var _ ports.ServerPinger = (*MockServerPinger)(nil)
+
+// NewMockServerPinger creates a new mock server pinger
+func NewMockServerPinger() *MockServerPinger {
+	return &MockServerPinger{}
+}
+
+// Ping returns the server status
+// Without PingFunc it reports an empty vanilla server
+func (m *MockServerPinger) Ping(ctx context.Context, address string) (*domain.ServerStatus, error) {
+	if m.PingFunc != nil {
+		return m.PingFunc(ctx, address)
+	}
+	return &domain.ServerStatus{Version: "1.21.1", MaxPlayers: 20, MOTD: "A Minecraft Server"}, nil
+}
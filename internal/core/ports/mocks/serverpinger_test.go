@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ritual/internal/core/domain"
+)
+
+func TestMockServerPinger_Ping_Success(t *testing.T) {
+	mock := NewMockServerPinger()
+
+	status, err := mock.Ping(context.Background(), "127.0.0.1:25565")
+	if err != nil {
+		t.Errorf("Ping() error = %v, want nil", err)
+	}
+	if status == nil || status.MaxPlayers != 20 {
+		t.Errorf("Ping() status = %+v, want max players 20", status)
+	}
+}
+
+func TestMockServerPinger_Ping_WithFunction(t *testing.T) {
+	mock := NewMockServerPinger()
+	expectedError := errors.New("connection refused")
+	var gotAddress string
+	mock.PingFunc = func(ctx context.Context, address string) (*domain.ServerStatus, error) {
+		gotAddress = address
+		return nil, expectedError
+	}
+
+	_, err := mock.Ping(context.Background(), "127.0.0.1:25565")
+	if err != expectedError {
+		t.Errorf("Ping() error = %v, want %v", err, expectedError)
+	}
+	if gotAddress != "127.0.0.1:25565" {
+		t.Errorf("Ping() address = %q, want %q", gotAddress, "127.0.0.1:25565")
+	}
+}
@@ -2,7 +2,15 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"ritual/internal/core/domain"
+	"time"
+)
+
+// Storage error constants shared by all StorageRepository implementations
+var (
+	ErrNotFound        = errors.New("key not found")
+	ErrVersionConflict = errors.New("version conflict") // Conditional write found a different stored version
 )
 
 // StorageRepository defines the interface for storage operations
@@ -24,17 +32,37 @@ type StorageRepository interface {
 	Copy(ctx context.Context, sourceKey string, destKey string) error
 }
 
+// VersionedStorage defines versioned reads and compare-and-swap writes
+// Implemented by storage backends that support conditional writes
+type VersionedStorage interface {
+	// GetWithVersion retrieves data by key together with its version token
+	GetWithVersion(ctx context.Context, key string) ([]byte, string, error)
+
+	// PutIfVersion stores data only if the stored version still equals version
+	// An empty version requires that key does not exist yet
+	// Returns the new version token, or an error wrapping ErrVersionConflict
+	PutIfVersion(ctx context.Context, key string, data []byte, version string) (string, error)
+}
+
+// SizedLister defines listing keys together with their stored sizes
+// Implemented by storage backends that report object sizes while listing
+type SizedLister interface {
+	// ListSizes returns the size in bytes of every key with the given prefix
+	ListSizes(ctx context.Context, prefix string) (map[string]int64, error)
+}
+
 // MolfarService defines the main orchestration interface
 // Molfar coordinates the complete server lifecycle and manages all operations
 type MolfarService interface {
 	// Prepare initializes the environment and validates prerequisites
-	Prepare() error
+	Prepare(ctx context.Context) error
 
 	// Run executes the main server orchestration process
-	Run(server *domain.Server) error
+	// Cancelling ctx stops the server gracefully
+	Run(ctx context.Context, server *domain.Server) error
 
 	// Exit gracefully shuts down the server and cleans up resources
-	Exit() error
+	Exit(ctx context.Context) error
 }
 
 // LibrarianService defines the manifest management interface
@@ -50,7 +78,21 @@ type LibrarianService interface {
 	SaveLocalManifest(ctx context.Context, manifest *domain.Manifest) error
 
 	// SaveRemoteManifest stores the manifest remotely
+	// With versioned storage the write only succeeds if the remote manifest
+	// is unchanged since it was last read or written
 	SaveRemoteManifest(ctx context.Context, manifest *domain.Manifest) error
+
+	// GetRemoteLease retrieves the remote lock object
+	// Returns an error wrapping ErrNotFound if no lease is held
+	GetRemoteLease(ctx context.Context) (*domain.Lease, error)
+
+	// SaveRemoteLease stores the remote lock object
+	// With versioned storage the write only succeeds if the lease is unchanged
+	// since it was last read or written; an unseen lease may only be created
+	SaveRemoteLease(ctx context.Context, lease *domain.Lease) error
+
+	// DeleteRemoteLease removes the remote lock object
+	DeleteRemoteLease(ctx context.Context) error
 }
 
 // ValidatorService defines the validation interface
@@ -80,12 +122,54 @@ type ServerRunner interface {
 	Run(server *domain.Server) error
 }
 
+// ServerConsole defines the interface for interacting with a running server
+// ServerConsole lets other components issue console commands during a session
+type ServerConsole interface {
+	// SendCommand writes a command line to the running server console
+	SendCommand(command string) error
+	// Stop requests a graceful shutdown and kills the server if it does not exit within timeout
+	Stop(timeout time.Duration) error
+}
+
+// RemoteConsole defines the RCON client interface
+// RemoteConsole issues commands to a running server over its RCON port
+type RemoteConsole interface {
+	// Connect opens a connection to address and authenticates with password
+	Connect(ctx context.Context, address, password string) error
+	// Command runs a console command and returns its complete response
+	Command(ctx context.Context, command string) (string, error)
+	// Close closes the connection
+	Close() error
+}
+
+// ServerPinger defines the Server List Ping interface
+// ServerPinger asks a running server for its status the way the multiplayer screen does
+type ServerPinger interface {
+	// Ping connects to address ("host:port") and returns the status the server reports
+	Ping(ctx context.Context, address string) (*domain.ServerStatus, error)
+}
+
 // BackupperService defines the backup orchestration interface
 // BackupperService handles backup creation and storage
 type BackupperService interface {
 	// Run executes the backup orchestration process
-	// Returns the archive name/URI that was created for manifest updates
-	Run(ctx context.Context) (string, error)
+	// Returns the world entry (URI, size, checksum) for manifest updates, or nil if the backup was skipped
+	Run(ctx context.Context) (*domain.World, error)
+}
+
+// CheckpointService defines the in-session backup interface
+// CheckpointService backs up the world periodically while the server runs
+type CheckpointService interface {
+	// Run takes a checkpoint every interval until ctx is cancelled
+	// lockID is the manifest lock held by the session; checkpoints stop once it is no longer held
+	Run(ctx context.Context, lockID string)
+}
+
+// HealthProbeService defines the in-session health check interface
+// HealthProbeService polls the running server and reports its status as events
+type HealthProbeService interface {
+	// Run polls server until ctx is cancelled
+	Run(ctx context.Context, server *domain.Server)
 }
 
 // UpdaterService defines the interface for update operations
@@ -99,8 +183,11 @@ type UpdaterService interface {
 // RetentionService defines the interface for backup retention operations
 // Retentions clean up old backups after manifest is updated
 type RetentionService interface {
-	// Apply removes old backups exceeding the retention limit
-	// Uses manifest's Backups to identify valid backups
+	// Plan lists what Apply would delete and why, without changing storage or the manifest
+	Plan(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error)
+
+	// Apply executes the plan, removing old backups exceeding the retention limit
+	// Uses manifest's Backups to identify valid backups and drops deleted ones from it
 	Apply(ctx context.Context, manifest *domain.Manifest) error
 }
 
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// BackupAnnotator error constants
+var (
+	ErrAnnotatorNil          = errors.New("backup annotator cannot be nil")
+	ErrAnnotatorLibrarianNil = errors.New("librarian service cannot be nil")
+	ErrBackupNotFound        = errors.New("backup not found in remote manifest")
+	ErrAnnotationEmpty       = errors.New("annotation changes nothing")
+	ErrLabelInvalid          = errors.New("label must be non-empty and contain no whitespace")
+)
+
+// BackupAnnotation changes the labels and note of a backup entry
+type BackupAnnotation struct {
+	AddLabels    []string
+	RemoveLabels []string
+	Note         *string // nil leaves the note unchanged; empty clears it
+}
+
+// BackupAnnotator pins and annotates backup entries in the remote manifest
+// Changes are made while holding the manifest lock and mirrored to the local manifest
+type BackupAnnotator struct {
+	librarian ports.LibrarianService
+	events    chan<- ports.Event
+}
+
+// NewBackupAnnotator creates a new backup annotator
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewBackupAnnotator(librarian ports.LibrarianService, events chan<- ports.Event) (*BackupAnnotator, error) {
+	if librarian == nil {
+		return nil, ErrAnnotatorLibrarianNil
+	}
+
+	return &BackupAnnotator{
+		librarian: librarian,
+		events:    events,
+	}, nil
+}
+
+// send safely sends an event to the channel
+func (a *BackupAnnotator) send(evt ports.Event) {
+	ports.SendEvent(a.events, evt)
+}
+
+// Pin sets whether retention keeps the backup referenced by ref forever
+// ref is the backup URI, its file name, or its timestamp
+func (a *BackupAnnotator) Pin(ctx context.Context, ref string, pinned bool) (*domain.World, error) {
+	return a.update(ctx, ref, func(world *domain.World) {
+		world.Pinned = pinned
+	})
+}
+
+// Annotate adds and removes labels and replaces the note of the backup referenced by ref
+func (a *BackupAnnotator) Annotate(ctx context.Context, ref string, annotation BackupAnnotation) (*domain.World, error) {
+	if len(annotation.AddLabels) == 0 && len(annotation.RemoveLabels) == 0 && annotation.Note == nil {
+		return nil, ErrAnnotationEmpty
+	}
+	for _, label := range slices.Concat(annotation.AddLabels, annotation.RemoveLabels) {
+		if label == "" || strings.ContainsFunc(label, unicode.IsSpace) {
+			return nil, fmt.Errorf("%w: %q", ErrLabelInvalid, label)
+		}
+	}
+
+	return a.update(ctx, ref, func(world *domain.World) {
+		for _, label := range annotation.AddLabels {
+			world.AddLabel(label)
+		}
+		for _, label := range annotation.RemoveLabels {
+			world.RemoveLabel(label)
+		}
+		if annotation.Note != nil {
+			world.Note = strings.TrimSpace(*annotation.Note)
+		}
+	})
+}
+
+// update applies change to the backup entry while holding the manifest lock
+// Returns the entry as saved to the remote manifest
+func (a *BackupAnnotator) update(ctx context.Context, ref string, change func(world *domain.World)) (*domain.World, error) {
+	if a == nil {
+		return nil, ErrAnnotatorNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	locker, err := NewManifestLocker(a.librarian, "annotate", a.events)
+	if err != nil {
+		return nil, err
+	}
+	remoteManifest, err := locker.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, updateErr := a.updateLocked(ctx, ref, remoteManifest, change)
+
+	// Release with a context that survives cancellation so the lock is not left behind
+	if err := locker.Release(context.WithoutCancel(ctx), remoteManifest); err != nil {
+		if updateErr != nil {
+			return nil, fmt.Errorf("%w; additionally failed to release lock: %w", updateErr, err)
+		}
+		return nil, fmt.Errorf("failed to save annotation: %w", err)
+	}
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	a.send(ports.UpdateEvent{Operation: "annotate", Message: "Backup annotated", Data: map[string]any{
+		"uri":    updated.URI,
+		"pinned": updated.Pinned,
+		"labels": updated.Labels,
+		"note":   updated.Note,
+	}})
+	return updated, nil
+}
+
+// updateLocked changes the remote entry in place and mirrors it to the local manifest
+// remoteManifest is saved by the caller on release
+func (a *BackupAnnotator) updateLocked(ctx context.Context, ref string, remoteManifest *domain.Manifest, change func(world *domain.World)) (*domain.World, error) {
+	world := remoteManifest.FindBackup(ref)
+	if world == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, ref)
+	}
+	change(world)
+	updated := *world
+
+	// The local manifest may not list the backup; it then has nothing to mirror
+	localManifest, err := a.librarian.GetLocalManifest(ctx)
+	if err != nil || localManifest == nil {
+		return &updated, nil
+	}
+	if local := localManifest.FindBackup(updated.URI); local != nil {
+		local.Annotate(updated)
+		if err := a.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+			return nil, fmt.Errorf("failed to record annotation in local manifest: %w", err)
+		}
+	}
+
+	return &updated, nil
+}
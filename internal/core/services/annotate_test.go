@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAnnotator stores a manifest with two backups locally and remotely
+func setupAnnotator(t *testing.T) (*services.BackupAnnotator, *adapters.FSRepository, *adapters.FSRepository, []domain.World) {
+	t.Helper()
+	ctx := context.Background()
+	localStorage := newReplicaStorage(t)
+	remoteStorage := newReplicaStorage(t)
+
+	backups := []domain.World{
+		{URI: config.RemoteBackups + "/20240101120000" + config.BackupExtension, CreatedAt: time.Now().Add(-time.Hour)},
+		{URI: config.RemoteBackups + "/20240102120000" + config.BackupExtension, CreatedAt: time.Now()},
+	}
+	data, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.0.0", backups))
+	require.NoError(t, err)
+	require.NoError(t, remoteStorage.Put(ctx, config.ManifestFilename, data))
+	require.NoError(t, localStorage.Put(ctx, config.ManifestFilename, data))
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	annotator, err := services.NewBackupAnnotator(librarian, nil)
+	require.NoError(t, err)
+	return annotator, localStorage, remoteStorage, backups
+}
+
+func TestNewBackupAnnotator(t *testing.T) {
+	_, err := services.NewBackupAnnotator(nil, nil)
+	assert.ErrorIs(t, err, services.ErrAnnotatorLibrarianNil)
+
+	annotator, err := services.NewBackupAnnotator(&mocks.MockLibrarianService{}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, annotator)
+
+	var nilAnnotator *services.BackupAnnotator
+	_, err = nilAnnotator.Pin(context.Background(), "20240101120000", true)
+	assert.ErrorIs(t, err, services.ErrAnnotatorNil)
+}
+
+func TestBackupAnnotator_Pin(t *testing.T) {
+	ctx := context.Background()
+	annotator, localStorage, remoteStorage, backups := setupAnnotator(t)
+
+	world, err := annotator.Pin(ctx, "20240101120000", true)
+	require.NoError(t, err)
+	assert.Equal(t, backups[0].URI, world.URI)
+	assert.True(t, world.Pinned)
+
+	for _, storage := range []*adapters.FSRepository{remoteStorage, localStorage} {
+		manifest := readTestManifest(t, storage)
+		assert.True(t, manifest.Backups[0].Pinned)
+		assert.False(t, manifest.Backups[1].Pinned)
+		assert.False(t, manifest.IsLocked(), "lock is released")
+	}
+
+	world, err = annotator.Pin(ctx, backups[0].URI, false)
+	require.NoError(t, err)
+	assert.False(t, world.Pinned)
+	assert.False(t, readTestManifest(t, remoteStorage).Backups[0].Pinned)
+
+	_, err = annotator.Pin(ctx, "20991231000000", true)
+	assert.ErrorIs(t, err, services.ErrBackupNotFound)
+	assert.False(t, readTestManifest(t, remoteStorage).IsLocked(), "lock is released after a failure")
+}
+
+func TestBackupAnnotator_Annotate(t *testing.T) {
+	ctx := context.Background()
+	annotator, localStorage, remoteStorage, backups := setupAnnotator(t)
+	note := "  before the dragon fight "
+
+	world, err := annotator.Annotate(ctx, "20240102120000.tar", services.BackupAnnotation{AddLabels: []string{"season-1", "dragon"}, Note: &note})
+	require.NoError(t, err)
+	assert.Equal(t, backups[1].URI, world.URI)
+	assert.Equal(t, []string{"season-1", "dragon"}, world.Labels)
+	assert.Equal(t, "before the dragon fight", world.Note)
+
+	world, err = annotator.Annotate(ctx, backups[1].URI, services.BackupAnnotation{AddLabels: []string{"season-1"}, RemoveLabels: []string{"dragon"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"season-1"}, world.Labels)
+	assert.Equal(t, "before the dragon fight", world.Note, "note is kept when not given")
+
+	for _, storage := range []*adapters.FSRepository{remoteStorage, localStorage} {
+		stored := readTestManifest(t, storage).Backups[1]
+		assert.Equal(t, []string{"season-1"}, stored.Labels)
+		assert.Equal(t, "before the dragon fight", stored.Note)
+	}
+
+	_, err = annotator.Annotate(ctx, backups[1].URI, services.BackupAnnotation{})
+	assert.ErrorIs(t, err, services.ErrAnnotationEmpty)
+	_, err = annotator.Annotate(ctx, backups[1].URI, services.BackupAnnotation{AddLabels: []string{"two words"}})
+	assert.ErrorIs(t, err, services.ErrLabelInvalid)
+}
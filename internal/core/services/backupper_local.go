@@ -10,6 +10,7 @@ import (
 
 	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
+	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 )
 
@@ -56,24 +57,29 @@ func NewLocalBackupper(workRoot *os.Root, worldDirs []string, shouldRun func() b
 }
 
 // Run executes the streaming backup process
-// Returns the archive path (relative to workRoot) for manifest updates
-// Returns empty string if shouldRun callback returns false (backup skipped)
-func (b *LocalBackupper) Run(ctx context.Context) (string, error) {
+// Returns the world entry (URI relative to workRoot) for manifest updates
+// Returns nil if shouldRun callback returns false (backup skipped)
+func (b *LocalBackupper) Run(ctx context.Context) (*domain.World, error) {
 	if b == nil {
-		return "", ErrLocalBackupperNil
+		return nil, ErrLocalBackupperNil
 	}
 	if ctx == nil {
-		return "", errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
 
 	// Check if backup should run at all
 	if b.shouldRun != nil && !b.shouldRun() {
-		return "", nil // Skip backup, return empty (no archive created)
+		return nil, nil // Skip backup (no archive created)
+	}
+
+	codec, err := streamer.CodecByName(config.BackupCodec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup codec: %w", err)
 	}
 
 	// Generate backup name based on timestamp
 	timestamp := time.Now().Format(config.TimestampFormat)
-	backupName := timestamp + config.BackupExtension
+	backupName := timestamp + config.BackupExtension + codec.Extension()
 
 	// World directories to backup (relative to workRoot)
 	rootPath := b.workRoot.Name()
@@ -86,14 +92,14 @@ func (b *LocalBackupper) Run(ctx context.Context) (string, error) {
 	}
 
 	if len(existingDirs) == 0 {
-		return "", errors.New("no world directories found")
+		return nil, errors.New("no world directories found")
 	}
 
 	// Create local file writer for the backup directory
 	backupDir := filepath.Join(rootPath, config.LocalBackups)
 	localWriter, err := streamer.NewLocalFileWriter(backupDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to create local writer: %w", err)
+		return nil, fmt.Errorf("failed to create local writer: %w", err)
 	}
 
 	// Execute streaming push (key is just the filename since basePath is backupDir)
@@ -102,13 +108,19 @@ func (b *LocalBackupper) Run(ctx context.Context) (string, error) {
 		Bucket: "local", // Not used by LocalFileWriter but required by Push
 		Key:    backupName,
 		Events: b.events,
+		Codec:  codec,
 	}
 
-	_, err = streamer.Push(ctx, cfg, localWriter)
+	result, err := streamer.Push(ctx, cfg, localWriter)
 	if err != nil {
-		return "", fmt.Errorf("streaming backup failed: %w", err)
+		return nil, fmt.Errorf("streaming backup failed: %w", err)
 	}
 
 	// Return full path relative to workRoot for manifest tracking
-	return config.LocalBackups + "/" + backupName, nil
+	return &domain.World{
+		URI:       config.LocalBackups + "/" + backupName,
+		CreatedAt: time.Now(),
+		Size:      result.Size,
+		Checksum:  result.Checksum,
+	}, nil
 }
@@ -2,6 +2,8 @@ package services_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"os"
 	"path/filepath"
 	"ritual/internal/adapters"
@@ -75,8 +77,14 @@ func TestLocalBackupper_Run(t *testing.T) {
 		ctx := context.Background()
 		archiveName, err := backupper.Run(ctx)
 		require.NoError(t, err)
-		assert.NotEmpty(t, archiveName)
-		assert.True(t, strings.HasSuffix(archiveName, ".tar"))
+		require.NotNil(t, archiveName)
+		assert.True(t, strings.HasSuffix(archiveName.URI, ".tar.gz"))
+
+		// Size and checksum describe the archive written to disk
+		data, err := os.ReadFile(filepath.Join(tempDir, filepath.FromSlash(archiveName.URI)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), archiveName.Size)
+		assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(data)), archiveName.Checksum)
 	})
 
 	t.Run("stores archive in local backup directory", func(t *testing.T) {
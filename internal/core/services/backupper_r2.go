@@ -10,25 +10,30 @@ import (
 
 	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
+	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 )
 
 // R2Backupper error constants
 var (
-	ErrR2BackupperUploaderNil = errors.New("uploader cannot be nil")
-	ErrR2BackupperWorkRootNil = errors.New("workRoot cannot be nil")
-	ErrR2BackupperNil         = errors.New("R2 backupper cannot be nil")
+	ErrR2BackupperUploaderNil   = errors.New("uploader cannot be nil")
+	ErrR2BackupperDownloaderNil = errors.New("downloader cannot be nil")
+	ErrR2BackupperWorkRootNil   = errors.New("workRoot cannot be nil")
+	ErrR2BackupperNil           = errors.New("R2 backupper cannot be nil")
 )
 
 // R2Backupper implements BackupperService for R2 backup storage with streaming
 type R2Backupper struct {
 	uploader        streamer.S3StreamUploader
+	downloader      streamer.S3StreamDownloader // Reads uploads back for verification
+	lister          streamer.S3StreamLister     // Optional: lists stored chunks; non-nil stores backups as deduplicated chunks
 	bucket          string
 	workRoot        *os.Root
 	worldDirs       []string           // Directories to archive (relative to instance dir)
 	saveLocalBackup bool               // Whether to also save local backup
 	shouldSaveLocal func() bool        // Condition for local backup (nil = always save if enabled)
 	shouldRun       func() bool        // Condition to run backup at all (nil = always run)
+	keys            *streamer.Keyring  // Optional: encrypts archives with the current key
 	events          chan<- ports.Event // Optional: channel for progress events
 }
 
@@ -39,17 +44,23 @@ var _ ports.BackupperService = (*R2Backupper)(nil)
 // Validates all dependencies are non-nil per NASA JPL defensive programming standards
 func NewR2Backupper(
 	uploader streamer.S3StreamUploader,
+	downloader streamer.S3StreamDownloader,
+	lister streamer.S3StreamLister,
 	bucket string,
 	workRoot *os.Root,
 	worldDirs []string,
 	saveLocalBackup bool,
 	shouldSaveLocal func() bool,
 	shouldRun func() bool,
+	keys *streamer.Keyring,
 	events chan<- ports.Event,
 ) (*R2Backupper, error) {
 	if uploader == nil {
 		return nil, ErrR2BackupperUploaderNil
 	}
+	if downloader == nil {
+		return nil, ErrR2BackupperDownloaderNil
+	}
 	if workRoot == nil {
 		return nil, ErrR2BackupperWorkRootNil
 	}
@@ -59,12 +70,15 @@ func NewR2Backupper(
 
 	backupper := &R2Backupper{
 		uploader:        uploader,
+		downloader:      downloader,
+		lister:          lister,
 		bucket:          bucket,
 		workRoot:        workRoot,
 		worldDirs:       worldDirs,
 		saveLocalBackup: saveLocalBackup,
 		shouldSaveLocal: shouldSaveLocal,
 		shouldRun:       shouldRun,
+		keys:            keys,
 		events:          events,
 	}
 
@@ -76,49 +90,69 @@ func NewR2Backupper(
 	return backupper, nil
 }
 
+// send safely sends an event to the channel
+func (b *R2Backupper) send(evt ports.Event) {
+	ports.SendEvent(b.events, evt)
+}
+
 // Run executes the streaming backup process
-// Returns the archive key for manifest updates
-// Returns empty string if shouldRun callback returns false (backup skipped)
-func (b *R2Backupper) Run(ctx context.Context) (string, error) {
+// Returns the verified world entry for manifest updates
+// Returns nil if shouldRun callback returns false (backup skipped)
+func (b *R2Backupper) Run(ctx context.Context) (*domain.World, error) {
 	if b == nil {
-		return "", ErrR2BackupperNil
+		return nil, ErrR2BackupperNil
 	}
 	if ctx == nil {
-		return "", errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
 
 	// Check if backup should run at all
 	if b.shouldRun != nil && !b.shouldRun() {
-		return "", nil // Skip backup, return empty (no archive created)
+		return nil, nil // Skip backup (no archive created)
+	}
+
+	codec, err := streamer.CodecByName(config.BackupCodec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup codec: %w", err)
 	}
 
 	// Generate backup key based on timestamp
+	// Chunked backups are named by their index; the archive filename is only used for full archives
 	timestamp := time.Now().Format(config.TimestampFormat)
-	key := config.RemoteBackups + "/" + timestamp + config.BackupExtension
-	backupFilename := timestamp + config.BackupExtension
+	backupFilename := timestamp + config.BackupExtension + codec.Extension()
+	key := config.RemoteBackups + "/" + backupFilename
+	if b.lister != nil {
+		key = config.RemoteBackups + "/" + timestamp + streamer.IndexExtension
+	}
 
 	// World directories to backup (via workRoot for safety)
-	var existingDirs []string
+	var existingDirs, includedDirs []string
 	for _, dir := range b.worldDirs {
 		relPath := config.InstanceDir + "/" + dir
 		if _, err := b.workRoot.Stat(relPath); err == nil {
 			// Convert to absolute path for tar archiver
 			existingDirs = append(existingDirs, filepath.Join(b.workRoot.Name(), relPath))
+			includedDirs = append(includedDirs, dir)
 		}
 	}
 
 	if len(existingDirs) == 0 {
-		return "", errors.New("no world directories found")
+		return nil, errors.New("no world directories found")
 	}
 
 	// Prepare local path if configured (via workRoot for safety)
 	// Evaluate condition early to avoid creating directory unnecessarily
 	var localBackupPath string
 	doLocalBackup := b.saveLocalBackup && (b.shouldSaveLocal == nil || b.shouldSaveLocal())
+	if doLocalBackup && b.lister != nil {
+		// There is no single archive stream to tee in chunked mode
+		b.send(ports.UpdateEvent{Operation: "backup", Message: "Local backup is not written for chunked backups"})
+		doLocalBackup = false
+	}
 	if doLocalBackup {
 		// Ensure local backup directory exists
 		if err := b.workRoot.Mkdir(config.LocalBackups, 0755); err != nil && !os.IsExist(err) {
-			return "", fmt.Errorf("failed to create local backup directory: %w", err)
+			return nil, fmt.Errorf("failed to create local backup directory: %w", err)
 		}
 		localBackupPath = filepath.Join(b.workRoot.Name(), config.LocalBackups, backupFilename)
 	}
@@ -126,17 +160,62 @@ func (b *R2Backupper) Run(ctx context.Context) (string, error) {
 	// Execute streaming push
 	// Note: ShouldBackup already evaluated above, so we pass nil here
 	cfg := streamer.PushConfig{
-		Dirs:      existingDirs,
-		Bucket:    b.bucket,
-		Key:       key,
-		LocalPath: localBackupPath,
-		Events:    b.events,
+		Dirs:       existingDirs,
+		Bucket:     b.bucket,
+		Key:        key,
+		LocalPath:  localBackupPath,
+		Events:     b.events,
+		Codec:      codec,
+		EncryptKey: b.keys.Current(),
 	}
 
-	_, err := streamer.Push(ctx, cfg, b.uploader)
+	var result streamer.Result
+	if b.lister != nil {
+		result, err = streamer.PushChunks(ctx, cfg, b.uploader, b.lister)
+	} else {
+		result, err = streamer.Push(ctx, cfg, b.uploader)
+	}
 	if err != nil {
-		return "", fmt.Errorf("streaming backup failed: %w", err)
+		return nil, fmt.Errorf("streaming backup failed: %w", err)
 	}
 
-	return key, nil
+	// Read the object back before it can be recorded in the manifest
+	// An unverified upload is left for retention to remove as a dangling backup
+	b.send(ports.UpdateEvent{Operation: "backup", Message: "Verifying uploaded backup", Data: map[string]any{"key": key}})
+	if err := streamer.Verify(ctx, streamer.VerifyConfig{
+		Bucket:   b.bucket,
+		Key:      key,
+		Size:     result.Size,
+		Checksum: result.Checksum,
+	}, b.downloader); err != nil {
+		return nil, fmt.Errorf("uploaded backup failed verification: %w", err)
+	}
+
+	world := &domain.World{
+		URI:           key,
+		CreatedAt:     time.Now(),
+		Size:          result.Size,
+		Checksum:      result.Checksum,
+		KeyID:         result.KeyID,
+		RitualVersion: config.AppVersion,
+		WorldDirs:     includedDirs,
+	}
+	b.describeSession(world)
+	return world, nil
+}
+
+// describeSession records the host and what the server log tells about the session
+// Metadata is informational, so failing to collect it never fails the backup
+func (b *R2Backupper) describeSession(world *domain.World) {
+	if host, err := os.Hostname(); err == nil {
+		world.Host = host
+	}
+
+	session, err := ReadSessionLog(b.workRoot)
+	if err != nil {
+		b.send(ports.UpdateEvent{Operation: "backup", Message: "Server log unreadable, players and version not recorded", Data: map[string]any{"error": err.Error()}})
+		return
+	}
+	world.Players = session.Players
+	world.MinecraftVersion = session.MinecraftVersion
 }
@@ -3,6 +3,8 @@ package services_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -23,11 +25,12 @@ import (
 // R2Backupper creates streaming tar.gz archives from world directories and uploads to R2 storage.
 // Uses mockStreamUploader and FSRepository to simulate R2 storage in tests.
 
-// mockStreamUploader implements streamer.S3StreamUploader for testing
+// mockStreamUploader implements streamer.S3StreamUploader and S3StreamDownloader for testing
 type mockStreamUploader struct {
 	storage   *adapters.FSRepository
 	basePath  string
 	uploadErr error
+	corrupt   bool // Store a different object than the one streamed
 }
 
 func (m *mockStreamUploader) Upload(ctx context.Context, bucket, key string, body io.Reader, _ int64) (int64, error) {
@@ -41,7 +44,12 @@ func (m *mockStreamUploader) Upload(ctx context.Context, bucket, key string, bod
 		return 0, err
 	}
 
-	err = m.storage.Put(ctx, key, data)
+	stored := data
+	if m.corrupt {
+		stored = append([]byte("corrupt"), data...)
+	}
+
+	err = m.storage.Put(ctx, key, stored)
 	if err != nil {
 		return 0, err
 	}
@@ -49,6 +57,14 @@ func (m *mockStreamUploader) Upload(ctx context.Context, bucket, key string, bod
 	return int64(len(data)), nil
 }
 
+func (m *mockStreamUploader) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, err := m.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
 func setupR2BackupperServices(t *testing.T) (
 	*mockStreamUploader,
 	*adapters.FSRepository,
@@ -110,13 +126,16 @@ func TestR2Backupper_Run(t *testing.T) {
 		// Create R2Backupper
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
 			false, // no local backup
 			nil,   // shouldSaveLocal
 			nil,   // shouldRun
-			nil,   // no events
+			nil,
+			nil, // no events
 		)
 		require.NoError(t, err)
 
@@ -124,8 +143,8 @@ func TestR2Backupper_Run(t *testing.T) {
 		ctx := context.Background()
 		archiveName, err := backupper.Run(ctx)
 		require.NoError(t, err)
-		assert.NotEmpty(t, archiveName)
-		assert.True(t, strings.HasSuffix(archiveName, ".tar"))
+		require.NotNil(t, archiveName)
+		assert.True(t, strings.HasSuffix(archiveName.URI, ".tar.gz"))
 	})
 
 	t.Run("uploads archive to R2 storage", func(t *testing.T) {
@@ -138,6 +157,8 @@ func TestR2Backupper_Run(t *testing.T) {
 		// Create R2Backupper
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -145,6 +166,7 @@ func TestR2Backupper_Run(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -169,6 +191,8 @@ func TestR2Backupper_Run(t *testing.T) {
 		// Create R2Backupper
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -176,6 +200,7 @@ func TestR2Backupper_Run(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -186,7 +211,7 @@ func TestR2Backupper_Run(t *testing.T) {
 
 		// Archive key should be non-empty and usable for manifest
 		assert.NotEmpty(t, archiveKey)
-		assert.True(t, strings.HasPrefix(archiveKey, config.RemoteBackups+"/"))
+		assert.True(t, strings.HasPrefix(archiveKey.URI, config.RemoteBackups+"/"))
 	})
 
 	t.Run("nil context returns error", func(t *testing.T) {
@@ -195,6 +220,8 @@ func TestR2Backupper_Run(t *testing.T) {
 
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -202,6 +229,7 @@ func TestR2Backupper_Run(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -215,19 +243,27 @@ func TestNewR2Backupper(t *testing.T) {
 	worldDirs := []string{"world", "world_nether", "world_the_end"}
 
 	t.Run("nil uploader returns error", func(t *testing.T) {
-		_, _, _, workRoot, cleanup := setupR2BackupperServices(t)
+		uploader, _, _, workRoot, cleanup := setupR2BackupperServices(t)
 		defer cleanup()
 
-		_, err := services.NewR2Backupper(nil, "bucket", workRoot, worldDirs, false, nil, nil, nil)
+		_, err := services.NewR2Backupper(nil, uploader, nil, "bucket", workRoot, worldDirs, false, nil, nil, nil, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "uploader")
 	})
 
+	t.Run("nil downloader returns error", func(t *testing.T) {
+		uploader, _, _, workRoot, cleanup := setupR2BackupperServices(t)
+		defer cleanup()
+
+		_, err := services.NewR2Backupper(uploader, nil, nil, "bucket", workRoot, worldDirs, false, nil, nil, nil, nil)
+		assert.ErrorIs(t, err, services.ErrR2BackupperDownloaderNil)
+	})
+
 	t.Run("nil workRoot returns error", func(t *testing.T) {
 		uploader, _, _, _, cleanup := setupR2BackupperServices(t)
 		defer cleanup()
 
-		_, err := services.NewR2Backupper(uploader, "bucket", nil, worldDirs, false, nil, nil, nil)
+		_, err := services.NewR2Backupper(uploader, uploader, nil, "bucket", nil, worldDirs, false, nil, nil, nil, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "workRoot")
 	})
@@ -236,7 +272,7 @@ func TestNewR2Backupper(t *testing.T) {
 		uploader, _, _, workRoot, cleanup := setupR2BackupperServices(t)
 		defer cleanup()
 
-		_, err := services.NewR2Backupper(uploader, "bucket", workRoot, []string{}, false, nil, nil, nil)
+		_, err := services.NewR2Backupper(uploader, uploader, nil, "bucket", workRoot, []string{}, false, nil, nil, nil, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "worldDirs")
 	})
@@ -245,7 +281,7 @@ func TestNewR2Backupper(t *testing.T) {
 		uploader, _, _, workRoot, cleanup := setupR2BackupperServices(t)
 		defer cleanup()
 
-		backupper, err := services.NewR2Backupper(uploader, "bucket", workRoot, worldDirs, false, nil, nil, nil)
+		backupper, err := services.NewR2Backupper(uploader, uploader, nil, "bucket", workRoot, worldDirs, false, nil, nil, nil, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, backupper)
 	})
@@ -263,6 +299,8 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 		// Create R2Backupper with local backup enabled
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -270,6 +308,7 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -295,6 +334,8 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 		// Create R2Backupper with local backup disabled
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -302,6 +343,7 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -326,6 +368,8 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 		// Create R2Backupper with local backup enabled but condition false
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -333,6 +377,7 @@ func TestR2Backupper_LocalBackup(t *testing.T) {
 			func() bool { return false }, // shouldSaveLocal returns false
 			nil,                          // shouldRun
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -366,6 +411,8 @@ func TestR2Backupper_StreamingVerification(t *testing.T) {
 
 		backupper, err := services.NewR2Backupper(
 			capturingUploader,
+			capturingUploader,
+			nil,
 			"test-bucket",
 			tempRoot,
 			[]string{"world", "world_nether", "world_the_end"},
@@ -373,6 +420,7 @@ func TestR2Backupper_StreamingVerification(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -408,6 +456,10 @@ func (c *capturingStreamUploader) Upload(ctx context.Context, bucket, key string
 	return n, err
 }
 
+func (c *capturingStreamUploader) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(c.buf.Bytes())), nil
+}
+
 // mockStreamDownloader implements streamer.S3StreamDownloader for testing
 type mockStreamDownloader struct {
 	data []byte
@@ -417,6 +469,156 @@ func (m *mockStreamDownloader) Download(ctx context.Context, bucket, key string)
 	return io.NopCloser(bytes.NewReader(m.data)), nil
 }
 
+// TestR2Backupper_RecordsChecksum tests that the uploaded object is verified and described by the returned world
+func TestR2Backupper_RecordsChecksum(t *testing.T) {
+	t.Run("world carries size and checksum of the uploaded object", func(t *testing.T) {
+		uploader, remoteStorage, tempDir, workRoot, cleanup := setupR2BackupperServices(t)
+		defer cleanup()
+		setupR2BackupperWorldData(t, tempDir)
+
+		backupper, err := services.NewR2Backupper(uploader, uploader, nil, "test-bucket", workRoot, []string{"world"}, false, nil, nil, nil, nil)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		world, err := backupper.Run(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, world)
+
+		data, err := remoteStorage.Get(ctx, world.URI)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), world.Size)
+		assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(data)), world.Checksum)
+	})
+
+	t.Run("mismatched upload fails verification", func(t *testing.T) {
+		uploader, _, tempDir, workRoot, cleanup := setupR2BackupperServices(t)
+		defer cleanup()
+		setupR2BackupperWorldData(t, tempDir)
+		uploader.corrupt = true
+
+		backupper, err := services.NewR2Backupper(uploader, uploader, nil, "test-bucket", workRoot, []string{"world"}, false, nil, nil, nil, nil)
+		require.NoError(t, err)
+
+		world, err := backupper.Run(context.Background())
+		assert.ErrorIs(t, err, streamer.ErrSizeMismatch)
+		assert.Nil(t, world)
+	})
+}
+
+// TestR2Backupper_RecordsSessionMetadata tests that the world entry describes the session it came from
+func TestR2Backupper_RecordsSessionMetadata(t *testing.T) {
+	uploader, _, tempDir, workRoot, cleanup := setupR2BackupperServices(t)
+	defer cleanup()
+	setupR2BackupperWorldData(t, tempDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, config.LogsDir), 0755))
+	logContent := "[Server thread/INFO]: Starting minecraft server version 1.20.1\n" +
+		"[Server thread/INFO]: owl joined the game\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, config.LogsDir, config.ServerLogFilename), []byte(logContent), 0644))
+
+	backupper, err := services.NewR2Backupper(uploader, uploader, nil, "test-bucket", workRoot, []string{"world", "missing"}, false, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	world, err := backupper.Run(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, world)
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	assert.Equal(t, host, world.Host)
+	assert.Equal(t, config.AppVersion, world.RitualVersion)
+	assert.Equal(t, []string{"world"}, world.WorldDirs, "only directories that exist are recorded")
+	assert.Equal(t, []string{"owl"}, world.Players)
+	assert.Equal(t, "1.20.1", world.MinecraftVersion)
+}
+
+// TestR2Backupper_Encryption tests that archives are sealed with the current key and record its ID
+func TestR2Backupper_Encryption(t *testing.T) {
+	uploader, remoteStorage, tempDir, workRoot, cleanup := setupR2BackupperServices(t)
+	defer cleanup()
+	setupR2BackupperWorldData(t, tempDir)
+
+	key, err := streamer.NewKey(bytes.Repeat([]byte{3}, 32))
+	require.NoError(t, err)
+	keys, err := streamer.NewKeyring(key)
+	require.NoError(t, err)
+
+	backupper, err := services.NewR2Backupper(uploader, uploader, nil, "test-bucket", workRoot, []string{"world"}, false, nil, nil, keys, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	world, err := backupper.Run(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, world)
+	assert.Equal(t, key.ID(), world.KeyID)
+
+	data, err := remoteStorage.Get(ctx, world.URI)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(data)), world.Checksum)
+
+	destDir := t.TempDir()
+	err = streamer.Pull(ctx, streamer.PullConfig{
+		Bucket:   "test-bucket",
+		Key:      world.URI,
+		Dest:     destDir,
+		Checksum: world.Checksum,
+		Keys:     keys,
+	}, uploader)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(destDir, "world", "level.dat"))
+	assert.NoError(t, err)
+
+	err = streamer.Pull(ctx, streamer.PullConfig{
+		Bucket: "test-bucket",
+		Key:    world.URI,
+		Dest:   t.TempDir(),
+	}, uploader)
+	assert.ErrorIs(t, err, streamer.ErrKeyNotFound)
+}
+
+// TestR2Backupper_Chunked tests that a lister switches backups to deduplicated chunks
+func TestR2Backupper_Chunked(t *testing.T) {
+	uploader, remoteStorage, tempDir, workRoot, cleanup := setupR2BackupperServices(t)
+	defer cleanup()
+	setupR2BackupperWorldData(t, tempDir)
+
+	backupper, err := services.NewR2Backupper(uploader, uploader, remoteStorage, "test-bucket", workRoot, []string{"world"}, true, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first, err := backupper.Run(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.True(t, streamer.IsChunkIndex(first.URI))
+
+	chunks, err := remoteStorage.List(ctx, streamer.ChunkPrefix)
+	require.NoError(t, err)
+	assert.NotEmpty(t, chunks)
+
+	// Unchanged world data is not uploaded again
+	second, err := backupper.Run(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	after, err := remoteStorage.List(ctx, streamer.ChunkPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, chunks, after)
+
+	// No local archive is teed in chunked mode
+	_, err = os.Stat(filepath.Join(tempDir, config.LocalBackups))
+	assert.True(t, os.IsNotExist(err))
+
+	destDir := t.TempDir()
+	require.NoError(t, streamer.Pull(ctx, streamer.PullConfig{
+		Bucket:   "test-bucket",
+		Key:      second.URI,
+		Dest:     destDir,
+		Size:     second.Size,
+		Checksum: second.Checksum,
+	}, uploader))
+	_, err = os.Stat(filepath.Join(destDir, "world", "level.dat"))
+	assert.NoError(t, err)
+}
+
 // TestR2Backupper_ShouldRun tests the shouldRun callback that skips entire backup
 func TestR2Backupper_ShouldRun(t *testing.T) {
 	t.Run("skips backup when shouldRun returns false", func(t *testing.T) {
@@ -429,13 +631,16 @@ func TestR2Backupper_ShouldRun(t *testing.T) {
 		// Create R2Backupper with shouldRun returning false
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
 			true,                         // saveLocalBackup
 			nil,                          // shouldSaveLocal
 			func() bool { return false }, // shouldRun - skip backup
-			nil,                          // events
+			nil,
+			nil, // events
 		)
 		require.NoError(t, err)
 
@@ -468,13 +673,16 @@ func TestR2Backupper_ShouldRun(t *testing.T) {
 		// Create R2Backupper with shouldRun returning true
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
 			false,                       // saveLocalBackup
 			nil,                         // shouldSaveLocal
 			func() bool { return true }, // shouldRun - run backup
-			nil,                         // events
+			nil,
+			nil, // events
 		)
 		require.NoError(t, err)
 
@@ -502,13 +710,16 @@ func TestR2Backupper_ShouldRun(t *testing.T) {
 		// Create R2Backupper with shouldRun nil (default behavior)
 		backupper, err := services.NewR2Backupper(
 			uploader,
+			uploader,
+			nil,
 			"test-bucket",
 			workRoot,
 			[]string{"world", "world_nether", "world_the_end"},
 			false, // saveLocalBackup
 			nil,   // shouldSaveLocal
 			nil,   // shouldRun - nil means always run
-			nil,   // events
+			nil,
+			nil, // events
 		)
 		require.NoError(t, err)
 
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ritual/internal/adapters/streamer"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// ReplicatedBackupper error constants
+var (
+	ErrReplicatedBackupperNil        = errors.New("replicated backupper cannot be nil")
+	ErrReplicatedPrimaryNil          = errors.New("primary backupper cannot be nil")
+	ErrReplicatedSourceNil           = errors.New("source downloader cannot be nil")
+	ErrReplicaTargetNameEmpty        = errors.New("replica target name cannot be empty")
+	ErrReplicaTargetNameReserved     = errors.New("replica target name is reserved for the primary remote")
+	ErrReplicaTargetNameDuplicate    = errors.New("replica target name is used twice")
+	ErrReplicaTargetUploaderNil      = errors.New("replica target uploader cannot be nil")
+	ErrReplicaTargetDownloaderNil    = errors.New("replica target downloader cannot be nil")
+	ErrReplicatedBackupNotReplicable = errors.New("backup has no size or checksum to verify replicas against")
+)
+
+// ReplicaTarget is an additional storage destination holding copies of world backups
+type ReplicaTarget struct {
+	Name       string                      // Recorded in World.Replicas
+	Bucket     string                      // Directory targets ignore it, but it must not be empty
+	Uploader   streamer.S3StreamUploader   // Writes replicated objects
+	Downloader streamer.S3StreamDownloader // Verifies copies and serves restores
+	Lister     streamer.S3StreamLister     // Optional: skips chunks the target already holds
+}
+
+// validateReplicaTargets checks that every target can be read and is uniquely named
+func validateReplicaTargets(targets []ReplicaTarget) error {
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		switch {
+		case target.Name == "":
+			return ErrReplicaTargetNameEmpty
+		case target.Name == config.PrimaryReplica:
+			return fmt.Errorf("%w: %s", ErrReplicaTargetNameReserved, target.Name)
+		case seen[target.Name]:
+			return fmt.Errorf("%w: %s", ErrReplicaTargetNameDuplicate, target.Name)
+		case target.Downloader == nil:
+			return fmt.Errorf("%w: %s", ErrReplicaTargetDownloaderNil, target.Name)
+		}
+		seen[target.Name] = true
+	}
+	return nil
+}
+
+// ReplicatedBackupper runs the primary backupper and copies its verified backup to each target
+// A target that fails is left out of World.Replicas; only a primary failure fails the backup
+type ReplicatedBackupper struct {
+	primary ports.BackupperService
+	source  streamer.S3StreamDownloader // Reads the primary copy
+	bucket  string                      // Primary bucket
+	targets []ReplicaTarget
+	events  chan<- ports.Event
+}
+
+// Compile-time check to ensure ReplicatedBackupper implements ports.BackupperService
+var _ ports.BackupperService = (*ReplicatedBackupper)(nil)
+
+// NewReplicatedBackupper creates a backupper that fans the primary backup out to targets
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewReplicatedBackupper(
+	primary ports.BackupperService,
+	source streamer.S3StreamDownloader,
+	bucket string,
+	targets []ReplicaTarget,
+	events chan<- ports.Event,
+) (*ReplicatedBackupper, error) {
+	if primary == nil {
+		return nil, ErrReplicatedPrimaryNil
+	}
+	if source == nil {
+		return nil, ErrReplicatedSourceNil
+	}
+	if err := validateReplicaTargets(targets); err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		if target.Uploader == nil {
+			return nil, fmt.Errorf("%w: %s", ErrReplicaTargetUploaderNil, target.Name)
+		}
+	}
+
+	return &ReplicatedBackupper{
+		primary: primary,
+		source:  source,
+		bucket:  bucket,
+		targets: targets,
+		events:  events,
+	}, nil
+}
+
+// send safely sends an event to the channel
+func (b *ReplicatedBackupper) send(evt ports.Event) {
+	ports.SendEvent(b.events, evt)
+}
+
+// Run backs up to the primary remote, then replicates the verified backup to every target
+// Returns the world entry with the targets holding a verified copy in Replicas
+// Returns nil if the primary backupper skipped the backup
+func (b *ReplicatedBackupper) Run(ctx context.Context) (*domain.World, error) {
+	if b == nil {
+		return nil, ErrReplicatedBackupperNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	world, err := b.primary.Run(ctx)
+	if err != nil || world == nil {
+		return world, err
+	}
+	world.Replicas = []string{config.PrimaryReplica}
+	if world.Size <= 0 && world.Checksum == "" {
+		b.send(ports.ErrorEvent{Operation: "replicate", Err: fmt.Errorf("%w: %s", ErrReplicatedBackupNotReplicable, world.URI)})
+		return world, nil
+	}
+
+	for _, target := range b.targets {
+		b.send(ports.UpdateEvent{Operation: "replicate", Message: "Replicating backup", Data: map[string]any{"key": world.URI, "target": target.Name}})
+		err := streamer.Replicate(ctx, streamer.ReplicateConfig{
+			SourceBucket: b.bucket,
+			Bucket:       target.Bucket,
+			Key:          world.URI,
+			Size:         world.Size,
+			Checksum:     world.Checksum,
+			Events:       b.events,
+		}, b.source, target.Uploader, target.Downloader, target.Lister)
+		if err != nil {
+			// Cancellation ends the exit phase; the primary copy is still recorded
+			if ctx.Err() != nil {
+				return world, nil
+			}
+			b.send(ports.ErrorEvent{Operation: "replicate", Err: fmt.Errorf("replica %s failed: %w", target.Name, err)})
+			continue
+		}
+		world.Replicas = append(world.Replicas, target.Name)
+	}
+
+	b.send(ports.UpdateEvent{Operation: "replicate", Message: "Backup replicated", Data: map[string]any{"key": world.URI, "replicas": world.Replicas}})
+	return world, nil
+}
@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingUploader rejects every upload, like an unreachable target
+type failingUploader struct{}
+
+func (failingUploader) Upload(ctx context.Context, bucket, key string, body io.Reader, _ int64) (int64, error) {
+	return 0, errors.New("target unreachable")
+}
+
+// newReplicaStorage returns a directory-backed storage usable as a replica target
+func newReplicaStorage(t *testing.T) *adapters.FSRepository {
+	t.Helper()
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	storage, err := adapters.NewFSRepository(root)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+func TestNewReplicatedBackupper(t *testing.T) {
+	primary := &mocks.MockBackupperService{}
+	source := newReplicaStorage(t)
+	nas := services.ReplicaTarget{Name: "nas", Bucket: "local", Uploader: source, Downloader: source}
+
+	_, err := services.NewReplicatedBackupper(nil, source, "bucket", nil, nil)
+	assert.ErrorIs(t, err, services.ErrReplicatedPrimaryNil)
+	_, err = services.NewReplicatedBackupper(primary, nil, "bucket", nil, nil)
+	assert.ErrorIs(t, err, services.ErrReplicatedSourceNil)
+	_, err = services.NewReplicatedBackupper(primary, source, "bucket", []services.ReplicaTarget{{Name: "nas", Downloader: source}}, nil)
+	assert.ErrorIs(t, err, services.ErrReplicaTargetUploaderNil)
+	_, err = services.NewReplicatedBackupper(primary, source, "bucket", []services.ReplicaTarget{{Uploader: source, Downloader: source}}, nil)
+	assert.ErrorIs(t, err, services.ErrReplicaTargetNameEmpty)
+
+	backupper, err := services.NewReplicatedBackupper(primary, source, "bucket", []services.ReplicaTarget{nas}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, backupper)
+}
+
+func TestReplicatedBackupper_Run(t *testing.T) {
+	ctx := context.Background()
+	uri := config.RemoteBackups + "/20240101000000" + config.BackupExtension
+	archive := markerWorldTar(t, "replicated")
+
+	source := newReplicaStorage(t)
+	require.NoError(t, source.Put(ctx, uri, archive))
+	primary := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+		return &domain.World{URI: uri, CreatedAt: time.Now(), Size: int64(len(archive)), Checksum: fmt.Sprintf("%x", sha256.Sum256(archive))}, nil
+	}}
+
+	t.Run("records the targets that hold a verified copy", func(t *testing.T) {
+		nas := newReplicaStorage(t)
+		targets := []services.ReplicaTarget{
+			{Name: "nas", Bucket: "local", Uploader: nas, Downloader: nas, Lister: nas},
+			{Name: "offsite", Bucket: "offsite", Uploader: failingUploader{}, Downloader: nas},
+		}
+		backupper, err := services.NewReplicatedBackupper(primary, source, "bucket", targets, nil)
+		require.NoError(t, err)
+
+		world, err := backupper.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{config.PrimaryReplica, "nas"}, world.Replicas)
+		assert.True(t, world.HeldBy("nas"))
+		assert.False(t, world.HeldBy("offsite"))
+
+		copied, err := nas.Get(ctx, uri)
+		require.NoError(t, err)
+		assert.Equal(t, archive, copied)
+	})
+
+	t.Run("skipped backup is not replicated", func(t *testing.T) {
+		nas := newReplicaStorage(t)
+		skipped := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) { return nil, nil }}
+		backupper, err := services.NewReplicatedBackupper(skipped, source, "bucket", []services.ReplicaTarget{{Name: "nas", Bucket: "local", Uploader: nas, Downloader: nas}}, nil)
+		require.NoError(t, err)
+
+		world, err := backupper.Run(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, world)
+		keys, err := nas.List(ctx, config.RemoteBackups)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("primary failure fails the backup", func(t *testing.T) {
+		failing := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			return nil, errors.New("upload failed")
+		}}
+		backupper, err := services.NewReplicatedBackupper(failing, source, "bucket", nil, nil)
+		require.NoError(t, err)
+
+		_, err = backupper.Run(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// Checkpointer error constants
+var (
+	ErrCheckpointerNil           = errors.New("checkpointer cannot be nil")
+	ErrCheckpointConsoleNil      = errors.New("server console cannot be nil")
+	ErrCheckpointBackupperNil    = errors.New("backupper cannot be nil")
+	ErrCheckpointLibrarianNil    = errors.New("librarian service cannot be nil")
+	ErrCheckpointIntervalInvalid = errors.New("checkpoint interval must be positive")
+	ErrCheckpointLockLost        = errors.New("manifest lock is no longer held by this session")
+)
+
+// Checkpointer backs up the world at a fixed interval while the server runs
+// Each checkpoint is recorded in both manifests as an intermediate world entry
+type Checkpointer struct {
+	console   ports.ServerConsole
+	backupper ports.BackupperService
+	librarian ports.LibrarianService
+	interval  time.Duration
+	flushWait time.Duration // Time given to save-all before archiving
+	started   time.Time     // When Run began; zero when checkpoints are taken directly
+	events    chan<- ports.Event
+}
+
+// Compile-time check to ensure Checkpointer implements ports.CheckpointService
+var _ ports.CheckpointService = (*Checkpointer)(nil)
+
+// NewCheckpointer creates a new in-session checkpointer
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewCheckpointer(
+	console ports.ServerConsole,
+	backupper ports.BackupperService,
+	librarian ports.LibrarianService,
+	interval time.Duration,
+	events chan<- ports.Event,
+) (*Checkpointer, error) {
+	if console == nil {
+		return nil, ErrCheckpointConsoleNil
+	}
+	if backupper == nil {
+		return nil, ErrCheckpointBackupperNil
+	}
+	if librarian == nil {
+		return nil, ErrCheckpointLibrarianNil
+	}
+	if interval <= 0 {
+		return nil, ErrCheckpointIntervalInvalid
+	}
+
+	return &Checkpointer{
+		console:   console,
+		backupper: backupper,
+		librarian: librarian,
+		interval:  interval,
+		flushWait: time.Duration(config.CheckpointFlushWaitSec) * time.Second,
+		events:    events,
+	}, nil
+}
+
+// SetFlushWaitForTesting sets the time given to save-all (for testing only)
+func (c *Checkpointer) SetFlushWaitForTesting(wait time.Duration) {
+	c.flushWait = wait
+}
+
+// send safely sends an event to the channel
+func (c *Checkpointer) send(evt ports.Event) {
+	ports.SendEvent(c.events, evt)
+}
+
+// Run takes a checkpoint every interval until ctx is cancelled
+// A failed checkpoint is reported and retried at the next interval; a lost lock stops checkpoints
+func (c *Checkpointer) Run(ctx context.Context, lockID string) {
+	if c == nil || ctx == nil {
+		return
+	}
+
+	c.started = time.Now()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.send(ports.UpdateEvent{Operation: "checkpoint", Message: "In-session checkpoints scheduled", Data: map[string]any{"interval": c.interval.String()}})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A tick can be ready at the same time as the cancellation
+			if ctx.Err() != nil {
+				return
+			}
+			_, err := c.Checkpoint(ctx, lockID)
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			c.send(ports.ErrorEvent{Operation: "checkpoint", Err: err})
+			if errors.Is(err, ErrCheckpointLockLost) {
+				return
+			}
+		}
+	}
+}
+
+// Checkpoint backs up the running server's world and records it as an intermediate entry
+// Returns nil if the backupper skipped the backup
+func (c *Checkpointer) Checkpoint(ctx context.Context, lockID string) (*domain.World, error) {
+	if c == nil {
+		return nil, ErrCheckpointerNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+	if lockID == "" {
+		return nil, ErrCheckpointLockLost
+	}
+
+	// Do not spend a backup on a session that no longer holds the lock
+	remoteManifest, err := c.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote manifest: %w", err)
+	}
+	if remoteManifest.LockedBy != lockID {
+		return nil, fmt.Errorf("%w: remote manifest locked by %q", ErrCheckpointLockLost, remoteManifest.LockedBy)
+	}
+
+	c.send(ports.StartEvent{Operation: "checkpoint"})
+	world, err := c.backup(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint backup failed: %w", err)
+	}
+	if world == nil {
+		c.send(ports.UpdateEvent{Operation: "checkpoint", Message: "Checkpoint skipped by backupper"})
+		c.send(ports.FinishEvent{Operation: "checkpoint"})
+		return nil, nil
+	}
+
+	world.Intermediate = true
+	if !c.started.IsZero() {
+		world.SessionSeconds = int64(time.Since(c.started).Round(time.Second) / time.Second)
+	}
+	if err := c.record(ctx, lockID, world); err != nil {
+		return nil, err
+	}
+
+	c.send(ports.UpdateEvent{Operation: "checkpoint", Message: "Checkpoint recorded", Data: map[string]any{
+		"archive_name": world.URI,
+		"size":         world.Size,
+	}})
+	c.send(ports.FinishEvent{Operation: "checkpoint"})
+	return world, nil
+}
+
+// backup archives the world with saving turned off so files do not change mid-archive
+// Saving is turned back on even when the backup fails
+func (c *Checkpointer) backup(ctx context.Context) (*domain.World, error) {
+	if err := c.console.SendCommand(config.ServerSaveOffCommand); err != nil {
+		return nil, fmt.Errorf("failed to turn off saving: %w", err)
+	}
+	defer func() {
+		if err := c.console.SendCommand(config.ServerSaveOnCommand); err != nil {
+			c.send(ports.ErrorEvent{Operation: "checkpoint", Err: fmt.Errorf("failed to turn saving back on: %w", err)})
+		}
+	}()
+
+	if err := c.console.SendCommand(config.ServerSaveAllCommand); err != nil {
+		return nil, fmt.Errorf("failed to save the world: %w", err)
+	}
+
+	// The console does not report when the save finishes, so give it time to reach disk
+	c.send(ports.UpdateEvent{Operation: "checkpoint", Message: "Waiting for the world to be saved", Data: map[string]any{"wait": c.flushWait.String()}})
+	timer := time.NewTimer(c.flushWait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+
+	return c.backupper.Run(ctx)
+}
+
+// record adds the checkpoint to the local manifest and mirrors it to the remote manifest
+// The remote manifest is only written while lockID still holds it
+func (c *Checkpointer) record(ctx context.Context, lockID string, world *domain.World) error {
+	localManifest, err := c.librarian.GetLocalManifest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read local manifest: %w", err)
+	}
+
+	// The uploaded archive is left for retention to delete as dangling if the lock was lost meanwhile
+	remoteManifest, err := c.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read remote manifest: %w", err)
+	}
+	if remoteManifest.LockedBy != lockID {
+		return fmt.Errorf("%w: remote manifest locked by %q", ErrCheckpointLockLost, remoteManifest.LockedBy)
+	}
+
+	localManifest.AddWorld(*world)
+	localManifest.RitualVersion = config.AppVersion
+	if err := c.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+		return fmt.Errorf("failed to record checkpoint in local manifest: %w", err)
+	}
+	if err := c.librarian.SaveRemoteManifest(ctx, localManifest); err != nil {
+		return fmt.Errorf("failed to record checkpoint in remote manifest: %w", err)
+	}
+	return nil
+}
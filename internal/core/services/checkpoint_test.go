@@ -0,0 +1,187 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const checkpointLockID = "test-host::1234567890"
+
+// setupCheckpointer stores manifests locked by lockedBy and returns a checkpointer over them
+func setupCheckpointer(t *testing.T, backupper *mocks.MockBackupperService, lockedBy string, interval time.Duration) (*services.Checkpointer, *mocks.MockServerConsole, *adapters.FSRepository, *adapters.FSRepository) {
+	t.Helper()
+	ctx := context.Background()
+	localStorage := newReplicaStorage(t)
+	remoteStorage := newReplicaStorage(t)
+
+	manifest := createTestManifest("1.0.0", "1.0.0", []domain.World{createTestWorld(config.RemoteBackups + "/20240101120000.tar")})
+	manifest.Lock(lockedBy)
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, localStorage.Put(ctx, config.ManifestFilename, data))
+	require.NoError(t, remoteStorage.Put(ctx, config.ManifestFilename, data))
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+
+	console := mocks.NewMockServerConsole()
+	console.On("SendCommand", mock.Anything).Return(nil)
+
+	checkpointer, err := services.NewCheckpointer(console, backupper, librarian, interval, nil)
+	require.NoError(t, err)
+	checkpointer.SetFlushWaitForTesting(0)
+	return checkpointer, console, localStorage, remoteStorage
+}
+
+// sentCommands lists the console commands in the order they were sent
+func sentCommands(console *mocks.MockServerConsole) []string {
+	var commands []string
+	for _, call := range console.Calls {
+		commands = append(commands, call.Arguments.String(0))
+	}
+	return commands
+}
+
+func TestNewCheckpointer(t *testing.T) {
+	console := mocks.NewMockServerConsole()
+	backupper := &mocks.MockBackupperService{}
+	librarian := &mocks.MockLibrarianService{}
+
+	_, err := services.NewCheckpointer(nil, backupper, librarian, time.Hour, nil)
+	assert.ErrorIs(t, err, services.ErrCheckpointConsoleNil)
+	_, err = services.NewCheckpointer(console, nil, librarian, time.Hour, nil)
+	assert.ErrorIs(t, err, services.ErrCheckpointBackupperNil)
+	_, err = services.NewCheckpointer(console, backupper, nil, time.Hour, nil)
+	assert.ErrorIs(t, err, services.ErrCheckpointLibrarianNil)
+	_, err = services.NewCheckpointer(console, backupper, librarian, 0, nil)
+	assert.ErrorIs(t, err, services.ErrCheckpointIntervalInvalid)
+
+	checkpointer, err := services.NewCheckpointer(console, backupper, librarian, time.Hour, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, checkpointer)
+
+	var nilCheckpointer *services.Checkpointer
+	_, err = nilCheckpointer.Checkpoint(context.Background(), checkpointLockID)
+	assert.ErrorIs(t, err, services.ErrCheckpointerNil)
+}
+
+func TestCheckpointer_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backs up with saving off and records an intermediate world", func(t *testing.T) {
+		uri := config.RemoteBackups + "/20240101130000.tar"
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			return &domain.World{URI: uri, CreatedAt: time.Now(), Size: 42}, nil
+		}}
+		checkpointer, console, localStorage, remoteStorage := setupCheckpointer(t, backupper, checkpointLockID, time.Hour)
+
+		world, err := checkpointer.Checkpoint(ctx, checkpointLockID)
+		require.NoError(t, err)
+		require.NotNil(t, world)
+		assert.True(t, world.Intermediate)
+		assert.Equal(t, []string{config.ServerSaveOffCommand, config.ServerSaveAllCommand, config.ServerSaveOnCommand}, sentCommands(console))
+
+		for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+			manifest := readTestManifest(t, storage)
+			recorded := manifest.FindBackup(uri)
+			require.NotNil(t, recorded)
+			assert.True(t, recorded.Intermediate)
+			assert.Equal(t, int64(42), recorded.Size)
+			assert.Equal(t, checkpointLockID, manifest.LockedBy, "the session keeps the lock")
+		}
+	})
+
+	t.Run("lost lock skips the backup", func(t *testing.T) {
+		backupCalled := false
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			backupCalled = true
+			return nil, nil
+		}}
+		checkpointer, console, _, _ := setupCheckpointer(t, backupper, "other-host::1", time.Hour)
+
+		_, err := checkpointer.Checkpoint(ctx, checkpointLockID)
+		assert.ErrorIs(t, err, services.ErrCheckpointLockLost)
+		assert.False(t, backupCalled)
+		assert.Empty(t, sentCommands(console))
+	})
+
+	t.Run("failed backup turns saving back on", func(t *testing.T) {
+		backupErr := errors.New("upload failed")
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			return nil, backupErr
+		}}
+		checkpointer, console, _, remoteStorage := setupCheckpointer(t, backupper, checkpointLockID, time.Hour)
+
+		_, err := checkpointer.Checkpoint(ctx, checkpointLockID)
+		assert.ErrorIs(t, err, backupErr)
+		assert.Equal(t, config.ServerSaveOnCommand, sentCommands(console)[2])
+		assert.Len(t, readTestManifest(t, remoteStorage).Backups, 1)
+	})
+
+	t.Run("skipped backup records nothing", func(t *testing.T) {
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			return nil, nil
+		}}
+		checkpointer, _, _, remoteStorage := setupCheckpointer(t, backupper, checkpointLockID, time.Hour)
+
+		world, err := checkpointer.Checkpoint(ctx, checkpointLockID)
+		assert.NoError(t, err)
+		assert.Nil(t, world)
+		assert.Len(t, readTestManifest(t, remoteStorage).Backups, 1)
+	})
+}
+
+func TestCheckpointer_Run(t *testing.T) {
+	t.Run("takes checkpoints every interval until cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		runs := 0
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			runs++
+			uri := fmt.Sprintf("%s/checkpoint-%d.tar", config.RemoteBackups, runs)
+			if runs == 2 {
+				cancel()
+			}
+			return &domain.World{URI: uri, CreatedAt: time.Now()}, nil
+		}}
+		checkpointer, _, _, remoteStorage := setupCheckpointer(t, backupper, checkpointLockID, 10*time.Millisecond)
+
+		checkpointer.Run(ctx, checkpointLockID)
+		assert.Equal(t, 2, runs)
+
+		recorded := readTestManifest(t, remoteStorage).FindBackup(config.RemoteBackups + "/checkpoint-1.tar")
+		require.NotNil(t, recorded)
+		assert.True(t, recorded.Intermediate)
+	})
+
+	t.Run("stops once the lock is lost", func(t *testing.T) {
+		checkpointer, _, _, _ := setupCheckpointer(t, &mocks.MockBackupperService{}, "other-host::1", 10*time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			checkpointer.Run(context.Background(), checkpointLockID)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not stop after the lock was lost")
+		}
+	})
+}
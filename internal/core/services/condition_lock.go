@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
+	"time"
 )
 
 // ManifestLockCondition error constants
@@ -13,31 +15,42 @@ var (
 	ErrLockConditionCtxNil       = errors.New("context cannot be nil")
 	ErrLockConditionLibrarianNil = errors.New("librarian service cannot be nil")
 	ErrManifestLocked            = errors.New("manifest is locked")
+	ErrLockBreakDeclined         = errors.New("expired lock was not broken")
 )
 
 // ManifestLockCondition checks if the remote manifest is unlocked
+// A lock whose lease has expired is offered for breaking after confirmation
 type ManifestLockCondition struct {
 	librarian ports.LibrarianService
+	events    chan<- ports.Event
 }
 
 // Compile-time check to ensure ManifestLockCondition implements ports.ConditionService
 var _ ports.ConditionService = (*ManifestLockCondition)(nil)
 
 // NewManifestLockCondition creates a new manifest lock condition
+// events is used to confirm breaking expired locks; nil disables breaking
 // Validates all dependencies are non-nil per NASA JPL defensive programming standards
-func NewManifestLockCondition(librarian ports.LibrarianService) (*ManifestLockCondition, error) {
+func NewManifestLockCondition(librarian ports.LibrarianService, events chan<- ports.Event) (*ManifestLockCondition, error) {
 	if librarian == nil {
 		return nil, ErrLockConditionLibrarianNil
 	}
 
 	condition := &ManifestLockCondition{
 		librarian: librarian,
+		events:    events,
 	}
 
 	return condition, nil
 }
 
+// send safely sends an event to the channel
+func (c *ManifestLockCondition) send(evt ports.Event) {
+	ports.SendEvent(c.events, evt)
+}
+
 // Check validates that the remote manifest is not locked
+// An expired lease is broken if the operator confirms it
 func (c *ManifestLockCondition) Check(ctx context.Context) error {
 	if c == nil {
 		return ErrLockConditionNil
@@ -54,9 +67,70 @@ func (c *ManifestLockCondition) Check(ctx context.Context) error {
 		return errors.New("remote manifest cannot be nil")
 	}
 
-	if remoteManifest.IsLocked() {
+	lease, leaseErr := c.librarian.GetRemoteLease(ctx)
+	if !remoteManifest.IsLocked() {
+		if leaseErr == nil && lease != nil && !lease.IsExpired(time.Now()) {
+			return fmt.Errorf("%w: lease held by %s until %s", ErrManifestLocked, lease.Owner, lease.ExpiresAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	// Without a lease there is no way to tell a live session from a crashed one
+	if leaseErr != nil || lease == nil {
 		return fmt.Errorf("%w by %s", ErrManifestLocked, remoteManifest.LockedBy)
 	}
 
+	now := time.Now()
+	if !lease.IsExpired(now) {
+		return fmt.Errorf("%w by %s (lease expires %s)", ErrManifestLocked, remoteManifest.LockedBy, lease.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if c.events == nil {
+		return fmt.Errorf("%w by %s (lease expired %s)", ErrManifestLocked, remoteManifest.LockedBy, lease.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if !c.confirmBreak(remoteManifest, lease, now) {
+		return fmt.Errorf("%w: %w by %s", ErrManifestLocked, ErrLockBreakDeclined, remoteManifest.LockedBy)
+	}
+
+	return c.breakLock(ctx, remoteManifest, lease)
+}
+
+// confirmBreak asks the operator whether an expired lock should be broken
+func (c *ManifestLockCondition) confirmBreak(manifest *domain.Manifest, lease *domain.Lease, now time.Time) bool {
+	prompt := fmt.Sprintf(
+		"Lock held by %s (session %s) expired %s ago. Break it? Only do this if %s is not running a server",
+		lease.Owner, manifest.LockedBy, now.Sub(lease.ExpiresAt).Round(time.Second), lease.Owner,
+	)
+	return promptConfirm(c.events, "break_expired_lock", prompt)
+}
+
+// breakLock clears the expired lock from the remote manifest and removes the lease
+// The local manifest is cleared too when this machine held the broken session
+func (c *ManifestLockCondition) breakLock(ctx context.Context, manifest *domain.Manifest, lease *domain.Lease) error {
+	brokenLockID := manifest.LockedBy
+	c.send(ports.UpdateEvent{Operation: "lock", Message: "Breaking expired lock", Data: map[string]any{
+		"lock_id": brokenLockID,
+		"owner":   lease.Owner,
+	}})
+
+	// Conditional save fails if another host changed the manifest meanwhile
+	manifest.Unlock()
+	if err := c.librarian.SaveRemoteManifest(ctx, manifest); err != nil {
+		return fmt.Errorf("failed to break expired lock: %w", err)
+	}
+	if err := c.librarian.DeleteRemoteLease(ctx); err != nil {
+		return fmt.Errorf("failed to remove expired lease: %w", err)
+	}
+
+	localManifest, err := c.librarian.GetLocalManifest(ctx)
+	if err == nil && localManifest != nil && localManifest.LockedBy == brokenLockID {
+		localManifest.Unlock()
+		if err := c.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+			return fmt.Errorf("failed to clear local lock: %w", err)
+		}
+	}
+
+	c.send(ports.UpdateEvent{Operation: "lock", Message: "Expired lock broken", Data: map[string]any{"lock_id": brokenLockID}})
 	return nil
 }
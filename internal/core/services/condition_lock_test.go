@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,10 +16,15 @@ import (
 type mockLockLibrarian struct {
 	remoteManifest    *domain.Manifest
 	remoteManifestErr error
+	localManifest     *domain.Manifest
+	lease             *domain.Lease
+	savedRemote       *domain.Manifest
+	savedLocal        *domain.Manifest
+	leaseDeleted      bool
 }
 
 func (m *mockLockLibrarian) GetLocalManifest(ctx context.Context) (*domain.Manifest, error) {
-	return nil, nil
+	return m.localManifest, nil
 }
 
 func (m *mockLockLibrarian) GetRemoteManifest(ctx context.Context) (*domain.Manifest, error) {
@@ -25,23 +32,43 @@ func (m *mockLockLibrarian) GetRemoteManifest(ctx context.Context) (*domain.Mani
 }
 
 func (m *mockLockLibrarian) SaveLocalManifest(ctx context.Context, manifest *domain.Manifest) error {
+	m.savedLocal = manifest
 	return nil
 }
 
 func (m *mockLockLibrarian) SaveRemoteManifest(ctx context.Context, manifest *domain.Manifest) error {
+	m.savedRemote = manifest
+	return nil
+}
+
+func (m *mockLockLibrarian) GetRemoteLease(ctx context.Context) (*domain.Lease, error) {
+	if m.lease == nil {
+		return nil, ports.ErrNotFound
+	}
+	return m.lease, nil
+}
+
+func (m *mockLockLibrarian) SaveRemoteLease(ctx context.Context, lease *domain.Lease) error {
+	m.lease = lease
+	return nil
+}
+
+func (m *mockLockLibrarian) DeleteRemoteLease(ctx context.Context) error {
+	m.lease = nil
+	m.leaseDeleted = true
 	return nil
 }
 
 func TestNewManifestLockCondition(t *testing.T) {
 	t.Run("nil librarian returns error", func(t *testing.T) {
-		_, err := NewManifestLockCondition(nil)
+		_, err := NewManifestLockCondition(nil, nil)
 		assert.Error(t, err)
 		assert.Equal(t, ErrLockConditionLibrarianNil, err)
 	})
 
 	t.Run("valid librarian returns condition", func(t *testing.T) {
 		librarian := &mockLockLibrarian{}
-		condition, err := NewManifestLockCondition(librarian)
+		condition, err := NewManifestLockCondition(librarian, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, condition)
 	})
@@ -85,7 +112,7 @@ func TestManifestLockCondition_Check(t *testing.T) {
 				remoteManifest:    tt.remoteManifest,
 				remoteManifestErr: tt.remoteErr,
 			}
-			condition, err := NewManifestLockCondition(librarian)
+			condition, err := NewManifestLockCondition(librarian, nil)
 			require.NoError(t, err)
 
 			err = condition.Check(context.Background())
@@ -111,7 +138,7 @@ func TestManifestLockCondition_Check_DefensiveValidation(t *testing.T) {
 		librarian := &mockLockLibrarian{
 			remoteManifest: &domain.Manifest{},
 		}
-		condition, err := NewManifestLockCondition(librarian)
+		condition, err := NewManifestLockCondition(librarian, nil)
 		require.NoError(t, err)
 
 		err = condition.Check(nil)
@@ -119,3 +146,117 @@ func TestManifestLockCondition_Check_DefensiveValidation(t *testing.T) {
 		assert.Equal(t, ErrLockConditionCtxNil, err)
 	})
 }
+
+// answerPrompts replies to every prompt event with response
+func answerPrompts(events <-chan ports.Event, response string) {
+	for evt := range events {
+		if prompt, ok := evt.(ports.PromptEvent); ok {
+			prompt.ResponseChan <- response
+		}
+	}
+}
+
+func TestManifestLockCondition_Lease(t *testing.T) {
+	const lockID = "deadhost::1700000000"
+	expiredLease := func() *domain.Lease {
+		return &domain.Lease{
+			Owner:      "deadhost",
+			SessionID:  lockID,
+			AcquiredAt: time.Now().Add(-time.Hour),
+			ExpiresAt:  time.Now().Add(-time.Minute),
+		}
+	}
+
+	t.Run("live lease keeps lock", func(t *testing.T) {
+		lease, err := domain.NewLease("livehost", lockID, time.Minute)
+		require.NoError(t, err)
+		librarian := &mockLockLibrarian{remoteManifest: &domain.Manifest{LockedBy: lockID}, lease: lease}
+		events := make(chan ports.Event, 10)
+		condition, err := NewManifestLockCondition(librarian, events)
+		require.NoError(t, err)
+
+		err = condition.Check(context.Background())
+		assert.ErrorIs(t, err, ErrManifestLocked)
+		assert.Contains(t, err.Error(), "lease expires")
+		assert.Nil(t, librarian.savedRemote)
+	})
+
+	t.Run("live lease on unlocked manifest blocks", func(t *testing.T) {
+		lease, err := domain.NewLease("livehost", lockID, time.Minute)
+		require.NoError(t, err)
+		librarian := &mockLockLibrarian{remoteManifest: &domain.Manifest{}, lease: lease}
+		condition, err := NewManifestLockCondition(librarian, nil)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, condition.Check(context.Background()), ErrManifestLocked)
+	})
+
+	t.Run("expired lease on unlocked manifest passes", func(t *testing.T) {
+		librarian := &mockLockLibrarian{remoteManifest: &domain.Manifest{}, lease: expiredLease()}
+		condition, err := NewManifestLockCondition(librarian, nil)
+		require.NoError(t, err)
+
+		assert.NoError(t, condition.Check(context.Background()))
+	})
+
+	t.Run("expired lease without events fails", func(t *testing.T) {
+		librarian := &mockLockLibrarian{remoteManifest: &domain.Manifest{LockedBy: lockID}, lease: expiredLease()}
+		condition, err := NewManifestLockCondition(librarian, nil)
+		require.NoError(t, err)
+
+		err = condition.Check(context.Background())
+		assert.ErrorIs(t, err, ErrManifestLocked)
+		assert.Contains(t, err.Error(), "lease expired")
+	})
+
+	t.Run("declined break keeps lock", func(t *testing.T) {
+		librarian := &mockLockLibrarian{remoteManifest: &domain.Manifest{LockedBy: lockID}, lease: expiredLease()}
+		events := make(chan ports.Event, 10)
+		go answerPrompts(events, "n")
+		defer close(events)
+		condition, err := NewManifestLockCondition(librarian, events)
+		require.NoError(t, err)
+
+		err = condition.Check(context.Background())
+		assert.ErrorIs(t, err, ErrManifestLocked)
+		assert.ErrorIs(t, err, ErrLockBreakDeclined)
+		assert.Nil(t, librarian.savedRemote)
+		assert.False(t, librarian.leaseDeleted)
+	})
+
+	t.Run("confirmed break clears lock and lease", func(t *testing.T) {
+		librarian := &mockLockLibrarian{
+			remoteManifest: &domain.Manifest{LockedBy: lockID},
+			localManifest:  &domain.Manifest{LockedBy: lockID},
+			lease:          expiredLease(),
+		}
+		events := make(chan ports.Event, 10)
+		go answerPrompts(events, " YES ")
+		defer close(events)
+		condition, err := NewManifestLockCondition(librarian, events)
+		require.NoError(t, err)
+
+		require.NoError(t, condition.Check(context.Background()))
+		require.NotNil(t, librarian.savedRemote)
+		assert.False(t, librarian.savedRemote.IsLocked())
+		assert.True(t, librarian.leaseDeleted)
+		require.NotNil(t, librarian.savedLocal)
+		assert.False(t, librarian.savedLocal.IsLocked())
+	})
+
+	t.Run("confirmed break leaves foreign local lock", func(t *testing.T) {
+		librarian := &mockLockLibrarian{
+			remoteManifest: &domain.Manifest{LockedBy: lockID},
+			localManifest:  &domain.Manifest{LockedBy: "otherhost::1"},
+			lease:          expiredLease(),
+		}
+		events := make(chan ports.Event, 10)
+		go answerPrompts(events, "y")
+		defer close(events)
+		condition, err := NewManifestLockCondition(librarian, events)
+		require.NoError(t, err)
+
+		require.NoError(t, condition.Check(context.Background()))
+		assert.Nil(t, librarian.savedLocal)
+	})
+}
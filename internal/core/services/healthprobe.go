@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+)
+
+// HealthProbe error constants
+var (
+	ErrHealthProbePingerNil       = errors.New("server pinger cannot be nil")
+	ErrHealthProbeIntervalInvalid = errors.New("health probe interval must be positive")
+	ErrServerNotAnswering         = errors.New("server is not answering status requests")
+)
+
+// HealthProbe polls the running server with Server List Ping and reports its status
+// Status updates are sent when the server first answers and whenever players, MOTD or version change
+type HealthProbe struct {
+	pinger           ports.ServerPinger
+	interval         time.Duration
+	startupTimeout   time.Duration // Time the server has to answer its first probe
+	failureThreshold int           // Consecutive failed probes before the server counts as down
+	events           chan<- ports.Event
+}
+
+// Compile-time check to ensure HealthProbe implements ports.HealthProbeService
+var _ ports.HealthProbeService = (*HealthProbe)(nil)
+
+// NewHealthProbe creates a new in-session health probe
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewHealthProbe(pinger ports.ServerPinger, interval time.Duration, events chan<- ports.Event) (*HealthProbe, error) {
+	if pinger == nil {
+		return nil, ErrHealthProbePingerNil
+	}
+	if interval <= 0 {
+		return nil, ErrHealthProbeIntervalInvalid
+	}
+
+	return &HealthProbe{
+		pinger:           pinger,
+		interval:         interval,
+		startupTimeout:   config.HealthProbeStartupTimeout,
+		failureThreshold: config.HealthProbeFailureThreshold,
+		events:           events,
+	}, nil
+}
+
+// SetStartupTimeoutForTesting sets the time the server has to answer its first probe (for testing only)
+func (h *HealthProbe) SetStartupTimeoutForTesting(timeout time.Duration) {
+	h.startupTimeout = timeout
+}
+
+// send safely sends an event to the channel
+func (h *HealthProbe) send(evt ports.Event) {
+	ports.SendEvent(h.events, evt)
+}
+
+// Run pings server every interval until ctx is cancelled
+// Failures while the server starts are expected until the startup timeout passes;
+// once it has answered, failureThreshold failures in a row are reported as the server no longer answering
+func (h *HealthProbe) Run(ctx context.Context, server *domain.Server) {
+	if h == nil || ctx == nil || server == nil {
+		return
+	}
+
+	address := server.StatusAddress()
+	started := time.Now()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	h.send(ports.UpdateEvent{Operation: "health", Message: "Health probe scheduled", Data: map[string]any{"address": address, "interval": h.interval.String()}})
+
+	var last *domain.ServerStatus // Last reported status; nil until the server first answers
+	failures := 0
+	startupReported := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// A tick can be ready at the same time as the cancellation
+		if ctx.Err() != nil {
+			return
+		}
+
+		status, err := h.pinger.Ping(ctx, address)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures++
+			if last == nil {
+				if !startupReported && time.Since(started) >= h.startupTimeout {
+					startupReported = true
+					h.send(ports.ErrorEvent{Operation: "health", Err: fmt.Errorf("%w: no answer at %s within %s: %w", ErrServerNotAnswering, address, h.startupTimeout, err)})
+				}
+				continue
+			}
+			if failures == h.failureThreshold {
+				h.send(ports.ErrorEvent{Operation: "health", Err: fmt.Errorf("%w: %d probes of %s failed in a row: %w", ErrServerNotAnswering, failures, address, err)})
+			}
+			continue
+		}
+
+		switch {
+		case last == nil:
+			h.report("Server is up", status)
+		case failures >= h.failureThreshold:
+			h.report("Server is answering again", status)
+		case *status != *last:
+			h.report("Server status changed", status)
+		}
+		last = status
+		failures = 0
+	}
+}
+
+// report sends status as an update event
+func (h *HealthProbe) report(message string, status *domain.ServerStatus) {
+	h.send(ports.UpdateEvent{Operation: "health", Message: message, Data: map[string]any{
+		"online_players": status.OnlinePlayers,
+		"max_players":    status.MaxPlayers,
+		"motd":           status.MOTD,
+		"version":        status.Version,
+	}})
+}
@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runHealthProbe runs a probe whose pinger answers with results in order, then returns its health events
+// A nil result fails that ping
+// The probe is cancelled at the first ping after the results run out
+func runHealthProbe(t *testing.T, startupTimeout time.Duration, results []*domain.ServerStatus) ([]ports.Event, []string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var addresses []string
+	pinger := &mocks.MockServerPinger{PingFunc: func(ctx context.Context, address string) (*domain.ServerStatus, error) {
+		if len(results) == 0 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		addresses = append(addresses, address)
+		result := results[0]
+		results = results[1:]
+		if result == nil {
+			return nil, errors.New("connection refused")
+		}
+		status := *result
+		return &status, nil
+	}}
+
+	events := make(chan ports.Event, 100)
+	probe, err := services.NewHealthProbe(pinger, time.Millisecond, events)
+	require.NoError(t, err)
+	probe.SetStartupTimeoutForTesting(startupTimeout)
+
+	probe.Run(ctx, &domain.Server{IP: "0.0.0.0", Port: 25565})
+	close(events)
+
+	var health []ports.Event
+	for evt := range events {
+		switch e := evt.(type) {
+		case ports.UpdateEvent:
+			if e.Operation == "health" {
+				health = append(health, e)
+			}
+		case ports.ErrorEvent:
+			if e.Operation == "health" {
+				health = append(health, e)
+			}
+		}
+	}
+	return health, addresses
+}
+
+func TestNewHealthProbe(t *testing.T) {
+	pinger := mocks.NewMockServerPinger()
+
+	_, err := services.NewHealthProbe(nil, time.Second, nil)
+	assert.ErrorIs(t, err, services.ErrHealthProbePingerNil)
+	_, err = services.NewHealthProbe(pinger, 0, nil)
+	assert.ErrorIs(t, err, services.ErrHealthProbeIntervalInvalid)
+
+	probe, err := services.NewHealthProbe(pinger, time.Second, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, probe)
+
+	var nilProbe *services.HealthProbe
+	nilProbe.Run(context.Background(), &domain.Server{IP: "127.0.0.1", Port: 25565})
+}
+
+func TestHealthProbe_Run(t *testing.T) {
+	empty := &domain.ServerStatus{Version: "1.21.1", Protocol: 767, MaxPlayers: 20, MOTD: "Ritual"}
+	busy := &domain.ServerStatus{Version: "1.21.1", Protocol: 767, OnlinePlayers: 2, MaxPlayers: 20, MOTD: "Ritual"}
+
+	t.Run("reports status changes and a server that stops answering", func(t *testing.T) {
+		events, addresses := runHealthProbe(t, time.Hour, []*domain.ServerStatus{
+			nil, nil, // Still starting
+			empty, empty,
+			busy,
+			nil, nil, nil, nil,
+			busy,
+		})
+
+		assert.Equal(t, "127.0.0.1:25565", addresses[0], "a server on every interface is probed over loopback")
+		require.Len(t, events, 5)
+		assert.Equal(t, "Health probe scheduled", events[0].(ports.UpdateEvent).Message)
+
+		up := events[1].(ports.UpdateEvent)
+		assert.Equal(t, "Server is up", up.Message)
+		assert.Equal(t, map[string]any{"online_players": 0, "max_players": 20, "motd": "Ritual", "version": "1.21.1"}, up.Data)
+
+		changed := events[2].(ports.UpdateEvent)
+		assert.Equal(t, "Server status changed", changed.Message)
+		assert.Equal(t, 2, changed.Data["online_players"])
+
+		down, ok := events[3].(ports.ErrorEvent)
+		require.True(t, ok, "an error is raised once the server stops answering")
+		assert.ErrorIs(t, down.Err, services.ErrServerNotAnswering)
+
+		assert.Equal(t, "Server is answering again", events[4].(ports.UpdateEvent).Message)
+	})
+
+	t.Run("raises an error once when the server does not come up", func(t *testing.T) {
+		events, _ := runHealthProbe(t, 0, []*domain.ServerStatus{nil, nil, nil, nil})
+
+		require.Len(t, events, 2)
+		down, ok := events[1].(ports.ErrorEvent)
+		require.True(t, ok)
+		assert.ErrorIs(t, down.Err, services.ErrServerNotAnswering)
+	})
+}
@@ -8,17 +8,26 @@ import (
 	"ritual/internal/config"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
+	"sync"
 )
 
 var (
-	ErrEmptyData   = errors.New("empty data")
-	ErrNilManifest = errors.New("nil manifest")
+	ErrEmptyData        = errors.New("empty data")
+	ErrNilManifest      = errors.New("nil manifest")
+	ErrManifestConflict = errors.New("remote manifest changed since it was read")
+	ErrNilLease         = errors.New("nil lease")
+	ErrLeaseConflict    = errors.New("remote lease changed since it was read")
 )
 
 // LibrarianService implements manifest management and synchronization
+// When remote storage implements ports.VersionedStorage, remote saves are
+// compare-and-swap against the version of the last remote read or write
 type LibrarianService struct {
 	localStorage  ports.StorageRepository
 	remoteStorage ports.StorageRepository
+
+	mu             sync.Mutex
+	remoteVersions map[string]string // Version token per remote key last seen by this librarian
 }
 
 // NewLibrarianService creates a new LibrarianService instance
@@ -30,8 +39,9 @@ func NewLibrarianService(localStorage ports.StorageRepository, remoteStorage por
 		return nil, fmt.Errorf("remoteStorage cannot be nil")
 	}
 	return &LibrarianService{
-		localStorage:  localStorage,
-		remoteStorage: remoteStorage,
+		localStorage:   localStorage,
+		remoteStorage:  remoteStorage,
+		remoteVersions: make(map[string]string),
 	}, nil
 }
 
@@ -68,7 +78,7 @@ func (l *LibrarianService) GetRemoteManifest(ctx context.Context) (*domain.Manif
 	if l.remoteStorage == nil {
 		return nil, fmt.Errorf("remoteStorage repository is nil")
 	}
-	data, err := l.remoteStorage.Get(ctx, config.ManifestFilename)
+	data, err := l.getRemote(ctx, config.ManifestFilename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get remote manifest: %w", err)
 	}
@@ -128,9 +138,126 @@ func (l *LibrarianService) SaveRemoteManifest(ctx context.Context, manifest *dom
 		return fmt.Errorf("failed to marshal manifest: %w", err)
 	}
 
-	if err := l.remoteStorage.Put(ctx, config.ManifestFilename, data); err != nil {
+	if err := l.putRemote(ctx, config.ManifestFilename, data); err != nil {
+		if errors.Is(err, ports.ErrVersionConflict) {
+			return fmt.Errorf("%w: %w", ErrManifestConflict, err)
+		}
 		return fmt.Errorf("failed to save remote manifest: %w", err)
 	}
 
 	return nil
 }
+
+// GetRemoteLease retrieves the remote lock object
+func (l *LibrarianService) GetRemoteLease(ctx context.Context) (*domain.Lease, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+	if l.remoteStorage == nil {
+		return nil, fmt.Errorf("remoteStorage repository is nil")
+	}
+	data, err := l.getRemote(ctx, config.LockFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote lease: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+
+	var lease domain.Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remote lease: %w", err)
+	}
+
+	return &lease, nil
+}
+
+// SaveRemoteLease stores the remote lock object
+func (l *LibrarianService) SaveRemoteLease(ctx context.Context, lease *domain.Lease) error {
+	if ctx == nil {
+		return fmt.Errorf("context cannot be nil")
+	}
+	if l.remoteStorage == nil {
+		return fmt.Errorf("remoteStorage repository is nil")
+	}
+	if lease == nil {
+		return ErrNilLease
+	}
+
+	data, err := json.MarshalIndent(lease, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease: %w", err)
+	}
+
+	if err := l.putRemote(ctx, config.LockFilename, data); err != nil {
+		if errors.Is(err, ports.ErrVersionConflict) {
+			return fmt.Errorf("%w: %w", ErrLeaseConflict, err)
+		}
+		return fmt.Errorf("failed to save remote lease: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRemoteLease removes the remote lock object
+func (l *LibrarianService) DeleteRemoteLease(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("context cannot be nil")
+	}
+	if l.remoteStorage == nil {
+		return fmt.Errorf("remoteStorage repository is nil")
+	}
+
+	// Deleting a lease that is already gone is not an error
+	if err := l.remoteStorage.Delete(ctx, config.LockFilename); err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return fmt.Errorf("failed to delete remote lease: %w", err)
+	}
+
+	// The next save may only create a new lease
+	l.mu.Lock()
+	delete(l.remoteVersions, config.LockFilename)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// getRemote reads a remote object, recording its version when supported
+func (l *LibrarianService) getRemote(ctx context.Context, key string) ([]byte, error) {
+	versioned, ok := l.remoteStorage.(ports.VersionedStorage)
+	if !ok {
+		return l.remoteStorage.Get(ctx, key)
+	}
+
+	data, version, err := versioned.GetWithVersion(ctx, key)
+
+	// After a failed read only creating the object is allowed
+	l.mu.Lock()
+	l.remoteVersions[key] = version
+	l.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// putRemote writes a remote object
+// Versioned storage only accepts the write if the object is unchanged
+// since the last read or write; an unseen object may only be created
+func (l *LibrarianService) putRemote(ctx context.Context, key string, data []byte) error {
+	versioned, ok := l.remoteStorage.(ports.VersionedStorage)
+	if !ok {
+		return l.remoteStorage.Put(ctx, key, data)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	version, err := versioned.PutIfVersion(ctx, key, data, l.remoteVersions[key])
+	if err != nil {
+		return err
+	}
+	l.remoteVersions[key] = version
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
 	"ritual/internal/core/ports/mocks"
@@ -420,3 +421,169 @@ func TestLibrarianService_Integration(t *testing.T) {
 	assert.Equal(t, manifest.RitualVersion, retrievedRemote.RitualVersion)
 	assert.Equal(t, manifest.LockedBy, retrievedRemote.LockedBy)
 }
+
+// versionedMemoryStorage is an in-memory StorageRepository with compare-and-swap writes
+type versionedMemoryStorage struct {
+	mocks.MockStorageRepository
+	data    map[string][]byte
+	version map[string]int
+}
+
+func newVersionedMemoryStorage() *versionedMemoryStorage {
+	return &versionedMemoryStorage{data: map[string][]byte{}, version: map[string]int{}}
+}
+
+func (s *versionedMemoryStorage) GetWithVersion(ctx context.Context, key string) ([]byte, string, error) {
+	data, ok := s.data[key]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ports.ErrNotFound, key)
+	}
+	return data, fmt.Sprint(s.version[key]), nil
+}
+
+func (s *versionedMemoryStorage) Delete(ctx context.Context, key string) error {
+	delete(s.data, key)
+	delete(s.version, key)
+	return nil
+}
+
+func (s *versionedMemoryStorage) PutIfVersion(ctx context.Context, key string, data []byte, version string) (string, error) {
+	_, exists := s.data[key]
+	if (version == "" && exists) || (version != "" && (!exists || version != fmt.Sprint(s.version[key]))) {
+		return "", ports.ErrVersionConflict
+	}
+	s.data[key] = data
+	s.version[key]++
+	return fmt.Sprint(s.version[key]), nil
+}
+
+func TestLibrarianService_SaveRemoteManifestConditional(t *testing.T) {
+	ctx := context.Background()
+	manifest := &domain.Manifest{RitualVersion: "1.0.0", InstanceVersion: "1.0.0", Backups: []domain.World{}}
+
+	t.Run("unseen manifest can only be created", func(t *testing.T) {
+		remote := newVersionedMemoryStorage()
+		first, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+		second, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+
+		assert.NoError(t, first.SaveRemoteManifest(ctx, manifest))
+
+		err = second.SaveRemoteManifest(ctx, manifest)
+		assert.ErrorIs(t, err, ErrManifestConflict)
+		assert.ErrorIs(t, err, ports.ErrVersionConflict)
+	})
+
+	t.Run("concurrent lock attempts after the same read", func(t *testing.T) {
+		remote := newVersionedMemoryStorage()
+		seed, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+		assert.NoError(t, seed.SaveRemoteManifest(ctx, manifest))
+
+		hostA, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+		hostB, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+
+		manifestA, err := hostA.GetRemoteManifest(ctx)
+		assert.NoError(t, err)
+		manifestB, err := hostB.GetRemoteManifest(ctx)
+		assert.NoError(t, err)
+		assert.False(t, manifestA.IsLocked())
+		assert.False(t, manifestB.IsLocked())
+
+		manifestA.Lock("host-a::1")
+		assert.NoError(t, hostA.SaveRemoteManifest(ctx, manifestA))
+
+		manifestB.Lock("host-b::2")
+		assert.ErrorIs(t, hostB.SaveRemoteManifest(ctx, manifestB), ErrManifestConflict)
+
+		current, err := hostB.GetRemoteManifest(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "host-a::1", current.LockedBy)
+	})
+
+	t.Run("own writes advance the version", func(t *testing.T) {
+		remote := newVersionedMemoryStorage()
+		librarian, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+
+		assert.NoError(t, librarian.SaveRemoteManifest(ctx, manifest))
+		assert.NoError(t, librarian.SaveRemoteManifest(ctx, manifest))
+		_, err = librarian.GetRemoteManifest(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, librarian.SaveRemoteManifest(ctx, manifest))
+	})
+}
+
+func TestLibrarianService_RemoteLease(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing lease wraps ErrNotFound", func(t *testing.T) {
+		librarian, err := NewLibrarianService(mocks.NewMockStorageRepository(), newVersionedMemoryStorage())
+		assert.NoError(t, err)
+
+		lease, err := librarian.GetRemoteLease(ctx)
+		assert.Nil(t, lease)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+
+	t.Run("nil lease returns error", func(t *testing.T) {
+		librarian, err := NewLibrarianService(mocks.NewMockStorageRepository(), newVersionedMemoryStorage())
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, librarian.SaveRemoteLease(ctx, nil), ErrNilLease)
+	})
+
+	t.Run("save renew and delete round trip", func(t *testing.T) {
+		remote := newVersionedMemoryStorage()
+		librarian, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+
+		lease, err := domain.NewLease("host", "host::1", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, librarian.SaveRemoteLease(ctx, lease))
+
+		lease.Renew(time.Now(), time.Hour)
+		assert.NoError(t, librarian.SaveRemoteLease(ctx, lease))
+
+		stored, err := librarian.GetRemoteLease(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "host::1", stored.SessionID)
+		assert.True(t, stored.ExpiresAt.Equal(lease.ExpiresAt))
+
+		assert.NoError(t, librarian.DeleteRemoteLease(ctx))
+		_, err = librarian.GetRemoteLease(ctx)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+
+		// After delete the next save creates a fresh lease
+		assert.NoError(t, librarian.SaveRemoteLease(ctx, lease))
+	})
+
+	t.Run("second host cannot create or overwrite lease", func(t *testing.T) {
+		remote := newVersionedMemoryStorage()
+		hostA, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+		hostB, err := NewLibrarianService(mocks.NewMockStorageRepository(), remote)
+		assert.NoError(t, err)
+
+		leaseA, err := domain.NewLease("a", "a::1", time.Minute)
+		assert.NoError(t, err)
+		leaseB, err := domain.NewLease("b", "b::1", time.Minute)
+		assert.NoError(t, err)
+
+		_, err = hostB.GetRemoteLease(ctx)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+
+		assert.NoError(t, hostA.SaveRemoteLease(ctx, leaseA))
+		err = hostB.SaveRemoteLease(ctx, leaseB)
+		assert.ErrorIs(t, err, ErrLeaseConflict)
+
+		// A renewal by A after B read the lease invalidates B's version
+		_, err = hostB.GetRemoteLease(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, hostA.SaveRemoteLease(ctx, leaseA))
+		assert.ErrorIs(t, hostB.SaveRemoteLease(ctx, leaseB), ErrLeaseConflict)
+	})
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"time"
+)
+
+// LockBreaker error constants
+var (
+	ErrLockBreakerNil          = errors.New("lock breaker cannot be nil")
+	ErrLockBreakerLibrarianNil = errors.New("librarian service cannot be nil")
+	ErrLockBreakerStorageNil   = errors.New("remote storage cannot be nil")
+	ErrManifestNotLocked       = errors.New("remote manifest is not locked")
+)
+
+// LockBreaker clears orphaned manifest locks on operator request
+// Every broken lock is appended to the remote audit log
+type LockBreaker struct {
+	librarian     ports.LibrarianService
+	remoteStorage ports.StorageRepository
+	events        chan<- ports.Event
+	now           func() time.Time // Clock (replaceable in tests)
+}
+
+// NewLockBreaker creates a new LockBreaker instance
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewLockBreaker(librarian ports.LibrarianService, remoteStorage ports.StorageRepository, events chan<- ports.Event) (*LockBreaker, error) {
+	if librarian == nil {
+		return nil, ErrLockBreakerLibrarianNil
+	}
+	if remoteStorage == nil {
+		return nil, ErrLockBreakerStorageNil
+	}
+
+	return &LockBreaker{
+		librarian:     librarian,
+		remoteStorage: remoteStorage,
+		events:        events,
+		now:           time.Now,
+	}, nil
+}
+
+// send safely sends an event to the channel
+func (b *LockBreaker) send(evt ports.Event) {
+	ports.SendEvent(b.events, evt)
+}
+
+// Break shows the current lock holder, asks for confirmation and clears the lock
+// The local manifest is unlocked too when this machine holds the lock
+// Returns ErrManifestNotLocked if there is nothing to break
+func (b *LockBreaker) Break(ctx context.Context) (*domain.LockAuditRecord, error) {
+	if b == nil {
+		return nil, ErrLockBreakerNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	b.send(ports.StartEvent{Operation: "unlock"})
+
+	remoteManifest, err := b.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote manifest: %w", err)
+	}
+	if remoteManifest == nil {
+		return nil, errors.New("remote manifest cannot be nil")
+	}
+	if !remoteManifest.IsLocked() {
+		return nil, ErrManifestNotLocked
+	}
+
+	lockID := remoteManifest.LockedBy
+	now := b.now()
+	record := &domain.LockAuditRecord{LockID: lockID, Holder: "unknown"}
+
+	holder, lockedAt, parseErr := domain.ParseLockID(lockID)
+	if parseErr == nil {
+		record.Holder = holder
+		record.LockedAt = lockedAt
+	}
+
+	prompt := fmt.Sprintf("Manifest is locked by %s", record.Holder)
+	if parseErr == nil {
+		prompt += fmt.Sprintf(" since %s (%s ago)", lockedAt.Format(time.RFC3339), now.Sub(lockedAt).Round(time.Second))
+	}
+	lease, leaseErr := b.librarian.GetRemoteLease(ctx)
+	if leaseErr == nil && lease != nil && lease.SessionID == lockID && !lease.IsExpired(now) {
+		prompt += fmt.Sprintf(". Its lease is still renewed until %s, the server may be running", lease.ExpiresAt.Format(time.RFC3339))
+	}
+	prompt += ". Break the lock?"
+
+	if !promptConfirm(b.events, "break_lock", prompt) {
+		return nil, ErrLockBreakDeclined
+	}
+
+	// Conditional save fails if the holder changed the manifest meanwhile
+	remoteManifest.Unlock()
+	if err := b.librarian.SaveRemoteManifest(ctx, remoteManifest); err != nil {
+		return nil, fmt.Errorf("failed to unlock remote manifest: %w", err)
+	}
+	b.send(ports.UpdateEvent{Operation: "unlock", Message: "Cleared remote manifest lock", Data: map[string]any{"lock_id": lockID}})
+
+	if leaseErr == nil && lease != nil && lease.SessionID == lockID {
+		if err := b.librarian.DeleteRemoteLease(ctx); err != nil {
+			b.send(ports.ErrorEvent{Operation: "unlock", Err: fmt.Errorf("failed to remove remote lease: %w", err)})
+		}
+	}
+
+	// The remote lock is gone from here on, so the break is audited even if the local unlock fails
+	var localErr error
+	localManifest, err := b.librarian.GetLocalManifest(ctx)
+	if err == nil && localManifest != nil && localManifest.LockedBy == lockID {
+		localManifest.Unlock()
+		if err := b.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+			localErr = fmt.Errorf("remote lock cleared but failed to unlock local manifest: %w", err)
+			b.send(ports.ErrorEvent{Operation: "unlock", Err: localErr})
+		} else {
+			record.LocalCleared = true
+			b.send(ports.UpdateEvent{Operation: "unlock", Message: "Cleared local manifest lock"})
+		}
+	}
+
+	record.BrokenBy, err = os.Hostname()
+	if err != nil {
+		record.BrokenBy = "unknown"
+	}
+	record.BrokenAt = b.now()
+
+	if err := b.appendAudit(ctx, record); err != nil {
+		return record, errors.Join(localErr, fmt.Errorf("lock cleared but failed to write audit record: %w", err))
+	}
+	b.send(ports.UpdateEvent{Operation: "unlock", Message: "Recorded lock break in audit log", Data: map[string]any{"key": config.LockAuditKey}})
+	if localErr != nil {
+		return record, localErr
+	}
+
+	b.send(ports.FinishEvent{Operation: "unlock"})
+	return record, nil
+}
+
+// appendAudit appends record as a JSON line to the remote audit log
+// With versioned storage the append is retried when another host wrote concurrently
+func (b *LockBreaker) appendAudit(ctx context.Context, record *domain.LockAuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	versioned, ok := b.remoteStorage.(ports.VersionedStorage)
+	if !ok {
+		existing, err := b.remoteStorage.Get(ctx, config.LockAuditKey)
+		if err != nil && !errors.Is(err, ports.ErrNotFound) {
+			return err
+		}
+		return b.remoteStorage.Put(ctx, config.LockAuditKey, appendLine(existing, line))
+	}
+
+	for attempt := 0; attempt < config.LockAuditAppendAttempts; attempt++ {
+		existing, version, err := versioned.GetWithVersion(ctx, config.LockAuditKey)
+		if err != nil && !errors.Is(err, ports.ErrNotFound) {
+			return err
+		}
+		if err != nil {
+			version = ""
+		}
+
+		_, err = versioned.PutIfVersion(ctx, config.LockAuditKey, appendLine(existing, line), version)
+		if !errors.Is(err, ports.ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: gave up after %d attempts", ports.ErrVersionConflict, config.LockAuditAppendAttempts)
+}
+
+// appendLine joins line to existing, adding a missing trailing newline first
+func appendLine(existing, line []byte) []byte {
+	data := make([]byte, 0, len(existing)+len(line)+1)
+	data = append(data, existing...)
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	return append(data, line...)
+}
@@ -0,0 +1,236 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLockBreaker creates a lock breaker over FS storages with the given manifest locks
+// Prompts are answered with response
+func setupLockBreaker(t *testing.T, remoteLock, localLock, response string) (*services.LockBreaker, *adapters.FSRepository, *adapters.FSRepository) {
+	t.Helper()
+	return setupLockBreakerWithLibrarian(t, remoteLock, localLock, response, nil)
+}
+
+// setupLockBreakerWithLibrarian is setupLockBreaker with the librarian passed through wrap when it is non-nil
+func setupLockBreakerWithLibrarian(t *testing.T, remoteLock, localLock, response string, wrap func(ports.LibrarianService) ports.LibrarianService) (*services.LockBreaker, *adapters.FSRepository, *adapters.FSRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	localRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	localStorage, err := adapters.NewFSRepository(localRoot)
+	require.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		localStorage.Close()
+		remoteStorage.Close()
+	})
+
+	for storage, lock := range map[*adapters.FSRepository]string{localStorage: localLock, remoteStorage: remoteLock} {
+		manifest := createTestManifest("1.0.0", "1.0.0", []domain.World{})
+		manifest.LockedBy = lock
+		data, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		require.NoError(t, storage.Put(ctx, config.ManifestFilename, data))
+	}
+
+	events := make(chan ports.Event, 100)
+	go func() {
+		for evt := range events {
+			if prompt, ok := evt.(ports.PromptEvent); ok {
+				prompt.ResponseChan <- response
+			}
+		}
+	}()
+	t.Cleanup(func() { close(events) })
+
+	librarianService, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	var librarian ports.LibrarianService = librarianService
+	if wrap != nil {
+		librarian = wrap(librarianService)
+	}
+	breaker, err := services.NewLockBreaker(librarian, remoteStorage, events)
+	require.NoError(t, err)
+
+	return breaker, localStorage, remoteStorage
+}
+
+// readManifestLock returns LockedBy of the manifest in storage
+func readManifestLock(t *testing.T, storage *adapters.FSRepository) string {
+	t.Helper()
+	data, err := storage.Get(context.Background(), config.ManifestFilename)
+	require.NoError(t, err)
+	var manifest domain.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return manifest.LockedBy
+}
+
+// readAuditRecords parses all records in the remote audit log
+func readAuditRecords(t *testing.T, storage *adapters.FSRepository) []domain.LockAuditRecord {
+	t.Helper()
+	data, err := storage.Get(context.Background(), config.LockAuditKey)
+	require.NoError(t, err)
+
+	var records []domain.LockAuditRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record domain.LockAuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// failingLocalLibrarian fails every local manifest save with err
+type failingLocalLibrarian struct {
+	ports.LibrarianService
+	err error
+}
+
+func (l *failingLocalLibrarian) SaveLocalManifest(ctx context.Context, manifest *domain.Manifest) error {
+	return l.err
+}
+
+func TestNewLockBreaker(t *testing.T) {
+	librarian := &mocks.MockLibrarianService{}
+	storage := mocks.NewMockStorageRepository()
+
+	_, err := services.NewLockBreaker(nil, storage, nil)
+	assert.ErrorIs(t, err, services.ErrLockBreakerLibrarianNil)
+
+	_, err = services.NewLockBreaker(librarian, nil, nil)
+	assert.ErrorIs(t, err, services.ErrLockBreakerStorageNil)
+
+	breaker, err := services.NewLockBreaker(librarian, storage, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, breaker)
+
+	var nilBreaker *services.LockBreaker
+	_, err = nilBreaker.Break(context.Background())
+	assert.ErrorIs(t, err, services.ErrLockBreakerNil)
+}
+
+func TestLockBreaker_Break(t *testing.T) {
+	ctx := context.Background()
+	lockedAt := time.Now().Add(-3 * time.Hour)
+	lockID := fmt.Sprintf("deadhost%s%d", config.LockIDSeparator, lockedAt.UnixNano())
+
+	t.Run("not locked", func(t *testing.T) {
+		breaker, _, _ := setupLockBreaker(t, "", "", "y")
+
+		_, err := breaker.Break(ctx)
+		assert.ErrorIs(t, err, services.ErrManifestNotLocked)
+	})
+
+	t.Run("declined keeps lock", func(t *testing.T) {
+		breaker, _, remoteStorage := setupLockBreaker(t, lockID, "", "n")
+
+		_, err := breaker.Break(ctx)
+		assert.ErrorIs(t, err, services.ErrLockBreakDeclined)
+		assert.Equal(t, lockID, readManifestLock(t, remoteStorage))
+
+		_, err = remoteStorage.Get(ctx, config.LockAuditKey)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+
+	t.Run("foreign lock clears remote only", func(t *testing.T) {
+		breaker, localStorage, remoteStorage := setupLockBreaker(t, lockID, "", "yes")
+
+		record, err := breaker.Break(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "deadhost", record.Holder)
+		assert.True(t, lockedAt.Equal(record.LockedAt))
+		assert.False(t, record.LocalCleared)
+
+		assert.Empty(t, readManifestLock(t, remoteStorage))
+		assert.Empty(t, readManifestLock(t, localStorage))
+
+		records := readAuditRecords(t, remoteStorage)
+		require.Len(t, records, 1)
+		assert.Equal(t, lockID, records[0].LockID)
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+		assert.Equal(t, hostname, records[0].BrokenBy)
+		assert.False(t, records[0].BrokenAt.IsZero())
+	})
+
+	t.Run("owner machine clears local lock and lease", func(t *testing.T) {
+		breaker, localStorage, remoteStorage := setupLockBreaker(t, lockID, lockID, "y")
+
+		lease, err := domain.NewLease("deadhost", lockID, time.Minute)
+		require.NoError(t, err)
+		leaseData, err := json.Marshal(lease)
+		require.NoError(t, err)
+		require.NoError(t, remoteStorage.Put(ctx, config.LockFilename, leaseData))
+
+		record, err := breaker.Break(ctx)
+		require.NoError(t, err)
+		assert.True(t, record.LocalCleared)
+		assert.Empty(t, readManifestLock(t, localStorage))
+		assert.Empty(t, readManifestLock(t, remoteStorage))
+
+		_, err = remoteStorage.Get(ctx, config.LockFilename)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+
+	t.Run("audit log is appended", func(t *testing.T) {
+		breaker, _, remoteStorage := setupLockBreaker(t, lockID, "", "y")
+		require.NoError(t, remoteStorage.Put(ctx, config.LockAuditKey, []byte(`{"lock_id":"older::1"}`)))
+
+		_, err := breaker.Break(ctx)
+		require.NoError(t, err)
+
+		records := readAuditRecords(t, remoteStorage)
+		require.Len(t, records, 2)
+		assert.Equal(t, "older::1", records[0].LockID)
+		assert.Equal(t, lockID, records[1].LockID)
+	})
+
+	t.Run("failed local unlock is still audited", func(t *testing.T) {
+		localErr := errors.New("disk full")
+		breaker, localStorage, remoteStorage := setupLockBreakerWithLibrarian(t, lockID, lockID, "y", func(librarian ports.LibrarianService) ports.LibrarianService {
+			return &failingLocalLibrarian{LibrarianService: librarian, err: localErr}
+		})
+
+		record, err := breaker.Break(ctx)
+		assert.ErrorIs(t, err, localErr)
+		require.NotNil(t, record)
+		assert.False(t, record.LocalCleared)
+		assert.Empty(t, readManifestLock(t, remoteStorage))
+		assert.Equal(t, lockID, readManifestLock(t, localStorage))
+
+		records := readAuditRecords(t, remoteStorage)
+		require.Len(t, records, 1)
+		assert.Equal(t, lockID, records[0].LockID)
+		assert.False(t, records[0].LocalCleared)
+	})
+
+	t.Run("unparseable lock ID can still be broken", func(t *testing.T) {
+		breaker, _, remoteStorage := setupLockBreaker(t, "legacy-lock", "", "y")
+
+		record, err := breaker.Break(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "unknown", record.Holder)
+		assert.True(t, record.LockedAt.IsZero())
+		assert.Empty(t, readManifestLock(t, remoteStorage))
+	})
+}
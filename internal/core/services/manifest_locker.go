@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"time"
+)
+
+// ManifestLocker error constants
+var (
+	ErrManifestLockerNil          = errors.New("manifest locker cannot be nil")
+	ErrManifestLockerLibrarianNil = errors.New("librarian service cannot be nil")
+	ErrManifestLockNotHeld        = errors.New("manifest lock is not held")
+)
+
+// ManifestLocker takes and releases the manifest lock together with its remote lease
+// The lease is renewed by a heartbeat for as long as the lock is held
+// Molfar holds one for the server session; maintenance commands use Acquire and Release
+type ManifestLocker struct {
+	librarian ports.LibrarianService
+	events    chan<- ports.Event
+	operation string // Event operation name
+	lockID    string // Lock ID while held, empty otherwise
+
+	lease             *domain.Lease // Remote lease while the lock is held
+	heartbeatInterval time.Duration // Interval between lease renewals while the lock is held
+	stopHeartbeat     func()        // Stops the running lease heartbeat (nil when none runs)
+}
+
+// NewManifestLocker creates a new manifest locker reporting under operation
+func NewManifestLocker(librarian ports.LibrarianService, operation string, events chan<- ports.Event) (*ManifestLocker, error) {
+	if librarian == nil {
+		return nil, ErrManifestLockerLibrarianNil
+	}
+
+	return &ManifestLocker{
+		librarian:         librarian,
+		events:            events,
+		operation:         operation,
+		heartbeatInterval: time.Duration(config.LeaseHeartbeatSec) * time.Second,
+	}, nil
+}
+
+// SetHeartbeatIntervalForTesting sets the lease renewal interval (for testing only)
+func (l *ManifestLocker) SetHeartbeatIntervalForTesting(interval time.Duration) {
+	l.heartbeatInterval = interval
+}
+
+// send safely sends an event to the channel
+func (l *ManifestLocker) send(evt ports.Event) {
+	ports.SendEvent(l.events, evt)
+}
+
+// LockID returns the ID of the held lock, or an empty string
+func (l *ManifestLocker) LockID() string {
+	if l == nil {
+		return ""
+	}
+	return l.lockID
+}
+
+// Acquire writes the lease, locks the local and remote manifests and starts the lease heartbeat
+// Returns the locked remote manifest; a missing local manifest is not locked
+func (l *ManifestLocker) Acquire(ctx context.Context) (*domain.Manifest, error) {
+	if l == nil {
+		return nil, ErrManifestLockerNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	remoteManifest, err := l.librarian.GetRemoteManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote manifest: %w", err)
+	}
+	if remoteManifest == nil {
+		return nil, errors.New("remote manifest cannot be nil")
+	}
+
+	localManifest, err := l.librarian.GetLocalManifest(ctx)
+	if err != nil {
+		localManifest = nil
+	}
+
+	if err := l.lock(ctx, localManifest, remoteManifest); err != nil {
+		return nil, err
+	}
+
+	// Release runs with a context that survives cancellation, so the lease must stay alive until then
+	l.startHeartbeat(context.WithoutCancel(ctx))
+	return remoteManifest, nil
+}
+
+// Release saves remoteManifest unlocked, unlocks the local manifest and removes the lease
+// remoteManifest must be the manifest returned by Acquire, optionally modified
+func (l *ManifestLocker) Release(ctx context.Context, remoteManifest *domain.Manifest) error {
+	if l == nil {
+		return ErrManifestLockerNil
+	}
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	if remoteManifest == nil {
+		return errors.New("remote manifest cannot be nil")
+	}
+	if l.lockID == "" || remoteManifest.LockedBy != l.lockID {
+		return ErrManifestLockNotHeld
+	}
+
+	remoteManifest.Unlock()
+	if err := l.librarian.SaveRemoteManifest(ctx, remoteManifest); err != nil {
+		remoteManifest.Lock(l.lockID)
+		return fmt.Errorf("failed to unlock remote manifest: %w", err)
+	}
+
+	localManifest, err := l.librarian.GetLocalManifest(ctx)
+	if err == nil && localManifest != nil && localManifest.LockedBy == l.lockID {
+		localManifest.Unlock()
+		if err := l.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+			return fmt.Errorf("remote manifest unlocked but failed to unlock local manifest: %w", err)
+		}
+	}
+
+	lockID := l.lockID
+	l.release(ctx, l.operation)
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Released manifest lock", Data: map[string]any{"lock_id": lockID}})
+	return nil
+}
+
+// lock generates a lock ID, writes the lease and locks localManifest, when given, then remoteManifest
+// The lease is written first so a second host fails before touching the manifests;
+// a failure rolls back the steps already taken
+func (l *ManifestLocker) lock(ctx context.Context, localManifest, remoteManifest *domain.Manifest) error {
+	if l.lockID != "" {
+		return errors.New("manifest lock already held")
+	}
+	if remoteManifest.IsLocked() {
+		return fmt.Errorf("%w by %s", ErrManifestLocked, remoteManifest.LockedBy)
+	}
+	if localManifest != nil && localManifest.IsLocked() {
+		return fmt.Errorf("local manifest already locked by %s", localManifest.LockedBy)
+	}
+
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Generating unique lock identifier"})
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	lockID := fmt.Sprintf("%s"+config.LockIDSeparator+"%d", hostname, time.Now().UnixNano())
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Generated lock ID", Data: map[string]any{"lock_id": lockID}})
+
+	lease, err := l.acquireLease(ctx, hostname, lockID)
+	if err != nil {
+		return err
+	}
+	l.lease = lease
+
+	if localManifest != nil {
+		l.send(ports.UpdateEvent{Operation: l.operation, Message: "Acquiring local manifest lock"})
+		localManifest.Lock(lockID)
+		if err := l.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+			localManifest.Unlock()
+			l.release(ctx, l.operation)
+			return fmt.Errorf("failed to lock local manifest: %w", err)
+		}
+		l.send(ports.UpdateEvent{Operation: l.operation, Message: "Successfully locked local manifest"})
+	}
+
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Acquiring remote manifest lock"})
+	remoteManifest.Lock(lockID)
+	if err := l.librarian.SaveRemoteManifest(ctx, remoteManifest); err != nil {
+		remoteManifest.Unlock()
+		err = fmt.Errorf("failed to lock remote manifest: %w", err)
+
+		// Rollback: unlock local manifest and drop the lease to prevent orphaned locks
+		l.release(ctx, l.operation)
+		if localManifest != nil {
+			localManifest.Unlock()
+			if rollbackErr := l.librarian.SaveLocalManifest(ctx, localManifest); rollbackErr != nil {
+				return fmt.Errorf("%w, rollback failed: %w", err, rollbackErr)
+			}
+			l.send(ports.UpdateEvent{Operation: l.operation, Message: "Successfully rolled back local manifest lock"})
+		}
+		return err
+	}
+
+	l.lockID = lockID
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Acquired manifest lock", Data: map[string]any{"lock_id": lockID}})
+	return nil
+}
+
+// acquireLease writes a new remote lease for lockID
+// A leftover lease may only be replaced once it has expired
+func (l *ManifestLocker) acquireLease(ctx context.Context, hostname, lockID string) (*domain.Lease, error) {
+	existing, err := l.librarian.GetRemoteLease(ctx)
+	if err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return nil, fmt.Errorf("failed to read remote lease: %w", err)
+	}
+	if err == nil && existing != nil && !existing.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("%w: %s until %s", ErrLeaseHeld, existing.Owner, existing.ExpiresAt.Format(time.RFC3339))
+	}
+
+	lease, err := domain.NewLease(hostname, lockID, time.Duration(config.LeaseDurationSec)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Acquiring remote lease", Data: map[string]any{"expires_at": lease.ExpiresAt}})
+	if err := l.librarian.SaveRemoteLease(ctx, lease); err != nil {
+		return nil, fmt.Errorf("failed to acquire remote lease: %w", err)
+	}
+	l.send(ports.UpdateEvent{Operation: l.operation, Message: "Successfully acquired remote lease"})
+
+	return lease, nil
+}
+
+// release stops the heartbeat, deletes the remote lease if it still belongs to this lock and forgets the lock
+// The manifests are unlocked by the caller; failures are reported under operation but not returned,
+// as an orphaned lease expires on its own
+func (l *ManifestLocker) release(ctx context.Context, operation string) {
+	l.stopLeaseHeartbeat()
+	lease := l.lease
+	l.lease = nil
+	l.lockID = ""
+	if lease == nil {
+		return
+	}
+
+	current, err := l.librarian.GetRemoteLease(ctx)
+	if errors.Is(err, ports.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		l.send(ports.ErrorEvent{Operation: operation, Err: fmt.Errorf("failed to read remote lease: %w", err)})
+		return
+	}
+	if current == nil || current.SessionID != lease.SessionID {
+		l.send(ports.UpdateEvent{Operation: operation, Message: "Remote lease belongs to another session, leaving it"})
+		return
+	}
+
+	if err := l.librarian.DeleteRemoteLease(ctx); err != nil {
+		l.send(ports.ErrorEvent{Operation: operation, Err: fmt.Errorf("failed to release remote lease: %w", err)})
+		return
+	}
+	l.send(ports.UpdateEvent{Operation: operation, Message: "Released remote lease"})
+}
+
+// startHeartbeat renews the held lease in the background until stopLeaseHeartbeat is called
+func (l *ManifestLocker) startHeartbeat(ctx context.Context) {
+	if l.lease == nil || l.heartbeatInterval <= 0 || l.stopHeartbeat != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.heartbeat(ctx, l.lease, stop)
+	}()
+
+	l.stopHeartbeat = func() {
+		close(stop)
+		<-done
+	}
+}
+
+// stopLeaseHeartbeat stops the lease heartbeat and waits for it to finish
+func (l *ManifestLocker) stopLeaseHeartbeat() {
+	if l.stopHeartbeat == nil {
+		return
+	}
+	l.stopHeartbeat()
+	l.stopHeartbeat = nil
+}
+
+// heartbeat renews the lease every interval until stop is closed
+// A failed renewal is reported and retried at the next tick, so a transient storage error
+// does not let the lease expire; renewal only stops once another session owns the lease
+func (l *ManifestLocker) heartbeat(ctx context.Context, lease *domain.Lease, stop <-chan struct{}) {
+	ticker := time.NewTicker(l.heartbeatInterval)
+	defer ticker.Stop()
+
+	confirmedUntil := lease.ExpiresAt // Expiry other hosts currently see
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			lease.Renew(now, time.Duration(config.LeaseDurationSec)*time.Second)
+			err := l.librarian.SaveRemoteLease(ctx, lease)
+			if err == nil {
+				confirmedUntil = lease.ExpiresAt
+				l.send(ports.UpdateEvent{Operation: "lease", Message: "Lease renewed", Data: map[string]any{"expires_at": lease.ExpiresAt}})
+				continue
+			}
+			if lostErr := l.checkLeaseLost(ctx, lease, err); lostErr != nil {
+				l.send(ports.ErrorEvent{Operation: "lease", Err: lostErr})
+				return
+			}
+			l.send(ports.ErrorEvent{Operation: "lease", Err: fmt.Errorf("failed to renew lease, retrying; other hosts may break the lock after %s: %w", confirmedUntil.Format(time.RFC3339), err)})
+		}
+	}
+}
+
+// checkLeaseLost returns an error wrapping ErrLeaseLost if saveErr was a conflict with another session's lease
+// Returns nil when the renewal can be retried, including a conflict with a write of this session
+// whose response was lost; reading the lease records its current version for the retry
+func (l *ManifestLocker) checkLeaseLost(ctx context.Context, lease *domain.Lease, saveErr error) error {
+	if !errors.Is(saveErr, ErrLeaseConflict) {
+		return nil
+	}
+
+	current, err := l.librarian.GetRemoteLease(ctx)
+	if errors.Is(err, ports.ErrNotFound) {
+		return fmt.Errorf("%w: the remote lease was removed: %w", ErrLeaseLost, saveErr)
+	}
+	if err != nil || current == nil || current.SessionID == lease.SessionID {
+		return nil
+	}
+	return fmt.Errorf("%w: now held by %s (%s): %w", ErrLeaseLost, current.Owner, current.SessionID, saveErr)
+}
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupManifestLocker creates a locker over FS storages holding unlocked manifests
+func setupManifestLocker(t *testing.T) (*services.ManifestLocker, *adapters.FSRepository, *adapters.FSRepository) {
+	t.Helper()
+	localRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	localStorage, err := adapters.NewFSRepository(localRoot)
+	require.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		localStorage.Close()
+		remoteStorage.Close()
+	})
+
+	data := mustManifestJSON(t, []domain.World{})
+	require.NoError(t, localStorage.Put(context.Background(), config.ManifestFilename, data))
+	require.NoError(t, remoteStorage.Put(context.Background(), config.ManifestFilename, data))
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	locker, err := services.NewManifestLocker(librarian, "test", nil)
+	require.NoError(t, err)
+
+	return locker, localStorage, remoteStorage
+}
+
+func TestNewManifestLocker(t *testing.T) {
+	_, err := services.NewManifestLocker(nil, "test", nil)
+	assert.ErrorIs(t, err, services.ErrManifestLockerLibrarianNil)
+
+	var nilLocker *services.ManifestLocker
+	_, err = nilLocker.Acquire(context.Background())
+	assert.ErrorIs(t, err, services.ErrManifestLockerNil)
+	assert.Empty(t, nilLocker.LockID())
+}
+
+func TestManifestLocker_AcquireRelease(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("locks both manifests and writes lease", func(t *testing.T) {
+		locker, localStorage, remoteStorage := setupManifestLocker(t)
+
+		remote, err := locker.Acquire(ctx)
+		require.NoError(t, err)
+		lockID := locker.LockID()
+		require.NotEmpty(t, lockID)
+		assert.Equal(t, lockID, remote.LockedBy)
+		assert.Equal(t, lockID, readTestManifest(t, remoteStorage).LockedBy)
+		assert.Equal(t, lockID, readTestManifest(t, localStorage).LockedBy)
+
+		lease, err := readRemoteLease(t, remoteStorage)
+		require.NoError(t, err)
+		assert.Equal(t, lockID, lease.SessionID)
+
+		remote.InstanceVersion = "2.0.0"
+		require.NoError(t, locker.Release(ctx, remote))
+		assert.Empty(t, locker.LockID())
+
+		released := readTestManifest(t, remoteStorage)
+		assert.False(t, released.IsLocked())
+		assert.Equal(t, "2.0.0", released.InstanceVersion)
+		assert.False(t, readTestManifest(t, localStorage).IsLocked())
+
+		_, err = readRemoteLease(t, remoteStorage)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+
+	t.Run("lease is renewed while the lock is held", func(t *testing.T) {
+		locker, _, remoteStorage := setupManifestLocker(t)
+		locker.SetHeartbeatIntervalForTesting(10 * time.Millisecond)
+
+		remote, err := locker.Acquire(ctx)
+		require.NoError(t, err)
+		acquired, err := readRemoteLease(t, remoteStorage)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			lease, err := readRemoteLease(t, remoteStorage)
+			return err == nil && lease.ExpiresAt.After(acquired.ExpiresAt)
+		}, time.Second, 5*time.Millisecond, "heartbeat renews the lease")
+
+		require.NoError(t, locker.Release(ctx, remote))
+		_, err = readRemoteLease(t, remoteStorage)
+		assert.ErrorIs(t, err, ports.ErrNotFound, "no renewal recreates the lease after release")
+	})
+
+	t.Run("second locker is rejected", func(t *testing.T) {
+		locker, localStorage, remoteStorage := setupManifestLocker(t)
+		_, err := locker.Acquire(ctx)
+		require.NoError(t, err)
+
+		librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+		require.NoError(t, err)
+		other, err := services.NewManifestLocker(librarian, "test", nil)
+		require.NoError(t, err)
+
+		_, err = other.Acquire(ctx)
+		assert.ErrorIs(t, err, services.ErrManifestLocked)
+	})
+
+	t.Run("live lease blocks acquire", func(t *testing.T) {
+		locker, _, remoteStorage := setupManifestLocker(t)
+		lease, err := domain.NewLease("otherhost", "otherhost::1", time.Minute)
+		require.NoError(t, err)
+		data, err := json.Marshal(lease)
+		require.NoError(t, err)
+		require.NoError(t, remoteStorage.Put(ctx, config.LockFilename, data))
+
+		_, err = locker.Acquire(ctx)
+		assert.ErrorIs(t, err, services.ErrLeaseHeld)
+		assert.False(t, readTestManifest(t, remoteStorage).IsLocked())
+	})
+
+	t.Run("release without lock fails", func(t *testing.T) {
+		locker, _, _ := setupManifestLocker(t)
+		err := locker.Release(ctx, &domain.Manifest{})
+		assert.ErrorIs(t, err, services.ErrManifestLockNotHeld)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"ritual/internal/config"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,10 @@ var (
 	ErrServerRunnerNil            = errors.New("server runner cannot be nil")
 	ErrMolfarInitializationFailed = errors.New("molfar initialization failed")
 	ErrMolfarNil                  = errors.New("molfar service cannot be nil")
+	ErrMolfarContextNil           = errors.New("context cannot be nil")
+	ErrShutdownRequested          = errors.New("shutdown requested")
+	ErrLeaseHeld                  = errors.New("remote lease is held by another session")
+	ErrLeaseLost                  = errors.New("remote lease is no longer held by this session")
 )
 
 // MolfarService implements the main orchestration interface as a state machine
@@ -32,6 +37,14 @@ type MolfarService struct {
 	events        chan<- ports.Event
 	workRoot      *os.Root
 	currentLockID string // Tracks the current lock ID for ownership validation (internal use only)
+
+	locker *ManifestLocker // Takes the manifest lock and renews its lease while the lock is owned
+
+	sessionDuration   time.Duration            // How long the server ran, recorded with the backup
+	shutdownRequested bool                     // The server was stopped by a shutdown request; Exit then asks nothing
+	noteTimeout       time.Duration            // Time given to enter the backup note
+	checkpoints       ports.CheckpointService  // Optional: backs up the world while the server runs
+	healthProbe       ports.HealthProbeService // Optional: reports the server's status while it runs
 }
 
 // NewMolfarService creates a new Molfar orchestration service
@@ -88,6 +101,11 @@ func NewMolfarService(
 		return nil, errors.New("workRoot cannot be nil")
 	}
 
+	locker, err := NewManifestLocker(librarian, "lock", events)
+	if err != nil {
+		return nil, err
+	}
+
 	molfar := &MolfarService{
 		conditions:   conditions,
 		updaters:     updaters,
@@ -97,11 +115,25 @@ func NewMolfarService(
 		librarian:    librarian,
 		events:       events,
 		workRoot:     workRoot,
+		locker:       locker,
+
+		noteTimeout: time.Duration(config.BackupNoteTimeoutSec) * time.Second,
 	}
 
 	return molfar, nil
 }
 
+// EnableCheckpoints backs up the world with checkpoints while the server runs
+// Checkpoints only run while the session holds the manifest lock
+func (m *MolfarService) EnableCheckpoints(checkpoints ports.CheckpointService) {
+	m.checkpoints = checkpoints
+}
+
+// EnableHealthProbe polls the server's status while it runs
+func (m *MolfarService) EnableHealthProbe(probe ports.HealthProbeService) {
+	m.healthProbe = probe
+}
+
 // send safely sends an event to the channel
 func (m *MolfarService) send(evt ports.Event) {
 	ports.SendEvent(m.events, evt)
@@ -109,17 +141,23 @@ func (m *MolfarService) send(evt ports.Event) {
 
 // Prepare initializes the environment and validates prerequisites
 // Runs all conditions first, then all updaters in sequence
-func (m *MolfarService) Prepare() error {
+// Cancelling ctx aborts in-flight downloads and skips remaining steps
+func (m *MolfarService) Prepare(ctx context.Context) error {
 	if m == nil {
 		return ErrMolfarNil
 	}
+	if ctx == nil {
+		return ErrMolfarContextNil
+	}
 
 	m.send(ports.StartEvent{Operation: "prepare"})
 	m.send(ports.UpdateEvent{Operation: "prepare", Message: "Starting preparation phase", Data: map[string]any{"workRoot": m.workRoot.Name()}})
-	ctx := context.Background()
 
 	// Run all conditions first (includes manifest lock check)
 	for i, condition := range m.conditions {
+		if err := m.checkShutdown(ctx, "prepare"); err != nil {
+			return err
+		}
 		m.send(ports.StartEvent{Operation: "condition"})
 		m.send(ports.UpdateEvent{Operation: "condition", Message: "Checking condition", Data: map[string]any{"index": i}})
 		if err := condition.Check(ctx); err != nil {
@@ -131,6 +169,9 @@ func (m *MolfarService) Prepare() error {
 
 	// Run all updaters
 	for i, updater := range m.updaters {
+		if err := m.checkShutdown(ctx, "prepare"); err != nil {
+			return err
+		}
 		m.send(ports.StartEvent{Operation: "updater"})
 		m.send(ports.UpdateEvent{Operation: "updater", Message: "Running updater", Data: map[string]any{"index": i}})
 		if err := updater.Run(ctx); err != nil {
@@ -147,10 +188,14 @@ func (m *MolfarService) Prepare() error {
 
 // Run executes the main server orchestration process
 // Already in Running state, coordinates server execution
-func (m *MolfarService) Run(server *domain.Server) error {
+// Cancelling ctx stops the server gracefully; Exit still runs backup and unlock
+func (m *MolfarService) Run(ctx context.Context, server *domain.Server) error {
 	if m == nil {
 		return ErrMolfarNil
 	}
+	if ctx == nil {
+		return ErrMolfarContextNil
+	}
 	if server == nil {
 		return errors.New("server cannot be nil")
 	}
@@ -168,7 +213,6 @@ func (m *MolfarService) Run(server *domain.Server) error {
 		"server_ip":      server.IP,
 		"server_port":    server.Port,
 	}})
-	ctx := context.Background()
 
 	// Fetch remote manifest before run
 	remoteManifest, err := m.getRemoteManifest(ctx)
@@ -183,11 +227,19 @@ func (m *MolfarService) Run(server *domain.Server) error {
 		return err
 	}
 
+	// Do not take the lock once shutdown was requested
+	if err := m.checkShutdown(ctx, "run"); err != nil {
+		return err
+	}
+
 	if err := m.acquireManifestLocks(ctx, localManifest, remoteManifest); err != nil {
 		m.send(ports.ErrorEvent{Operation: "run", Err: err})
 		return err
 	}
 
+	// Keep the lease alive until Exit releases the lock, including the backup after a shutdown
+	m.locker.startHeartbeat(context.WithoutCancel(ctx))
+
 	if err := m.executeServer(ctx, server); err != nil {
 		m.send(ports.ErrorEvent{Operation: "run", Err: err})
 		return err
@@ -257,45 +309,13 @@ func (m *MolfarService) acquireManifestLocks(ctx context.Context, localManifest,
 		return err
 	}
 
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Generating unique lock identifier"})
-	hostname, err := os.Hostname()
-	if err != nil {
-		m.send(ports.ErrorEvent{Operation: "lock", Err: err})
-		return err
-	}
-
-	lockID := fmt.Sprintf("%s"+config.LockIDSeparator+"%d", hostname, time.Now().UnixNano())
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Generated lock ID", Data: map[string]any{"lock_id": lockID}})
-	localManifest.LockedBy = lockID
-	remoteManifest.LockedBy = lockID
-
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Acquiring local manifest lock"})
-	err = m.librarian.SaveLocalManifest(ctx, localManifest)
-	if err != nil {
+	if err := m.locker.lock(ctx, localManifest, remoteManifest); err != nil {
 		m.send(ports.ErrorEvent{Operation: "lock", Err: err})
 		return err
 	}
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Successfully locked local manifest"})
-
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Acquiring remote manifest lock"})
-	err = m.librarian.SaveRemoteManifest(ctx, remoteManifest)
-	if err != nil {
-		m.send(ports.ErrorEvent{Operation: "lock", Err: fmt.Errorf("failed to lock remote manifest: %w", err)})
-
-		// Rollback: unlock local manifest to prevent orphaned lock
-		localManifest.Unlock()
-		if rollbackErr := m.librarian.SaveLocalManifest(ctx, localManifest); rollbackErr != nil {
-			m.send(ports.ErrorEvent{Operation: "lock", Err: fmt.Errorf("rollback failed: %w", rollbackErr)})
-			return fmt.Errorf("failed to lock remote manifest: %w, rollback failed: %w", err, rollbackErr)
-		}
-		m.send(ports.UpdateEvent{Operation: "lock", Message: "Successfully rolled back local manifest lock"})
-
-		return fmt.Errorf("failed to lock remote manifest: %w", err)
-	}
-	m.send(ports.UpdateEvent{Operation: "lock", Message: "Successfully locked remote storage", Data: map[string]any{"lock_id": lockID}})
 
 	// Store lock ID for ownership validation
-	m.currentLockID = lockID
+	m.currentLockID = m.locker.LockID()
 
 	m.send(ports.FinishEvent{Operation: "lock"})
 	return nil
@@ -307,6 +327,16 @@ func (m *MolfarService) SetLockIDForTesting(lockID string) {
 	m.currentLockID = lockID
 }
 
+// SetNoteTimeoutForTesting sets the time given to enter the backup note (for testing only)
+func (m *MolfarService) SetNoteTimeoutForTesting(timeout time.Duration) {
+	m.noteTimeout = timeout
+}
+
+// SetHeartbeatIntervalForTesting sets the lease renewal interval (for testing only)
+func (m *MolfarService) SetHeartbeatIntervalForTesting(interval time.Duration) {
+	m.locker.SetHeartbeatIntervalForTesting(interval)
+}
+
 // executeServer runs the server using the server runner
 func (m *MolfarService) executeServer(ctx context.Context, server *domain.Server) error {
 	if ctx == nil {
@@ -321,7 +351,31 @@ func (m *MolfarService) executeServer(ctx context.Context, server *domain.Server
 
 	m.send(ports.StartEvent{Operation: "server"})
 	m.send(ports.UpdateEvent{Operation: "server", Message: "Starting server execution", Data: map[string]any{"server_address": server.Address}})
-	err := m.serverRunner.Run(server)
+
+	runErr := make(chan error, 1)
+	started := time.Now()
+	go func() {
+		runErr <- m.serverRunner.Run(server)
+	}()
+	stopCheckpoints := m.startCheckpoints(ctx)
+	stopHealthProbe := m.startHealthProbe(ctx, server)
+
+	var err error
+	select {
+	case err = <-runErr:
+	case <-ctx.Done():
+		m.stopServer()
+		err = <-runErr
+		if err == nil {
+			err = fmt.Errorf("%w: %w", ErrShutdownRequested, ctx.Err())
+		} else {
+			err = fmt.Errorf("%w: %w", ErrShutdownRequested, err)
+		}
+	}
+	m.sessionDuration = time.Since(started)
+	m.shutdownRequested = ctx.Err() != nil
+	stopHealthProbe()
+	stopCheckpoints()
 	if err != nil {
 		m.send(ports.ErrorEvent{Operation: "server", Err: err})
 		return err
@@ -332,19 +386,88 @@ func (m *MolfarService) executeServer(ctx context.Context, server *domain.Server
 	return nil
 }
 
+// startCheckpoints runs the checkpoint service in the background while the lock is held
+// The returned function stops it and waits for an in-flight checkpoint to finish
+func (m *MolfarService) startCheckpoints(ctx context.Context) func() {
+	if m.checkpoints == nil || m.currentLockID == "" {
+		return func() {}
+	}
+
+	lockID := m.currentLockID
+	return runInBackground(ctx, func(ctx context.Context) {
+		m.checkpoints.Run(ctx, lockID)
+	})
+}
+
+// startHealthProbe polls the server's status in the background
+// The returned function stops the probe and waits for it to return
+func (m *MolfarService) startHealthProbe(ctx context.Context, server *domain.Server) func() {
+	if m.healthProbe == nil {
+		return func() {}
+	}
+
+	return runInBackground(ctx, func(ctx context.Context) {
+		m.healthProbe.Run(ctx, server)
+	})
+}
+
+// runInBackground calls run in a goroutine with a context derived from ctx
+// The returned function cancels that context and waits for run to return
+func runInBackground(ctx context.Context, run func(ctx context.Context)) func() {
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(runCtx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// stopServer asks the server runner to stop the server gracefully
+// Runners without a console are left to exit on their own
+func (m *MolfarService) stopServer() {
+	console, ok := m.serverRunner.(ports.ServerConsole)
+	if !ok {
+		m.send(ports.UpdateEvent{Operation: "server", Message: "Shutdown requested, waiting for server to exit"})
+		return
+	}
+
+	m.send(ports.UpdateEvent{Operation: "server", Message: "Shutdown requested, stopping server", Data: map[string]any{"timeout_sec": config.ServerStopTimeoutSec}})
+	if err := console.Stop(time.Duration(config.ServerStopTimeoutSec) * time.Second); err != nil {
+		m.send(ports.ErrorEvent{Operation: "server", Err: fmt.Errorf("graceful stop failed: %w", err)})
+	}
+}
+
+// checkShutdown returns ErrShutdownRequested once ctx is cancelled
+func (m *MolfarService) checkShutdown(ctx context.Context, operation string) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	err := fmt.Errorf("%w: %w", ErrShutdownRequested, ctx.Err())
+	m.send(ports.ErrorEvent{Operation: operation, Err: err})
+	return err
+}
+
 // Exit gracefully shuts down the server and cleans up resources
 // Runs all backuppers in sequence only if we own the lock
-func (m *MolfarService) Exit() error {
+// ctx must outlive the Run context so backup and unlock complete after a shutdown request
+func (m *MolfarService) Exit(ctx context.Context) error {
 	if m == nil {
 		return ErrMolfarNil
 	}
+	if ctx == nil {
+		return ErrMolfarContextNil
+	}
 	if m.librarian == nil {
 		return ErrLibrarianNil
 	}
 
 	m.send(ports.StartEvent{Operation: "exit"})
 	m.send(ports.UpdateEvent{Operation: "exit", Message: "Starting exit phase"})
-	ctx := context.Background()
 
 	// Skip backup and unlock if we don't own the lock
 	if m.currentLockID == "" {
@@ -353,25 +476,35 @@ func (m *MolfarService) Exit() error {
 		return nil
 	}
 
-	// Run all backuppers and collect archive names
-	var lastArchiveName string
+	// A lock left behind by a failed exit must be able to expire
+	defer m.locker.stopLeaseHeartbeat()
+
+	// Run all backuppers and collect the world entries they produced
+	var lastWorld *domain.World
 	for i, backupper := range m.backuppers {
 		m.send(ports.StartEvent{Operation: "backup"})
 		m.send(ports.UpdateEvent{Operation: "backup", Message: "Running backupper", Data: map[string]any{"index": i}})
-		archiveName, err := backupper.Run(ctx)
+		world, err := backupper.Run(ctx)
 		if err != nil {
 			m.send(ports.ErrorEvent{Operation: "backup", Err: err})
 			return fmt.Errorf("backupper %d failed: %w", i, err)
 		}
-		m.send(ports.UpdateEvent{Operation: "backup", Message: "Backupper completed", Data: map[string]any{"index": i, "archive_name": archiveName}})
+		data := map[string]any{"index": i}
+		if world != nil {
+			data["archive_name"] = world.URI
+			data["size"] = world.Size
+			data["checksum"] = world.Checksum
+			lastWorld = world
+		}
+		m.send(ports.UpdateEvent{Operation: "backup", Message: "Backupper completed", Data: data})
 		m.send(ports.FinishEvent{Operation: "backup"})
-		lastArchiveName = archiveName
 	}
 
-	// Update manifests with last archive name (from any backupper)
+	// Update manifests with last world entry (from any backupper)
 	var updatedManifest *domain.Manifest
-	if lastArchiveName != "" {
-		manifest, err := m.updateManifestsWithArchive(ctx, lastArchiveName)
+	if lastWorld != nil {
+		lastWorld.Note = m.promptBackupNote(ctx)
+		manifest, err := m.updateManifestsWithArchive(ctx, lastWorld)
 		if err != nil {
 			m.send(ports.ErrorEvent{Operation: "exit", Err: err})
 			return err
@@ -413,15 +546,48 @@ func (m *MolfarService) Exit() error {
 	return nil
 }
 
-// updateManifestsWithArchive updates both local and remote manifests with the new archive name
+// promptBackupNote asks for an optional note describing the session's backup
+// Returns an empty note without asking when no one receives events or the server was stopped by a
+// shutdown request, and gives up after noteTimeout or once ctx is cancelled; the backup never waits on the operator
+func (m *MolfarService) promptBackupNote(ctx context.Context) string {
+	if m.events == nil || m.shutdownRequested {
+		return ""
+	}
+
+	responseChan := make(chan any, 1)
+	m.send(ports.PromptEvent{
+		ID:           "backup_note",
+		Prompt:       "Note for this backup (optional, e.g. \"before the dragon fight\")",
+		DefaultValue: "",
+		ResponseChan: responseChan,
+		Timeout:      m.noteTimeout,
+	})
+
+	timer := time.NewTimer(m.noteTimeout)
+	defer timer.Stop()
+
+	select {
+	case response := <-responseChan:
+		note, _ := response.(string)
+		return strings.TrimSpace(note)
+	case <-timer.C:
+		m.send(ports.UpdateEvent{Operation: "exit", Message: "No backup note entered, recording the backup without one"})
+		return ""
+	case <-ctx.Done():
+		return ""
+	}
+}
+
+// updateManifestsWithArchive updates both local and remote manifests with the new world entry
 // Returns the updated manifest for use in retention policies
-func (m *MolfarService) updateManifestsWithArchive(ctx context.Context, archiveName string) (*domain.Manifest, error) {
+func (m *MolfarService) updateManifestsWithArchive(ctx context.Context, archive *domain.World) (*domain.Manifest, error) {
 	if ctx == nil {
 		return nil, errors.New("context cannot be nil")
 	}
-	if archiveName == "" {
-		return nil, errors.New("archive name cannot be empty")
+	if archive == nil {
+		return nil, errors.New("archive cannot be nil")
 	}
+	archiveName := archive.URI
 	if m.librarian == nil {
 		return nil, ErrLibrarianNil
 	}
@@ -434,11 +600,22 @@ func (m *MolfarService) updateManifestsWithArchive(ctx context.Context, archiveN
 		return nil, err
 	}
 
-	// Create new world entry with archive name
+	// Create new world entry, keeping everything the backuppers and exit recorded
 	world, err := domain.NewWorld(archiveName)
 	if err != nil {
 		return nil, err
 	}
+	entry := *archive
+	entry.URI = world.URI
+	entry.CreatedAt = world.CreatedAt
+	entry.RitualVersion = config.AppVersion
+	entry.SessionSeconds = int64(m.sessionDuration.Round(time.Second) / time.Second)
+	world = &entry
+
+	// The final backup supersedes the session's checkpoints; retention deletes their archives
+	if removed := localManifest.RemoveIntermediateWorlds(); len(removed) > 0 {
+		m.send(ports.UpdateEvent{Operation: "exit", Message: "Final backup supersedes checkpoints", Data: map[string]any{"checkpoints": len(removed)}})
+	}
 
 	// Add world to manifest
 	localManifest.AddWorld(*world)
@@ -451,6 +628,14 @@ func (m *MolfarService) updateManifestsWithArchive(ctx context.Context, archiveN
 		return nil, err
 	}
 
+	// Confirm the remote lock is still ours before overwriting the remote manifest
+	// The conditional save then rejects any change made after this read
+	// An unreadable remote manifest is recreated, but never overwritten blindly
+	remoteManifest, err := m.librarian.GetRemoteManifest(ctx)
+	if err == nil && remoteManifest.LockedBy != m.currentLockID {
+		return nil, fmt.Errorf("lock ownership validation failed: remote manifest locked by %q", remoteManifest.LockedBy)
+	}
+
 	// Save updated remote manifest
 	if err := m.librarian.SaveRemoteManifest(ctx, localManifest); err != nil {
 		return nil, err
@@ -536,6 +721,8 @@ func (m *MolfarService) unlockManifests(ctx context.Context) error {
 		m.send(ports.UpdateEvent{Operation: "unlock", Message: "Successfully unlocked remote manifest"})
 	}
 
+	m.locker.release(ctx, "unlock")
+
 	// Clear stored lock ID
 	m.currentLockID = ""
 
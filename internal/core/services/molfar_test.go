@@ -18,10 +18,12 @@ import (
 	"ritual/internal/core/services"
 	"ritual/internal/testhelpers"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockTarGzDownloader implements streamer.S3StreamDownloader for testing
@@ -120,6 +122,7 @@ func setupMolfarServices(t *testing.T) (*services.MolfarService, *adapters.FSRep
 		mockDownloader,
 		"test-bucket",
 		tempRoot,
+		nil,
 	)
 	assert.NoError(t, err)
 
@@ -130,6 +133,7 @@ func setupMolfarServices(t *testing.T) (*services.MolfarService, *adapters.FSRep
 		"test-bucket",
 		tempRoot,
 		nil,
+		nil,
 	)
 	assert.NoError(t, err)
 
@@ -352,7 +356,7 @@ func TestMolfarService_Prepare(globT *testing.T) {
 		setupWorldTar(t, downloader, remoteTempDir, config.RemoteBackups+"/1234567890.tar")
 
 		// Execute Prepare
-		err := molfar.Prepare()
+		err := molfar.Prepare(context.Background())
 		if err != nil {
 			t.Fatalf("Prepare failed: %v", err)
 		}
@@ -458,7 +462,7 @@ func TestMolfarService_Prepare(globT *testing.T) {
 		assert.NoError(t, err)
 
 		// Execute Prepare
-		err = molfar.Prepare()
+		err = molfar.Prepare(context.Background())
 		if err != nil {
 			t.Fatalf("Prepare failed: %v", err)
 		}
@@ -522,7 +526,7 @@ func TestMolfarService_Prepare(globT *testing.T) {
 		assert.NoError(t, err)
 
 		// Execute Prepare
-		err = molfar.Prepare()
+		err = molfar.Prepare(context.Background())
 		if err != nil {
 			t.Fatalf("Prepare failed: %v", err)
 		}
@@ -589,7 +593,7 @@ func TestMolfarService_Prepare(globT *testing.T) {
 		setupInstanceTarGz(t, downloader, remoteTempDir)
 
 		// Execute Prepare - should succeed even without remote worlds
-		err = molfar.Prepare()
+		err = molfar.Prepare(context.Background())
 		assert.NoError(t, err, "Prepare should succeed without remote worlds")
 
 		// Verify local manifest was created
@@ -655,7 +659,7 @@ func TestMolfarService_Prepare(globT *testing.T) {
 		)
 		assert.NoError(t, err)
 
-		err = molfar.Prepare()
+		err = molfar.Prepare(context.Background())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "updater 0 failed")
 	})
@@ -706,7 +710,7 @@ func TestMolfarService_Run(t *testing.T) {
 					}
 
 		// Execute Run
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err)
 
 		// Verify manifests are locked after Run execution
@@ -775,7 +779,7 @@ func TestMolfarService_Run(t *testing.T) {
 					}
 
 		// Execute Run - should succeed and lock manifests (Run doesn't update versions)
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err)
 
 		// Verify manifests are locked after Run execution (versions remain unchanged)
@@ -846,7 +850,7 @@ func TestMolfarService_Run(t *testing.T) {
 					}
 
 		// Execute Run
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err)
 
 		// Verify remote manifest was fetched and used for lock acquisition
@@ -872,7 +876,7 @@ func TestMolfarService_Run(t *testing.T) {
 		molfar, _, _, _, _, _, cleanup := setupMolfarServices(t)
 		defer cleanup()
 
-		err := molfar.Run(nil)
+		err := molfar.Run(context.Background(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "server cannot be nil")
 	})
@@ -881,7 +885,7 @@ func TestMolfarService_Run(t *testing.T) {
 		var molfar *services.MolfarService
 		server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048}
 
-		err := molfar.Run(server)
+		err := molfar.Run(context.Background(), server)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "molfar service cannot be nil")
 	})
@@ -915,11 +919,217 @@ func TestMolfarService_Run(t *testing.T) {
 					}
 
 		// Execute Run - should succeed with mock runner
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err)
 	})
 }
 
+// consoleServerRunner blocks in Run until Stop is called, like a real server process
+type consoleServerRunner struct {
+	started     chan struct{}
+	stopped     chan struct{}
+	stopTimeout time.Duration
+}
+
+func newConsoleServerRunner() *consoleServerRunner {
+	return &consoleServerRunner{started: make(chan struct{}), stopped: make(chan struct{})}
+}
+
+func (r *consoleServerRunner) Run(server *domain.Server) error {
+	close(r.started)
+	<-r.stopped
+	return nil
+}
+
+func (r *consoleServerRunner) SendCommand(command string) error {
+	return nil
+}
+
+func (r *consoleServerRunner) Stop(timeout time.Duration) error {
+	r.stopTimeout = timeout
+	close(r.stopped)
+	return nil
+}
+
+// setupShutdownMolfar creates a molfar with unlocked manifests and the given server runner
+func setupShutdownMolfar(t *testing.T, runner ports.ServerRunner, backupper ports.BackupperService) (*services.MolfarService, *adapters.FSRepository, *adapters.FSRepository) {
+	return setupShutdownMolfarWithLibrarian(t, runner, backupper, nil)
+}
+
+// setupShutdownMolfarWithLibrarian is setupShutdownMolfar with the librarian passed through wrap when it is non-nil
+func setupShutdownMolfarWithLibrarian(t *testing.T, runner ports.ServerRunner, backupper ports.BackupperService, wrap func(ports.LibrarianService) ports.LibrarianService) (*services.MolfarService, *adapters.FSRepository, *adapters.FSRepository) {
+	tempRoot, err := os.OpenRoot(t.TempDir())
+	assert.NoError(t, err)
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	assert.NoError(t, err)
+
+	localStorage, err := adapters.NewFSRepository(tempRoot)
+	assert.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		localStorage.Close()
+		remoteStorage.Close()
+	})
+
+	ctx := context.Background()
+	world := createTestWorld(config.RemoteBackups + "/1234567890.tar")
+	for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+		manifestData, err := json.Marshal(createTestManifest("1.0.0", "1.0.0", []domain.World{world}))
+		assert.NoError(t, err)
+		assert.NoError(t, storage.Put(ctx, "manifest.json", manifestData))
+	}
+
+	librarianService, err := services.NewLibrarianService(localStorage, remoteStorage)
+	assert.NoError(t, err)
+	var librarian ports.LibrarianService = librarianService
+	if wrap != nil {
+		librarian = wrap(librarianService)
+	}
+
+	molfar, err := services.NewMolfarService(
+		[]ports.ConditionService{},
+		[]ports.UpdaterService{},
+		[]ports.BackupperService{backupper},
+		[]ports.RetentionService{},
+		runner,
+		librarian,
+		nil,
+		tempRoot,
+	)
+	assert.NoError(t, err)
+
+	return molfar, localStorage, remoteStorage
+}
+
+// flakyLeaseLibrarian fails chosen SaveRemoteLease calls (1-based) and counts all of them
+type flakyLeaseLibrarian struct {
+	ports.LibrarianService
+	failOn map[int]bool
+
+	mu    sync.Mutex
+	saves int
+}
+
+func (l *flakyLeaseLibrarian) SaveRemoteLease(ctx context.Context, lease *domain.Lease) error {
+	l.mu.Lock()
+	l.saves++
+	fail := l.failOn[l.saves]
+	l.mu.Unlock()
+	if fail {
+		return errors.New("503 service unavailable")
+	}
+	return l.LibrarianService.SaveRemoteLease(ctx, lease)
+}
+
+func (l *flakyLeaseLibrarian) saveCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.saves
+}
+
+func TestMolfarService_Shutdown(t *testing.T) {
+	server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565}
+
+	t.Run("cancel stops server gracefully and exit still backs up and unlocks", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		backupper := &mocks.MockBackupperService{}
+		molfar, localStorage, remoteStorage := setupShutdownMolfar(t, runner, backupper)
+
+		runCtx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-runner.started
+			cancel()
+		}()
+
+		err := molfar.Run(runCtx, server)
+		assert.ErrorIs(t, err, services.ErrShutdownRequested)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, time.Duration(config.ServerStopTimeoutSec)*time.Second, runner.stopTimeout)
+
+		// Exit runs with its own context after the run context was cancelled
+		assert.NoError(t, molfar.Exit(context.Background()))
+
+		for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+			data, err := storage.Get(context.Background(), "manifest.json")
+			assert.NoError(t, err)
+			var manifest domain.Manifest
+			assert.NoError(t, json.Unmarshal(data, &manifest))
+			assert.False(t, manifest.IsLocked())
+			assert.Equal(t, "mock-archive.zip", manifest.GetLatestWorld().URI)
+		}
+	})
+
+	t.Run("cancelled before run does not lock", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, localStorage, _ := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := molfar.Run(ctx, server)
+		assert.ErrorIs(t, err, services.ErrShutdownRequested)
+
+		data, err := localStorage.Get(context.Background(), "manifest.json")
+		assert.NoError(t, err)
+		var manifest domain.Manifest
+		assert.NoError(t, json.Unmarshal(data, &manifest))
+		assert.False(t, manifest.IsLocked())
+
+		// Nothing to back up when the lock was never taken
+		backupCalled := false
+		backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+			backupCalled = true
+			return nil, nil
+		}}
+		molfar, _, _ = setupShutdownMolfar(t, runner, backupper)
+		assert.NoError(t, molfar.Exit(context.Background()))
+		assert.False(t, backupCalled)
+	})
+
+	t.Run("cancelled prepare skips remaining steps", func(t *testing.T) {
+		tempRoot, err := os.OpenRoot(t.TempDir())
+		assert.NoError(t, err)
+		defer tempRoot.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		firstCalled, secondCalled := false, false
+		first := &mocks.MockUpdaterService{RunFunc: func(ctx context.Context) error {
+			firstCalled = true
+			cancel()
+			return nil
+		}}
+		second := &mocks.MockUpdaterService{RunFunc: func(ctx context.Context) error {
+			secondCalled = true
+			return nil
+		}}
+
+		molfar, err := services.NewMolfarService(
+			[]ports.ConditionService{},
+			[]ports.UpdaterService{first, second},
+			[]ports.BackupperService{},
+			[]ports.RetentionService{},
+			&MockServerRunner{},
+			mocks.NewMockLibrarianService(),
+			nil,
+			tempRoot,
+		)
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, molfar.Prepare(ctx), services.ErrShutdownRequested)
+		assert.True(t, firstCalled)
+		assert.False(t, secondCalled)
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		molfar, _, _ := setupShutdownMolfar(t, &MockServerRunner{}, &mocks.MockBackupperService{})
+
+		assert.ErrorIs(t, molfar.Prepare(nil), services.ErrMolfarContextNil)
+		assert.ErrorIs(t, molfar.Run(nil, server), services.ErrMolfarContextNil)
+		assert.ErrorIs(t, molfar.Exit(nil), services.ErrMolfarContextNil)
+	})
+}
+
 func TestMolfarService_Exit(t *testing.T) {
 	t.Run("successful exit with real backupper", func(t *testing.T) {
 		molfar, localStorage, remoteStorage, _, tempDir, _, cleanup := setupMolfarServices(t)
@@ -971,7 +1181,7 @@ func TestMolfarService_Exit(t *testing.T) {
 		assert.True(t, manifestBefore.IsLocked(), "Local manifest should be locked before exit")
 
 		// Execute Exit
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.NoError(t, err)
 
 		// Verify manifests are unlocked after exit and have valid structure
@@ -1064,7 +1274,7 @@ func TestMolfarService_Exit(t *testing.T) {
 	t.Run("nil molfar service", func(t *testing.T) {
 		var molfar *services.MolfarService
 
-		err := molfar.Exit()
+		err := molfar.Exit(context.Background())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "molfar service cannot be nil")
 	})
@@ -1077,8 +1287,8 @@ func TestMolfarService_Exit(t *testing.T) {
 
 		// Create a failing mock backupper
 		failingBackupper := &mocks.MockBackupperService{
-			RunFunc: func(ctx context.Context) (string, error) {
-				return "", errors.New("backupper failed")
+			RunFunc: func(ctx context.Context) (*domain.World, error) {
+				return nil, errors.New("backupper failed")
 			},
 		}
 
@@ -1109,7 +1319,7 @@ func TestMolfarService_Exit(t *testing.T) {
 		// Set lock ID so Exit() doesn't skip early
 		molfar.SetLockIDForTesting("test-lock-id")
 
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "backupper 0 failed")
 	})
@@ -1153,7 +1363,7 @@ func TestMolfarService_Exit(t *testing.T) {
 		molfar.SetLockIDForTesting(lockID)
 
 		// Execute Exit
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.NoError(t, err)
 
 		// Verify remote manifest has current AppVersion stamped
@@ -1209,8 +1419,8 @@ func TestMolfarService_Exit(t *testing.T) {
 
 		// Create backupper that returns a valid archive name
 		mockBackupper := &mocks.MockBackupperService{
-			RunFunc: func(ctx context.Context) (string, error) {
-				return config.RemoteBackups + "/20251227120000.tar", nil
+			RunFunc: func(ctx context.Context) (*domain.World, error) {
+				return &domain.World{URI: config.RemoteBackups + "/20251227120000.tar", Size: 42, Checksum: "abc123", KeyID: "key-1"}, nil
 			},
 		}
 
@@ -1283,7 +1493,7 @@ func TestMolfarService_Exit(t *testing.T) {
 		assert.Equal(t, 5, len(manifestBefore.Backups), "Should start with 5 worlds")
 
 		// Execute Exit
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.NoError(t, err)
 
 		// Verify retention was called
@@ -1311,6 +1521,15 @@ func TestMolfarService_Exit(t *testing.T) {
 		// Verify both manifests are in sync
 		assert.Equal(t, len(localManifestAfterObj.Backups), len(remoteManifestAfterObj.Backups),
 			"Local and remote manifests should have same number of worlds")
+
+		// Verify the new entry keeps the size and checksum reported by the backupper
+		latest := remoteManifestAfterObj.GetLatestWorld()
+		if assert.NotNil(t, latest) {
+			assert.Equal(t, config.RemoteBackups+"/20251227120000.tar", latest.URI)
+			assert.Equal(t, int64(42), latest.Size)
+			assert.Equal(t, "abc123", latest.Checksum)
+			assert.Equal(t, "key-1", latest.KeyID)
+		}
 	})
 }
 
@@ -1348,7 +1567,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 					}
 
 		// This should succeed since hostname resolution works in normal test environment
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err, "Lock acquisition should succeed with valid hostname")
 
 		// Verify manifests are locked after successful run
@@ -1390,7 +1609,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 			Port:    25565,
 					}
 
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.Error(t, err)
 
 		// Verify local manifest was not locked due to remote failure
@@ -1445,7 +1664,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 		molfar.SetLockIDForTesting("my-process::9876543210")
 
 		// Try to exit - should fail because manifest lock doesn't match our lock
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "lock ownership validation failed")
 	})
@@ -1480,7 +1699,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 					}
 
 		// Run should succeed
-		err = molfar.Run(server)
+		err = molfar.Run(context.Background(), server)
 		assert.NoError(t, err, "Run should succeed")
 
 		// Verify manifests are locked
@@ -1497,7 +1716,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 		defer cleanup()
 
 		// Test with nil server
-		err := molfar.Run(nil)
+		err := molfar.Run(context.Background(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "server cannot be nil")
 	})
@@ -1537,7 +1756,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 					}
 
 		// Run should fail due to lock acquired between Prepare and Run
-		err = molfar1.Run(server)
+		err = molfar1.Run(context.Background(), server)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "local manifest already locked")
 	})
@@ -1586,7 +1805,7 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 		remoteStorage.Delete(ctx, "manifest.json")
 
 		// Exit should succeed - the remote manifest will be recreated when needed
-		err = molfar.Exit()
+		err = molfar.Exit(context.Background())
 		assert.NoError(t, err)
 
 		// Verify local manifest was unlocked
@@ -1601,6 +1820,222 @@ func TestMolfarService_LockMechanisms(t *testing.T) {
 	})
 }
 
+func TestMolfarService_ExclusiveLockAcrossHosts(t *testing.T) {
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	assert.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	assert.NoError(t, err)
+	defer remoteStorage.Close()
+
+	ctx := context.Background()
+	world := createTestWorld(config.RemoteBackups + "/1234567890.tar")
+	manifestData, err := json.Marshal(createTestManifest("1.0.0", "1.0.0", []domain.World{world}))
+	assert.NoError(t, err)
+	assert.NoError(t, remoteStorage.Put(ctx, "manifest.json", manifestData))
+
+	// Each host has its own local storage and librarian; only the remote is shared
+	const hosts = 5
+	molfars := make([]*services.MolfarService, hosts)
+	for i := range molfars {
+		localRoot, err := os.OpenRoot(t.TempDir())
+		assert.NoError(t, err)
+		localStorage, err := adapters.NewFSRepository(localRoot)
+		assert.NoError(t, err)
+		defer localStorage.Close()
+		assert.NoError(t, localStorage.Put(ctx, "manifest.json", manifestData))
+
+		librarianService, err := services.NewLibrarianService(localStorage, remoteStorage)
+		assert.NoError(t, err)
+		molfars[i], err = services.NewMolfarService(
+			[]ports.ConditionService{},
+			[]ports.UpdaterService{},
+			[]ports.BackupperService{},
+			[]ports.RetentionService{},
+			&MockServerRunner{},
+			librarianService,
+			nil,
+			localRoot,
+		)
+		assert.NoError(t, err)
+	}
+
+	server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565}
+	results := make([]error, hosts)
+	var wg sync.WaitGroup
+	for i, molfar := range molfars {
+		wg.Add(1)
+		go func(i int, molfar *services.MolfarService) {
+			defer wg.Done()
+			results[i] = molfar.Run(ctx, server)
+		}(i, molfar)
+	}
+	wg.Wait()
+
+	winners := 0
+	for _, err := range results {
+		if err == nil {
+			winners++
+		}
+	}
+	assert.Equal(t, 1, winners, "exactly one host should acquire the lock: %v", results)
+
+	data, err := remoteStorage.Get(ctx, "manifest.json")
+	assert.NoError(t, err)
+	var remoteManifest domain.Manifest
+	assert.NoError(t, json.Unmarshal(data, &remoteManifest))
+	assert.True(t, remoteManifest.IsLocked())
+
+	leaseData, err := remoteStorage.Get(ctx, config.LockFilename)
+	assert.NoError(t, err)
+	var lease domain.Lease
+	assert.NoError(t, json.Unmarshal(leaseData, &lease))
+	assert.Equal(t, remoteManifest.LockedBy, lease.SessionID)
+}
+
+// readRemoteLease reads the lease object from remote storage
+func readRemoteLease(t *testing.T, remoteStorage *adapters.FSRepository) (*domain.Lease, error) {
+	t.Helper()
+	data, err := remoteStorage.Get(context.Background(), config.LockFilename)
+	if err != nil {
+		return nil, err
+	}
+	var lease domain.Lease
+	assert.NoError(t, json.Unmarshal(data, &lease))
+	return &lease, nil
+}
+
+func TestMolfarService_Lease(t *testing.T) {
+	server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565}
+
+	t.Run("heartbeat extends lease and exit releases it", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, _, remoteStorage := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+		molfar.SetHeartbeatIntervalForTesting(10 * time.Millisecond)
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- molfar.Run(context.Background(), server) }()
+		<-runner.started
+
+		acquired, err := readRemoteLease(t, remoteStorage)
+		assert.NoError(t, err)
+		hostname, err := os.Hostname()
+		assert.NoError(t, err)
+		assert.Equal(t, hostname, acquired.Owner)
+		assert.True(t, strings.HasPrefix(acquired.SessionID, hostname+config.LockIDSeparator))
+
+		assert.Eventually(t, func() bool {
+			renewed, err := readRemoteLease(t, remoteStorage)
+			return err == nil && renewed.ExpiresAt.After(acquired.ExpiresAt)
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.NoError(t, runner.Stop(time.Second))
+		assert.NoError(t, <-runErr)
+		assert.NoError(t, molfar.Exit(context.Background()))
+
+		_, err = readRemoteLease(t, remoteStorage)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+
+	t.Run("heartbeat keeps renewing after a failed save", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		// Save 1 acquires the lease, save 2 is the first renewal
+		flaky := &flakyLeaseLibrarian{failOn: map[int]bool{2: true}}
+		molfar, _, remoteStorage := setupShutdownMolfarWithLibrarian(t, runner, &mocks.MockBackupperService{}, func(librarian ports.LibrarianService) ports.LibrarianService {
+			flaky.LibrarianService = librarian
+			return flaky
+		})
+		molfar.SetHeartbeatIntervalForTesting(10 * time.Millisecond)
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- molfar.Run(context.Background(), server) }()
+		<-runner.started
+
+		acquired, err := readRemoteLease(t, remoteStorage)
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			renewed, err := readRemoteLease(t, remoteStorage)
+			return flaky.saveCount() >= 3 && err == nil && renewed.ExpiresAt.After(acquired.ExpiresAt)
+		}, 5*time.Second, 10*time.Millisecond, "the renewal after the failure succeeds")
+
+		assert.NoError(t, runner.Stop(time.Second))
+		assert.NoError(t, <-runErr)
+		assert.NoError(t, molfar.Exit(context.Background()))
+	})
+
+	t.Run("heartbeat stops once another session owns the lease", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, _, remoteStorage := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+		molfar.SetHeartbeatIntervalForTesting(10 * time.Millisecond)
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- molfar.Run(context.Background(), server) }()
+		<-runner.started
+
+		// Another host broke the lock and took the lease over
+		other, err := domain.NewLease("otherhost", "otherhost::1", time.Minute)
+		require.NoError(t, err)
+		leaseData, err := json.Marshal(other)
+		require.NoError(t, err)
+		require.NoError(t, remoteStorage.Put(context.Background(), config.LockFilename, leaseData))
+
+		time.Sleep(100 * time.Millisecond)
+		current, err := readRemoteLease(t, remoteStorage)
+		require.NoError(t, err)
+		assert.Equal(t, "otherhost::1", current.SessionID, "the other session's lease is not overwritten")
+
+		assert.NoError(t, runner.Stop(time.Second))
+		assert.NoError(t, <-runErr)
+	})
+
+	t.Run("live lease of another host blocks run", func(t *testing.T) {
+		molfar, localStorage, remoteStorage := setupShutdownMolfar(t, &MockServerRunner{}, &mocks.MockBackupperService{})
+
+		other, err := domain.NewLease("otherhost", "otherhost::1", time.Minute)
+		assert.NoError(t, err)
+		leaseData, err := json.Marshal(other)
+		assert.NoError(t, err)
+		assert.NoError(t, remoteStorage.Put(context.Background(), config.LockFilename, leaseData))
+
+		err = molfar.Run(context.Background(), server)
+		assert.ErrorIs(t, err, services.ErrLeaseHeld)
+
+		for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+			data, err := storage.Get(context.Background(), "manifest.json")
+			assert.NoError(t, err)
+			var manifest domain.Manifest
+			assert.NoError(t, json.Unmarshal(data, &manifest))
+			assert.False(t, manifest.IsLocked())
+		}
+
+		current, err := readRemoteLease(t, remoteStorage)
+		assert.NoError(t, err)
+		assert.Equal(t, "otherhost::1", current.SessionID)
+	})
+
+	t.Run("expired leftover lease is replaced", func(t *testing.T) {
+		molfar, _, remoteStorage := setupShutdownMolfar(t, &MockServerRunner{}, &mocks.MockBackupperService{})
+
+		stale := &domain.Lease{
+			Owner:      "otherhost",
+			SessionID:  "otherhost::1",
+			AcquiredAt: time.Now().Add(-time.Hour),
+			ExpiresAt:  time.Now().Add(-time.Minute),
+		}
+		leaseData, err := json.Marshal(stale)
+		assert.NoError(t, err)
+		assert.NoError(t, remoteStorage.Put(context.Background(), config.LockFilename, leaseData))
+
+		assert.NoError(t, molfar.Run(context.Background(), server))
+
+		current, err := readRemoteLease(t, remoteStorage)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "otherhost::1", current.SessionID)
+		assert.False(t, current.IsExpired(time.Now()))
+
+		assert.NoError(t, molfar.Exit(context.Background()))
+	})
+}
+
 func TestNewMolfarService(t *testing.T) {
 	t.Run("nil conditions slice returns error", func(t *testing.T) {
 		tempDir := t.TempDir()
@@ -2007,3 +2442,246 @@ type FailingMockServerRunner struct{}
 func (m *FailingMockServerRunner) Run(server *domain.Server) error {
 	return errors.New("server execution failed")
 }
+
+func TestMolfarService_Exit_RecordsBackupNote(t *testing.T) {
+	ctx := context.Background()
+	tempRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	defer tempRoot.Close()
+	storage, err := adapters.NewFSRepository(tempRoot)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	lockID := "test-host::1234567890"
+	manifest := createTestManifest("1.0.0", "1.20.1", []domain.World{createTestWorld(config.RemoteBackups + "/old")})
+	manifest.Lock(lockID)
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, config.ManifestFilename, data))
+
+	backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+		return &domain.World{
+			URI:              config.RemoteBackups + "/new" + config.BackupExtension,
+			CreatedAt:        time.Now(),
+			Host:             "test-host",
+			Players:          []string{"owl"},
+			MinecraftVersion: "1.20.1",
+		}, nil
+	}}
+
+	events := make(chan ports.Event, 100)
+	var prompts []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range events {
+			if prompt, ok := evt.(ports.PromptEvent); ok {
+				prompts = append(prompts, prompt.ID)
+				prompt.ResponseChan <- " end of season 1 "
+			}
+		}
+	}()
+
+	librarian, err := services.NewLibrarianService(storage, storage)
+	require.NoError(t, err)
+	molfar, err := services.NewMolfarService([]ports.ConditionService{}, []ports.UpdaterService{}, []ports.BackupperService{backupper},
+		[]ports.RetentionService{}, &MockServerRunner{}, librarian, events, tempRoot)
+	require.NoError(t, err)
+	molfar.SetLockIDForTesting(lockID)
+
+	require.NoError(t, molfar.Exit(ctx))
+	close(events)
+	<-done
+
+	assert.Equal(t, []string{"backup_note"}, prompts)
+	saved := readTestManifest(t, storage)
+	latest := saved.GetLatestWorld()
+	require.NotNil(t, latest)
+	assert.Equal(t, "end of season 1", latest.Note)
+	assert.Equal(t, "test-host", latest.Host, "backupper metadata is kept")
+	assert.Equal(t, []string{"owl"}, latest.Players)
+	assert.Equal(t, "1.20.1", latest.MinecraftVersion)
+	assert.Equal(t, config.AppVersion, latest.RitualVersion)
+}
+
+// setupNoteMolfar creates a molfar whose backupper produces a new world and returns its remote storage
+// Prompts are recorded and answered by answer unless it is empty; the returned function closes
+// the event channel and returns the recorded prompt IDs
+func setupNoteMolfar(t *testing.T, runner ports.ServerRunner, lockID, answer string) (*services.MolfarService, *adapters.FSRepository, func() []string) {
+	t.Helper()
+	tempRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	localStorage, err := adapters.NewFSRepository(tempRoot)
+	require.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		localStorage.Close()
+		remoteStorage.Close()
+	})
+
+	manifest := createTestManifest("1.0.0", "1.20.1", []domain.World{createTestWorld(config.RemoteBackups + "/old")})
+	manifest.LockedBy = lockID
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+		require.NoError(t, storage.Put(context.Background(), config.ManifestFilename, data))
+	}
+
+	backupper := &mocks.MockBackupperService{RunFunc: func(ctx context.Context) (*domain.World, error) {
+		return &domain.World{URI: config.RemoteBackups + "/new" + config.BackupExtension, CreatedAt: time.Now()}, nil
+	}}
+
+	events := make(chan ports.Event, 100)
+	var prompts []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range events {
+			if prompt, ok := evt.(ports.PromptEvent); ok {
+				prompts = append(prompts, prompt.ID)
+				if answer != "" {
+					prompt.ResponseChan <- answer
+				}
+			}
+		}
+	}()
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	molfar, err := services.NewMolfarService([]ports.ConditionService{}, []ports.UpdaterService{}, []ports.BackupperService{backupper},
+		[]ports.RetentionService{}, runner, librarian, events, tempRoot)
+	require.NoError(t, err)
+	molfar.SetLockIDForTesting(lockID)
+
+	return molfar, remoteStorage, func() []string {
+		close(events)
+		<-done
+		return prompts
+	}
+}
+
+func TestMolfarService_Exit_BackupNoteNeverBlocks(t *testing.T) {
+	t.Run("unanswered note prompt times out", func(t *testing.T) {
+		molfar, storage, finish := setupNoteMolfar(t, &MockServerRunner{}, "test-host::1234567890", "")
+		molfar.SetNoteTimeoutForTesting(10 * time.Millisecond)
+
+		require.NoError(t, molfar.Exit(context.Background()))
+		assert.Equal(t, []string{"backup_note"}, finish())
+
+		latest := readTestManifest(t, storage).GetLatestWorld()
+		require.NotNil(t, latest)
+		assert.Equal(t, config.RemoteBackups+"/new"+config.BackupExtension, latest.URI)
+		assert.Empty(t, latest.Note)
+	})
+
+	t.Run("no note is asked for after a shutdown request", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, storage, finish := setupNoteMolfar(t, runner, "", "ignored")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- molfar.Run(ctx, &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565})
+		}()
+		select {
+		case <-runner.started:
+		case err := <-runErr:
+			t.Fatalf("run ended before the server started: %v", err)
+		}
+		cancel()
+		assert.ErrorIs(t, <-runErr, services.ErrShutdownRequested)
+
+		require.NoError(t, molfar.Exit(context.Background()))
+		assert.Empty(t, finish())
+
+		latest := readTestManifest(t, storage).GetLatestWorld()
+		require.NotNil(t, latest)
+		assert.Equal(t, config.RemoteBackups+"/new"+config.BackupExtension, latest.URI)
+		assert.Empty(t, latest.Note)
+	})
+}
+
+func TestMolfarService_Checkpoints(t *testing.T) {
+	server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565}
+
+	t.Run("checkpoints run while the server runs and the lock is held", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, _, remoteStorage := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+
+		var lockID string
+		stopped := false
+		molfar.EnableCheckpoints(&mocks.MockCheckpointService{RunFunc: func(ctx context.Context, id string) {
+			lockID = id
+			<-ctx.Done()
+			stopped = true
+		}})
+
+		runCtx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-runner.started
+			cancel()
+		}()
+
+		err := molfar.Run(runCtx, server)
+		assert.ErrorIs(t, err, services.ErrShutdownRequested)
+		assert.True(t, stopped, "checkpoints stop before Run returns")
+		assert.Equal(t, readTestManifest(t, remoteStorage).LockedBy, lockID)
+		assert.NoError(t, molfar.Exit(context.Background()))
+	})
+
+	t.Run("final backup supersedes the session's checkpoints", func(t *testing.T) {
+		runner := newConsoleServerRunner()
+		molfar, localStorage, remoteStorage := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+
+		checkpoint := domain.World{URI: config.RemoteBackups + "/checkpoint.tar", CreatedAt: time.Now(), Intermediate: true}
+		molfar.EnableCheckpoints(&mocks.MockCheckpointService{RunFunc: func(ctx context.Context, lockID string) {
+			for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+				manifest := readTestManifest(t, storage)
+				manifest.AddWorld(checkpoint)
+				data, err := json.Marshal(manifest)
+				assert.NoError(t, err)
+				assert.NoError(t, storage.Put(ctx, config.ManifestFilename, data))
+			}
+			close(runner.stopped)
+		}})
+
+		require.NoError(t, molfar.Run(context.Background(), server))
+		assert.NotNil(t, readTestManifest(t, remoteStorage).FindBackup(checkpoint.URI), "checkpoint is recorded during the session")
+
+		require.NoError(t, molfar.Exit(context.Background()))
+		for _, storage := range []*adapters.FSRepository{localStorage, remoteStorage} {
+			manifest := readTestManifest(t, storage)
+			assert.Nil(t, manifest.FindBackup(checkpoint.URI))
+			assert.Equal(t, "mock-archive.zip", manifest.GetLatestWorld().URI)
+		}
+	})
+}
+
+func TestMolfarService_HealthProbe(t *testing.T) {
+	server := &domain.Server{Address: "127.0.0.1:25565", Memory: 2048, IP: "127.0.0.1", Port: 25565}
+	runner := newConsoleServerRunner()
+	molfar, _, _ := setupShutdownMolfar(t, runner, &mocks.MockBackupperService{})
+
+	var probed *domain.Server
+	stopped := false
+	molfar.EnableHealthProbe(&mocks.MockHealthProbeService{RunFunc: func(ctx context.Context, s *domain.Server) {
+		probed = s
+		<-ctx.Done()
+		stopped = true
+	}})
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-runner.started
+		cancel()
+	}()
+
+	err := molfar.Run(runCtx, server)
+	assert.ErrorIs(t, err, services.ErrShutdownRequested)
+	assert.True(t, stopped, "the probe stops before Run returns")
+	assert.Same(t, server, probed)
+	assert.NoError(t, molfar.Exit(context.Background()))
+}
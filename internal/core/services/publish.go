@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"ritual/internal/adapters/streamer"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"strings"
+)
+
+// PublishService error constants
+var (
+	ErrPublishNil           = errors.New("publish service cannot be nil")
+	ErrPublishLibrarianNil  = errors.New("librarian service cannot be nil")
+	ErrPublishUploaderNil   = errors.New("uploader cannot be nil")
+	ErrPublishDownloaderNil = errors.New("downloader cannot be nil")
+	ErrPublishListerNil     = errors.New("lister cannot be nil")
+	ErrPublishWorkRootNil   = errors.New("workRoot cannot be nil")
+	ErrPublishVersionEmpty  = errors.New("instance version cannot be empty")
+	ErrPublishDeclined      = errors.New("publish was not confirmed")
+)
+
+// PublishService uploads the local instance as a file index and records it
+// as the instance version every other host updates to
+type PublishService struct {
+	librarian  ports.LibrarianService
+	uploader   streamer.S3StreamUploader
+	downloader streamer.S3StreamDownloader
+	lister     streamer.S3StreamLister
+	bucket     string
+	workRoot   *os.Root
+	keys       *streamer.Keyring // Optional: encrypts published chunks
+	events     chan<- ports.Event
+}
+
+// NewPublishService creates a new publish service
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewPublishService(
+	librarian ports.LibrarianService,
+	uploader streamer.S3StreamUploader,
+	downloader streamer.S3StreamDownloader,
+	lister streamer.S3StreamLister,
+	bucket string,
+	workRoot *os.Root,
+	keys *streamer.Keyring,
+	events chan<- ports.Event,
+) (*PublishService, error) {
+	if librarian == nil {
+		return nil, ErrPublishLibrarianNil
+	}
+	if uploader == nil {
+		return nil, ErrPublishUploaderNil
+	}
+	if downloader == nil {
+		return nil, ErrPublishDownloaderNil
+	}
+	if lister == nil {
+		return nil, ErrPublishListerNil
+	}
+	if workRoot == nil {
+		return nil, ErrPublishWorkRootNil
+	}
+
+	return &PublishService{
+		librarian:  librarian,
+		uploader:   uploader,
+		downloader: downloader,
+		lister:     lister,
+		bucket:     bucket,
+		workRoot:   workRoot,
+		keys:       keys,
+		events:     events,
+	}, nil
+}
+
+// send safely sends an event to the channel
+func (p *PublishService) send(evt ports.Event) {
+	ports.SendEvent(p.events, evt)
+}
+
+// Publish uploads the local instance under the given version while holding the manifest lock
+// World directories and protected paths are left out of the published index
+func (p *PublishService) Publish(ctx context.Context, version string) error {
+	if p == nil {
+		return ErrPublishNil
+	}
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return ErrPublishVersionEmpty
+	}
+
+	p.send(ports.StartEvent{Operation: "publish"})
+
+	locker, err := NewManifestLocker(p.librarian, "publish", p.events)
+	if err != nil {
+		return err
+	}
+	remoteManifest, err := locker.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	publishErr := p.publishLocked(ctx, version, remoteManifest)
+
+	// Release with a context that survives cancellation so the lock is not left behind
+	if err := locker.Release(context.WithoutCancel(ctx), remoteManifest); err != nil {
+		if publishErr != nil {
+			return fmt.Errorf("%w; additionally failed to release lock: %w", publishErr, err)
+		}
+		return fmt.Errorf("instance published but failed to release lock: %w", err)
+	}
+	if publishErr != nil {
+		return publishErr
+	}
+
+	p.send(ports.FinishEvent{Operation: "publish"})
+	return nil
+}
+
+// publishLocked uploads and verifies the instance index and records it in both manifests
+// remoteManifest is updated in place and saved by the caller on release
+func (p *PublishService) publishLocked(ctx context.Context, version string, remoteManifest *domain.Manifest) error {
+	if _, err := p.workRoot.Stat(config.InstanceDir); err != nil {
+		return fmt.Errorf("instance directory not found: %w", err)
+	}
+
+	prompt := fmt.Sprintf("Publish the local instance as version %s (currently %s)?", version, remoteManifest.InstanceVersion)
+	if !promptConfirm(p.events, "confirm_publish", prompt) {
+		return ErrPublishDeclined
+	}
+
+	codec, err := streamer.CodecByName(config.BackupCodec)
+	if err != nil {
+		return fmt.Errorf("invalid backup codec: %w", err)
+	}
+
+	key := config.InstanceIndexKey
+	p.send(ports.UpdateEvent{Operation: "publish", Message: "Uploading instance", Data: map[string]any{"version": version}})
+	result, err := streamer.PushChunks(ctx, streamer.PushConfig{
+		Dirs:       []string{filepath.Join(p.workRoot.Name(), config.InstanceDir)},
+		Bucket:     p.bucket,
+		Key:        key,
+		Events:     p.events,
+		Codec:      codec,
+		EncryptKey: p.keys.Current(),
+		Exclude:    instanceProtection(remoteManifest),
+	}, p.uploader, p.lister)
+	if err != nil {
+		return fmt.Errorf("failed to upload instance: %w", err)
+	}
+
+	p.send(ports.UpdateEvent{Operation: "publish", Message: "Verifying uploaded instance index", Data: map[string]any{"key": key}})
+	if err := streamer.Verify(ctx, streamer.VerifyConfig{
+		Bucket:   p.bucket,
+		Key:      key,
+		Size:     result.Size,
+		Checksum: result.Checksum,
+	}, p.downloader); err != nil {
+		return fmt.Errorf("uploaded instance index failed verification: %w", err)
+	}
+
+	remoteManifest.InstanceVersion = version
+	remoteManifest.InstanceIndex = key
+	remoteManifest.InstanceChecksum = result.Checksum
+	if err := p.librarian.SaveRemoteManifest(ctx, remoteManifest); err != nil {
+		return fmt.Errorf("failed to record published instance in remote manifest: %w", err)
+	}
+
+	// The local instance is the published one, so this host does not download it again
+	localManifest, err := p.librarian.GetLocalManifest(ctx)
+	if err != nil || localManifest == nil {
+		localManifest = remoteManifest.Clone()
+	}
+	localManifest.InstanceVersion = version
+	localManifest.InstanceIndex = key
+	localManifest.InstanceChecksum = result.Checksum
+	if err := p.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+		return fmt.Errorf("failed to record published instance in local manifest: %w", err)
+	}
+
+	p.send(ports.UpdateEvent{Operation: "publish", Message: "Instance published", Data: map[string]any{
+		"version":    version,
+		"new_chunks": result.NewChunks,
+	}})
+	return nil
+}
@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishHost is a ritual root with its own local storage sharing a remote store
+type publishHost struct {
+	root         *os.Root
+	dir          string
+	localStorage *adapters.FSRepository
+	librarian    *services.LibrarianService
+}
+
+// newPublishHost creates a host whose instance holds files (slash paths to content)
+func newPublishHost(t *testing.T, remoteStorage *adapters.FSRepository, manifest *domain.Manifest, files map[string]string) *publishHost {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(dir, config.InstanceDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	localStorage, err := adapters.NewFSRepository(root)
+	require.NoError(t, err)
+	t.Cleanup(func() { localStorage.Close() })
+
+	if manifest != nil {
+		data, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		require.NoError(t, localStorage.Put(context.Background(), config.ManifestFilename, data))
+	}
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	return &publishHost{root: root, dir: dir, localStorage: localStorage, librarian: librarian}
+}
+
+// answerPrompts answers every prompt on a new event channel with answer
+func answerPrompts(t *testing.T, answer string) chan ports.Event {
+	t.Helper()
+	events := make(chan ports.Event, 100)
+	go func() {
+		for evt := range events {
+			if prompt, ok := evt.(ports.PromptEvent); ok {
+				prompt.ResponseChan <- answer
+			}
+		}
+	}()
+	t.Cleanup(func() { close(events) })
+	return events
+}
+
+// setupPublishRemote stores a remote manifest at instance version 1.0.0
+func setupPublishRemote(t *testing.T) (*adapters.FSRepository, *mockStreamUploader, *domain.Manifest) {
+	t.Helper()
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	require.NoError(t, err)
+	t.Cleanup(func() { remoteStorage.Close() })
+
+	manifest := createWorldsTestManifest("1.0.0", "1.0.0", []domain.World{})
+	manifest.WorldDirs = []string{"world"}
+	manifest.ProtectedPaths = []string{"plugins/LuckPerms/data"}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, remoteStorage.Put(context.Background(), config.ManifestFilename, data))
+
+	return remoteStorage, &mockStreamUploader{storage: remoteStorage}, manifest
+}
+
+func TestNewPublishService(t *testing.T) {
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	defer root.Close()
+	librarian := &mocks.MockLibrarianService{}
+	store := &mockStreamUploader{}
+	lister := &adapters.FSRepository{}
+
+	_, err = services.NewPublishService(nil, store, store, lister, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrPublishLibrarianNil)
+	_, err = services.NewPublishService(librarian, nil, store, lister, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrPublishUploaderNil)
+	_, err = services.NewPublishService(librarian, store, nil, lister, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrPublishDownloaderNil)
+	_, err = services.NewPublishService(librarian, store, store, nil, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrPublishListerNil)
+	_, err = services.NewPublishService(librarian, store, store, lister, "bucket", nil, nil, nil)
+	assert.ErrorIs(t, err, services.ErrPublishWorkRootNil)
+
+	service, err := services.NewPublishService(librarian, store, store, lister, "bucket", root, nil, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.Publish(context.Background(), " "), services.ErrPublishVersionEmpty)
+
+	var nilService *services.PublishService
+	assert.ErrorIs(t, nilService.Publish(context.Background(), "2.0.0"), services.ErrPublishNil)
+}
+
+func TestPublishService_DeltaUpdate(t *testing.T) {
+	ctx := context.Background()
+	remoteStorage, store, remoteManifest := setupPublishRemote(t)
+
+	publisher := newPublishHost(t, remoteStorage, remoteManifest, map[string]string{
+		"paper.jar":                       "paper v2",
+		"plugins/Essentials.jar":          "essentials",
+		"plugins/NewPlugin.jar":           "new plugin",
+		"plugins/LuckPerms/data/perms.db": "publisher perms",
+		"world/level.dat":                 "publisher world",
+		"logs/latest.log":                 "publisher log",
+		"server.properties":               "rcon.password=publisher-secret",
+	})
+	publishService, err := services.NewPublishService(publisher.librarian, store, store, remoteStorage, "bucket", publisher.root, nil, answerPrompts(t, "y"))
+	require.NoError(t, err)
+	require.NoError(t, publishService.Publish(ctx, "2.0.0"))
+
+	published := readTestManifest(t, remoteStorage)
+	assert.False(t, published.IsLocked())
+	assert.Equal(t, "2.0.0", published.InstanceVersion)
+	assert.Equal(t, config.InstanceIndexKey, published.InstanceIndex)
+	assert.NotEmpty(t, published.InstanceChecksum)
+	assert.Equal(t, "2.0.0", readTestManifest(t, publisher.localStorage).InstanceVersion)
+
+	// Excluded paths are not part of the index
+	index, err := remoteStorage.Get(ctx, config.InstanceIndexKey)
+	require.NoError(t, err)
+	for _, excluded := range []string{"publisher world", "world/level.dat", "logs/latest.log", "perms.db", "server.properties", "publisher-secret"} {
+		assert.NotContains(t, string(index), excluded)
+	}
+
+	// A host on the previous version downloads only what changed
+	consumerManifest := createWorldsTestManifest("1.0.0", "1.0.0", []domain.World{})
+	consumer := newPublishHost(t, remoteStorage, consumerManifest, map[string]string{
+		"paper.jar":                       "paper v1",
+		"plugins/Essentials.jar":          "essentials",
+		"plugins/OldPlugin.jar":           "old plugin",
+		"plugins/LuckPerms/data/perms.db": "local perms",
+		"world/level.dat":                 "local world",
+		"logs/latest.log":                 "local log",
+		"server.properties":               "rcon.password=local-secret",
+	})
+	validator, err := services.NewValidatorService()
+	require.NoError(t, err)
+	updater, err := services.NewInstanceUpdater(consumer.librarian, validator, store, "bucket", consumer.root, nil)
+	require.NoError(t, err)
+	require.NoError(t, updater.Run(ctx))
+
+	read := func(path string) string {
+		data, err := os.ReadFile(filepath.Join(consumer.dir, config.InstanceDir, filepath.FromSlash(path)))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "paper v2", read("paper.jar"))
+	assert.Equal(t, "new plugin", read("plugins/NewPlugin.jar"))
+	assert.Equal(t, "local perms", read("plugins/LuckPerms/data/perms.db"))
+	assert.Equal(t, "local world", read("world/level.dat"))
+	assert.Equal(t, "local log", read("logs/latest.log"))
+	assert.Equal(t, "rcon.password=local-secret", read("server.properties"))
+
+	_, err = os.Stat(filepath.Join(consumer.dir, config.InstanceDir, "plugins", "OldPlugin.jar"))
+	assert.True(t, os.IsNotExist(err), "plugin removed upstream should be deleted")
+
+	updated := readTestManifest(t, consumer.localStorage)
+	assert.Equal(t, "2.0.0", updated.InstanceVersion)
+	assert.Equal(t, config.InstanceIndexKey, updated.InstanceIndex)
+}
+
+func TestPublishService_Declined(t *testing.T) {
+	ctx := context.Background()
+	remoteStorage, store, remoteManifest := setupPublishRemote(t)
+	publisher := newPublishHost(t, remoteStorage, remoteManifest, map[string]string{"paper.jar": "paper v2"})
+
+	service, err := services.NewPublishService(publisher.librarian, store, store, remoteStorage, "bucket", publisher.root, nil, answerPrompts(t, "n"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.Publish(ctx, "2.0.0"), services.ErrPublishDeclined)
+
+	manifest := readTestManifest(t, remoteStorage)
+	assert.False(t, manifest.IsLocked())
+	assert.Equal(t, "1.0.0", manifest.InstanceVersion)
+	assert.Empty(t, manifest.InstanceIndex)
+
+	_, err = remoteStorage.Get(ctx, config.InstanceIndexKey)
+	assert.ErrorIs(t, err, ports.ErrNotFound)
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"ritual/internal/adapters/streamer"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RestoreService error constants
+var (
+	ErrRestoreNil           = errors.New("restore service cannot be nil")
+	ErrRestoreLibrarianNil  = errors.New("librarian service cannot be nil")
+	ErrRestoreDownloaderNil = errors.New("downloader cannot be nil")
+	ErrRestoreWorkRootNil   = errors.New("workRoot cannot be nil")
+	ErrNoBackups            = errors.New("remote manifest has no backups")
+	ErrRestoreDeclined      = errors.New("restore was not confirmed")
+)
+
+// RestoreService extracts an operator-selected backup from the remote manifest
+// and records it as the new head of both manifests
+type RestoreService struct {
+	librarian  ports.LibrarianService
+	downloader streamer.S3StreamDownloader
+	bucket     string
+	workRoot   *os.Root
+	keys       *streamer.Keyring // Optional: decrypts encrypted backups
+	replicas   []ReplicaTarget   // Optional: tried in order when the primary copy cannot be restored
+	events     chan<- ports.Event
+}
+
+// NewRestoreService creates a new restore service
+// Validates all dependencies are non-nil per NASA JPL defensive programming standards
+func NewRestoreService(
+	librarian ports.LibrarianService,
+	downloader streamer.S3StreamDownloader,
+	bucket string,
+	workRoot *os.Root,
+	keys *streamer.Keyring,
+	events chan<- ports.Event,
+) (*RestoreService, error) {
+	if librarian == nil {
+		return nil, ErrRestoreLibrarianNil
+	}
+	if downloader == nil {
+		return nil, ErrRestoreDownloaderNil
+	}
+	if workRoot == nil {
+		return nil, ErrRestoreWorkRootNil
+	}
+
+	return &RestoreService{
+		librarian:  librarian,
+		downloader: downloader,
+		bucket:     bucket,
+		workRoot:   workRoot,
+		keys:       keys,
+		events:     events,
+	}, nil
+}
+
+// SetReplicas sets the replication targets a backup can be restored from
+func (r *RestoreService) SetReplicas(targets []ReplicaTarget) error {
+	if r == nil {
+		return ErrRestoreNil
+	}
+	if err := validateReplicaTargets(targets); err != nil {
+		return err
+	}
+	r.replicas = targets
+	return nil
+}
+
+// send safely sends an event to the channel
+func (r *RestoreService) send(evt ports.Event) {
+	ports.SendEvent(r.events, evt)
+}
+
+// Restore lets the operator pick a backup, extracts it into the instance directory
+// and moves it to the head of both manifests while holding the manifest lock
+// Returns the restored world as recorded in the manifests
+func (r *RestoreService) Restore(ctx context.Context) (*domain.World, error) {
+	if r == nil {
+		return nil, ErrRestoreNil
+	}
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	r.send(ports.StartEvent{Operation: "restore"})
+
+	locker, err := NewManifestLocker(r.librarian, "restore", r.events)
+	if err != nil {
+		return nil, err
+	}
+	remoteManifest, err := locker.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, restoreErr := r.restoreLocked(ctx, locker.LockID(), remoteManifest)
+
+	// Release with a context that survives cancellation so the lock is not left behind
+	if err := locker.Release(context.WithoutCancel(ctx), remoteManifest); err != nil {
+		if restoreErr != nil {
+			return nil, fmt.Errorf("%w; additionally failed to release lock: %w", restoreErr, err)
+		}
+		return nil, fmt.Errorf("backup restored but failed to release lock: %w", err)
+	}
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+
+	r.send(ports.UpdateEvent{Operation: "restore", Message: "Backup restored", Data: map[string]any{"uri": restored.URI}})
+	r.send(ports.FinishEvent{Operation: "restore"})
+	return restored, nil
+}
+
+// restoreLocked selects, extracts and records the backup while the lock is held
+// remoteManifest is updated in place and saved by the caller on release
+func (r *RestoreService) restoreLocked(ctx context.Context, lockID string, remoteManifest *domain.Manifest) (*domain.World, error) {
+	if len(remoteManifest.Backups) == 0 {
+		return nil, ErrNoBackups
+	}
+
+	backups := sortedBackups(remoteManifest.Backups)
+	selected, err := r.selectBackup(backups, remoteManifest.GetLatestWorld().URI)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := fmt.Sprintf("Restore %s from %s? The current instance world is replaced", selected.URI, selected.CreatedAt.Format(time.RFC3339))
+	if !promptConfirm(r.events, "confirm_restore", prompt) {
+		return nil, ErrRestoreDeclined
+	}
+
+	if err := r.extract(ctx, selected); err != nil {
+		// Extraction is staged, but a failed rollback can leave a mixed world; force a fresh download on next start
+		r.invalidateLocalWorld(ctx, lockID)
+		return nil, err
+	}
+
+	// Move the restored backup to the head so CheckWorld picks it; GetLatestWorld picks it by RestoredAt
+	// CreatedAt is kept so retention and listings still place the backup by when it was made
+	restored := selected
+	restored.RestoredAt = time.Now()
+	var remaining []domain.World
+	for _, world := range remoteManifest.Backups {
+		if world.URI != selected.URI {
+			remaining = append(remaining, world)
+		}
+	}
+	remoteManifest.Backups = remaining
+	remoteManifest.AddWorld(restored)
+
+	if err := r.librarian.SaveRemoteManifest(ctx, remoteManifest); err != nil {
+		r.invalidateLocalWorld(ctx, lockID)
+		return nil, fmt.Errorf("failed to record restored backup in remote manifest: %w", err)
+	}
+
+	// Only the backups change; the local instance fields keep tracking what this host has installed
+	localManifest, err := r.librarian.GetLocalManifest(ctx)
+	if err != nil || localManifest == nil {
+		// With no record of the local instance, the next start updates it
+		localManifest = remoteManifest.Clone()
+		localManifest.InstanceVersion = ""
+		localManifest.InstanceIndex = ""
+		localManifest.InstanceChecksum = ""
+	}
+	localManifest.Backups = remoteManifest.Clone().Backups
+	if err := r.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+		return nil, fmt.Errorf("failed to record restored backup in local manifest: %w", err)
+	}
+
+	return &restored, nil
+}
+
+// selectBackup lists backups newest first, marking the one at currentURI, and prompts for the one to restore
+func (r *RestoreService) selectBackup(backups []domain.World, currentURI string) (domain.World, error) {
+	for i, world := range backups {
+		message := fmt.Sprintf("%d) %s  %s", i+1, world.CreatedAt.Format(time.RFC3339), world.URI)
+		if world.URI == currentURI {
+			message += "  (current)"
+		}
+		if world.Pinned {
+			message += "  [pinned]"
+		}
+		if len(world.Labels) > 0 {
+			message += "  #" + strings.Join(world.Labels, " #")
+		}
+		if world.Note != "" {
+			message += "  - " + world.Note
+		}
+		r.send(ports.UpdateEvent{Operation: "restore", Message: message})
+	}
+
+	prompt := fmt.Sprintf("Backup to restore (1-%d)", len(backups))
+	response, err := promptWithValidation(r.events, prompt, "1", func(input string) error {
+		index, err := strconv.Atoi(input)
+		if err != nil || index < 1 || index > len(backups) {
+			return fmt.Errorf("enter a number between 1 and %d", len(backups))
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.World{}, err
+	}
+
+	index, _ := strconv.Atoi(response)
+	return backups[index-1], nil
+}
+
+// extract downloads the backup and replaces the instance world with its contents
+// Copies are tried in order: the primary remote, then each replica holding the backup
+// The download is verified against the backup's recorded size and checksum when present
+func (r *RestoreService) extract(ctx context.Context, backup domain.World) error {
+	key, valid := sanitizeWorldURI(backup.URI)
+	if !valid {
+		return fmt.Errorf("invalid backup URI: %s", key)
+	}
+
+	sources := r.restoreSources(backup)
+	if len(sources) == 0 {
+		return fmt.Errorf("no configured target holds backup %s", key)
+	}
+
+	var errs []error
+	for _, source := range sources {
+		r.send(ports.UpdateEvent{Operation: "restore", Message: "Downloading backup", Data: map[string]any{"key": key, "target": source.Name}})
+		err := streamer.Pull(ctx, streamer.PullConfig{
+			Bucket:   source.Bucket,
+			Key:      key,
+			Dest:     filepath.Join(r.workRoot.Name(), config.InstanceDir),
+			Conflict: streamer.Replace,
+			Staged:   true,
+			Size:     backup.Size,
+			Checksum: backup.Checksum,
+			Keys:     r.keys,
+		}, source.Downloader)
+		if err == nil {
+			r.send(ports.UpdateEvent{Operation: "restore", Message: "Backup extracted", Data: map[string]any{"key": key, "target": source.Name}})
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+		if ctx.Err() != nil {
+			break
+		}
+		r.send(ports.ErrorEvent{Operation: "restore", Err: fmt.Errorf("restore from %s failed: %w", source.Name, err)})
+	}
+
+	return fmt.Errorf("failed to download and extract backup: %w", errors.Join(errs...))
+}
+
+// restoreSources returns the targets holding backup, primary remote first
+func (r *RestoreService) restoreSources(backup domain.World) []ReplicaTarget {
+	var sources []ReplicaTarget
+	if backup.HeldBy(config.PrimaryReplica) {
+		sources = append(sources, ReplicaTarget{Name: config.PrimaryReplica, Bucket: r.bucket, Downloader: r.downloader})
+	}
+	for _, replica := range r.replicas {
+		if backup.HeldBy(replica.Name) {
+			sources = append(sources, replica)
+		}
+	}
+	return sources
+}
+
+// invalidateLocalWorld clears the local backup history after a partial restore
+// WorldsUpdater then downloads the head again on the next start
+func (r *RestoreService) invalidateLocalWorld(ctx context.Context, lockID string) {
+	ctx = context.WithoutCancel(ctx)
+	localManifest, err := r.librarian.GetLocalManifest(ctx)
+	if err != nil || localManifest == nil || localManifest.LockedBy != lockID {
+		return
+	}
+
+	localManifest.Backups = []domain.World{}
+	if err := r.librarian.SaveLocalManifest(ctx, localManifest); err != nil {
+		r.send(ports.ErrorEvent{Operation: "restore", Err: fmt.Errorf("failed to reset local manifest: %w", err)})
+	}
+}
+
+// sortedBackups returns a copy of backups ordered newest first
+func sortedBackups(backups []domain.World) []domain.World {
+	sorted := make([]domain.World, len(backups))
+	copy(sorted, backups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	return sorted
+}
@@ -0,0 +1,321 @@
+package services_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
+	"ritual/internal/core/ports/mocks"
+	"ritual/internal/core/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markerWorldTar builds a tar holding world/marker.txt with the given content
+func markerWorldTar(t *testing.T, marker string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "world/", Typeflag: tar.TypeDir, Mode: 0755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "world/marker.txt", Mode: 0644, Size: int64(len(marker))}))
+	_, err := tw.Write([]byte(marker))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// restoreFixture holds the storages and backups of a restore test
+type restoreFixture struct {
+	service       *services.RestoreService
+	localStorage  *adapters.FSRepository
+	remoteStorage *adapters.FSRepository
+	workDir       string
+	backups       []domain.World // oldest first
+}
+
+// setupRestore creates three backups and answers the pick and confirm prompts in order
+func setupRestore(t *testing.T, answers ...string) *restoreFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	workDir := t.TempDir()
+	localRoot, err := os.OpenRoot(workDir)
+	require.NoError(t, err)
+	remoteRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	localStorage, err := adapters.NewFSRepository(localRoot)
+	require.NoError(t, err)
+	remoteStorage, err := adapters.NewFSRepository(remoteRoot)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		localStorage.Close()
+		remoteStorage.Close()
+	})
+
+	downloader := &mockWorldsDownloader{data: map[string][]byte{}}
+	base := time.Now().Add(-3 * time.Hour)
+	var backups []domain.World
+	for i, name := range []string{"oldest", "middle", "griefed"} {
+		uri := config.RemoteBackups + "/" + name + config.BackupExtension
+		backups = append(backups, domain.World{URI: uri, CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+		downloader.data[uri] = markerWorldTar(t, name)
+	}
+
+	manifest := createWorldsTestManifest("1.0.0", "1.0.0", backups)
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, remoteStorage.Put(ctx, config.ManifestFilename, data))
+	require.NoError(t, localStorage.Put(ctx, config.ManifestFilename, data))
+
+	events := make(chan ports.Event, 100)
+	go func() {
+		for evt := range events {
+			if prompt, ok := evt.(ports.PromptEvent); ok {
+				answer := prompt.DefaultValue
+				if len(answers) > 0 {
+					answer, answers = answers[0], answers[1:]
+				}
+				prompt.ResponseChan <- answer
+			}
+		}
+	}()
+	t.Cleanup(func() { close(events) })
+
+	librarian, err := services.NewLibrarianService(localStorage, remoteStorage)
+	require.NoError(t, err)
+	service, err := services.NewRestoreService(librarian, downloader, "bucket", localRoot, nil, events)
+	require.NoError(t, err)
+
+	return &restoreFixture{
+		service:       service,
+		localStorage:  localStorage,
+		remoteStorage: remoteStorage,
+		workDir:       workDir,
+		backups:       backups,
+	}
+}
+
+// readTestManifest reads the manifest stored in storage
+func readTestManifest(t *testing.T, storage *adapters.FSRepository) *domain.Manifest {
+	t.Helper()
+	data, err := storage.Get(context.Background(), config.ManifestFilename)
+	require.NoError(t, err)
+	var manifest domain.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return &manifest
+}
+
+func TestNewRestoreService(t *testing.T) {
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	defer root.Close()
+	librarian := &mocks.MockLibrarianService{}
+	downloader := &mockWorldsDownloader{}
+
+	_, err = services.NewRestoreService(nil, downloader, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrRestoreLibrarianNil)
+	_, err = services.NewRestoreService(librarian, nil, "bucket", root, nil, nil)
+	assert.ErrorIs(t, err, services.ErrRestoreDownloaderNil)
+	_, err = services.NewRestoreService(librarian, downloader, "bucket", nil, nil, nil)
+	assert.ErrorIs(t, err, services.ErrRestoreWorkRootNil)
+
+	service, err := services.NewRestoreService(librarian, downloader, "bucket", root, nil, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, service)
+
+	var nilService *services.RestoreService
+	_, err = nilService.Restore(context.Background())
+	assert.ErrorIs(t, err, services.ErrRestoreNil)
+}
+
+func TestRestoreService_Restore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("older backup becomes head of both manifests", func(t *testing.T) {
+		// Backups are listed newest first, so 3 is the oldest
+		f := setupRestore(t, "3", "y")
+
+		restored, err := f.service.Restore(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, f.backups[0].URI, restored.URI)
+
+		marker, err := os.ReadFile(filepath.Join(f.workDir, config.InstanceDir, "world", "marker.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "oldest", string(marker))
+
+		for _, storage := range []*adapters.FSRepository{f.localStorage, f.remoteStorage} {
+			manifest := readTestManifest(t, storage)
+			assert.False(t, manifest.IsLocked())
+			assert.Len(t, manifest.Backups, 3)
+			assert.Equal(t, f.backups[0].URI, manifest.GetLatestWorld().URI)
+			head := manifest.Backups[len(manifest.Backups)-1]
+			assert.Equal(t, f.backups[0].URI, head.URI)
+			assert.True(t, f.backups[0].CreatedAt.Equal(head.CreatedAt), "the backup keeps its creation time")
+			assert.False(t, head.RestoredAt.IsZero())
+		}
+
+		_, err = f.remoteStorage.Get(ctx, config.LockFilename)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+
+		// The worlds updater sees the local world as current
+		validator, err := services.NewValidatorService()
+		require.NoError(t, err)
+		assert.NoError(t, validator.CheckWorld(readTestManifest(t, f.localStorage), readTestManifest(t, f.remoteStorage)))
+	})
+
+	t.Run("outdated local instance is still updated on next start", func(t *testing.T) {
+		f := setupRestore(t, "3", "y")
+		local := readTestManifest(t, f.localStorage)
+		local.InstanceVersion = "0.9.0"
+		local.InstanceChecksum = "old-checksum"
+		data, err := json.Marshal(local)
+		require.NoError(t, err)
+		require.NoError(t, f.localStorage.Put(ctx, config.ManifestFilename, data))
+
+		_, err = f.service.Restore(ctx)
+		require.NoError(t, err)
+
+		localManifest := readTestManifest(t, f.localStorage)
+		remoteManifest := readTestManifest(t, f.remoteStorage)
+		assert.False(t, localManifest.IsLocked())
+		assert.Equal(t, "0.9.0", localManifest.InstanceVersion, "the local instance record is kept")
+		assert.Equal(t, "old-checksum", localManifest.InstanceChecksum)
+		assert.Equal(t, remoteManifest.Backups, localManifest.Backups)
+
+		validator, err := services.NewValidatorService()
+		require.NoError(t, err)
+		assert.ErrorIs(t, validator.CheckInstance(localManifest, remoteManifest), services.ErrOutdatedInstance)
+		assert.NoError(t, validator.CheckWorld(localManifest, remoteManifest))
+	})
+
+	t.Run("missing local manifest records no instance", func(t *testing.T) {
+		f := setupRestore(t, "3", "y")
+		require.NoError(t, f.localStorage.Delete(ctx, config.ManifestFilename))
+
+		_, err := f.service.Restore(ctx)
+		require.NoError(t, err)
+
+		localManifest := readTestManifest(t, f.localStorage)
+		remoteManifest := readTestManifest(t, f.remoteStorage)
+		assert.False(t, localManifest.IsLocked())
+		assert.Empty(t, localManifest.InstanceVersion, "the next start installs the instance")
+		assert.Empty(t, localManifest.InstanceIndex)
+		assert.Empty(t, localManifest.InstanceChecksum)
+		assert.Equal(t, remoteManifest.Backups, localManifest.Backups)
+	})
+
+	t.Run("invalid pick is asked again", func(t *testing.T) {
+		f := setupRestore(t, "9", "abc", "2", "yes")
+
+		restored, err := f.service.Restore(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, f.backups[1].URI, restored.URI)
+	})
+
+	t.Run("declined restore releases lock unchanged", func(t *testing.T) {
+		f := setupRestore(t, "3", "n")
+
+		_, err := f.service.Restore(ctx)
+		assert.ErrorIs(t, err, services.ErrRestoreDeclined)
+
+		manifest := readTestManifest(t, f.remoteStorage)
+		assert.False(t, manifest.IsLocked())
+		assert.Equal(t, f.backups[2].URI, manifest.GetLatestWorld().URI)
+		assert.False(t, readTestManifest(t, f.localStorage).IsLocked())
+
+		_, err = os.Stat(filepath.Join(f.workDir, config.InstanceDir, "world"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("locked manifest is not restored", func(t *testing.T) {
+		f := setupRestore(t, "3", "y")
+		manifest := readTestManifest(t, f.remoteStorage)
+		manifest.LockedBy = "otherhost::1"
+		data, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		require.NoError(t, f.remoteStorage.Put(ctx, config.ManifestFilename, data))
+
+		_, err = f.service.Restore(ctx)
+		assert.ErrorIs(t, err, services.ErrManifestLocked)
+		assert.Equal(t, "otherhost::1", readTestManifest(t, f.remoteStorage).LockedBy)
+	})
+
+	t.Run("failed download resets local world", func(t *testing.T) {
+		f := setupRestore(t, "1", "y")
+		require.NoError(t, f.remoteStorage.Put(ctx, config.ManifestFilename, mustManifestJSON(t, []domain.World{
+			{URI: config.RemoteBackups + "/missing" + config.BackupExtension, CreatedAt: time.Now()},
+		})))
+
+		_, err := f.service.Restore(ctx)
+		assert.Error(t, err)
+
+		local := readTestManifest(t, f.localStorage)
+		assert.False(t, local.IsLocked())
+		assert.Empty(t, local.Backups)
+		assert.False(t, readTestManifest(t, f.remoteStorage).IsLocked())
+	})
+}
+
+// mustManifestJSON marshals a test manifest holding backups
+func mustManifestJSON(t *testing.T, backups []domain.World) []byte {
+	t.Helper()
+	data, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.0.0", backups))
+	require.NoError(t, err)
+	return data
+}
+
+func TestRestoreService_RestoreFromReplica(t *testing.T) {
+	ctx := context.Background()
+	uri := config.RemoteBackups + "/replicated" + config.BackupExtension
+	archive := markerWorldTar(t, "replicated")
+	nas := services.ReplicaTarget{Name: "nas", Bucket: "nas", Downloader: &mockWorldsDownloader{data: map[string][]byte{uri: archive}}}
+
+	t.Run("unreachable primary falls back to a replica holding the backup", func(t *testing.T) {
+		f := setupRestore(t, "1", "y")
+		require.NoError(t, f.service.SetReplicas([]services.ReplicaTarget{nas}))
+		require.NoError(t, f.remoteStorage.Put(ctx, config.ManifestFilename, mustManifestJSON(t, []domain.World{
+			{URI: uri, CreatedAt: time.Now(), Replicas: []string{config.PrimaryReplica, "nas"}},
+		})))
+
+		restored, err := f.service.Restore(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uri, restored.URI)
+		assert.Equal(t, []string{config.PrimaryReplica, "nas"}, restored.Replicas)
+
+		marker, err := os.ReadFile(filepath.Join(f.workDir, config.InstanceDir, "world", "marker.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "replicated", string(marker))
+	})
+
+	t.Run("replica without the backup is not tried", func(t *testing.T) {
+		f := setupRestore(t, "1", "y")
+		require.NoError(t, f.service.SetReplicas([]services.ReplicaTarget{nas}))
+		require.NoError(t, f.remoteStorage.Put(ctx, config.ManifestFilename, mustManifestJSON(t, []domain.World{
+			{URI: uri, CreatedAt: time.Now(), Replicas: []string{config.PrimaryReplica}},
+		})))
+
+		_, err := f.service.Restore(ctx)
+		assert.Error(t, err)
+		_, statErr := os.Stat(filepath.Join(f.workDir, config.InstanceDir, "world"))
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("invalid replica targets are rejected", func(t *testing.T) {
+		f := setupRestore(t)
+		err := f.service.SetReplicas([]services.ReplicaTarget{{Name: config.PrimaryReplica, Downloader: nas.Downloader}})
+		assert.ErrorIs(t, err, services.ErrReplicaTargetNameReserved)
+		err = f.service.SetReplicas([]services.ReplicaTarget{nas, nas})
+		assert.ErrorIs(t, err, services.ErrReplicaTargetNameDuplicate)
+		err = f.service.SetReplicas([]services.ReplicaTarget{{Name: "nas"}})
+		assert.ErrorIs(t, err, services.ErrReplicaTargetDownloaderNil)
+	})
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"ritual/internal/core/ports"
+)
+
+// listSizes lists the keys under prefix with their stored sizes
+// Sizes are missing when the storage cannot report them while listing
+func listSizes(ctx context.Context, storage ports.StorageRepository, prefix string) ([]string, map[string]int64, error) {
+	sized, ok := storage.(ports.SizedLister)
+	if !ok {
+		keys, err := storage.List(ctx, prefix)
+		return keys, map[string]int64{}, err
+	}
+
+	sizes, err := sized.ListSizes(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]string, 0, len(sizes))
+	for key := range sizes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, sizes, nil
+}
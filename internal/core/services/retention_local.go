@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
+	"path"
 	"strings"
+	"time"
 
+	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
@@ -44,59 +46,109 @@ func (r *LocalRetention) send(evt ports.Event) {
 	ports.SendEvent(r.events, evt)
 }
 
-// Apply removes old local backups exceeding the retention limit
-// Keeps only the latest LocalMaxBackups files regardless of manifest
-func (r *LocalRetention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+// Plan evaluates retention against the local backups without deleting anything
+// Backup times come from the timestamp in the filename; files without one are left alone
+// Copies of pinned manifest entries are kept and not counted by the policy
+// Without a policy in the manifest, the newest LocalMaxBackups are kept
+func (r *LocalRetention) Plan(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error) {
 	if r == nil {
-		return ErrLocalRetentionNil
+		return nil, ErrLocalRetentionNil
 	}
 	if ctx == nil {
-		return errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
-	// manifest parameter kept for interface compatibility but not used
 
 	// List all local backups
-	keys, err := r.localStorage.List(ctx, config.LocalBackups)
+	keys, sizes, err := listSizes(ctx, r.localStorage, config.LocalBackups)
 	if err != nil {
-		return fmt.Errorf("failed to list local backups: %w", err)
+		return nil, fmt.Errorf("failed to list local backups: %w", err)
 	}
 
 	// Static bounds check
 	if len(keys) > config.MaxFiles {
-		return fmt.Errorf("too many backup files: %d exceeds limit %d", len(keys), config.MaxFiles)
+		return nil, fmt.Errorf("too many backup files: %d exceeds limit %d", len(keys), config.MaxFiles)
 	}
 
-	// Filter backup files (skip temp files)
+	// Local copies share the file name of their manifest entry
+	pinned := make(map[string]bool)
+	if manifest != nil {
+		for _, world := range manifest.Backups {
+			if world.Pinned {
+				pinned[path.Base(world.URI)] = true
+			}
+		}
+	}
+
+	policy := manifest.GetRetentionPolicy(config.LocalMaxBackups)
+	plan := &domain.RetentionPlan{Scope: "local", Policy: policy.String()}
+
+	// Filter backup files (skip temp files and names without a timestamp); pinned copies are kept
 	var backups []string
+	var createdAt []time.Time
 	for _, key := range keys {
-		if strings.HasSuffix(key, config.BackupExtension) {
-			if strings.Contains(key, "temp_") {
-				continue
-			}
-			backups = append(backups, key)
+		if !strings.HasSuffix(streamer.TrimCodecExtension(key), config.BackupExtension) || strings.Contains(key, "temp_") {
+			continue
 		}
+		created, ok := localBackupTime(key)
+		if !ok {
+			continue
+		}
+		if pinned[path.Base(key)] {
+			plan.Items = append(plan.Items, domain.RetentionItem{Key: key, Size: sizes[key], Reasons: []string{domain.RetainPinned}})
+			continue
+		}
+		backups = append(backups, key)
+		createdAt = append(createdAt, created)
 	}
 
-	// Sort by filename (timestamp in name, newest first)
-	sort.Slice(backups, func(i, j int) bool {
-		return backups[i] > backups[j]
-	})
-
-	// Delete excess backups beyond retention limit
-	if len(backups) > config.LocalMaxBackups {
-		r.send(ports.UpdateEvent{Operation: "retention", Message: "Applying local retention policy", Data: map[string]any{
-			"total":       len(backups),
-			"max_allowed": config.LocalMaxBackups,
-			"to_delete":   len(backups) - config.LocalMaxBackups,
-		}})
-
-		for _, key := range backups[config.LocalMaxBackups:] {
-			r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting local backup", Data: map[string]any{"key": key}})
-			if err := r.localStorage.Delete(ctx, key); err != nil {
-				return fmt.Errorf("failed to delete local backup %s: %w", key, err)
-			}
+	for i, reasons := range policy.Keep(createdAt) {
+		item := domain.RetentionItem{Key: backups[i], Size: sizes[backups[i]], Reasons: reasons}
+		if len(reasons) == 0 {
+			item.Delete = true
+			item.Reasons = []string{domain.RetentionOverLimit}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	return plan, nil
+}
+
+// Apply executes the retention plan, deleting local backups no policy rule keeps
+func (r *LocalRetention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+	plan, err := r.Plan(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	deletions := plan.Deletions()
+	if len(deletions) == 0 {
+		return nil
+	}
+
+	r.send(ports.UpdateEvent{Operation: "retention", Message: "Applying local retention policy", Data: map[string]any{
+		"total":           len(plan.Items),
+		"policy":          plan.Policy,
+		"to_delete":       len(deletions),
+		"reclaimed_bytes": plan.ReclaimedBytes(),
+	}})
+	for _, item := range deletions {
+		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting local backup", Data: map[string]any{"key": item.Key}})
+		if err := r.localStorage.Delete(ctx, item.Key); err != nil {
+			return fmt.Errorf("failed to delete local backup %s: %w", item.Key, err)
 		}
 	}
 
 	return nil
 }
+
+// localBackupTime parses the creation time from a backup filename such as 20240101120000.tar.gz
+func localBackupTime(key string) (time.Time, bool) {
+	name := path.Base(key)
+	if dot := strings.Index(name, "."); dot >= 0 {
+		name = name[:dot]
+	}
+	created, err := time.ParseInLocation(config.TimestampFormat, name, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ritual/internal/adapters"
+	"ritual/internal/config"
+	"ritual/internal/core/domain"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRetention_Apply(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local) // a Wednesday
+	names := []string{
+		now.Format(config.TimestampFormat) + config.BackupExtension,
+		now.Add(-time.Hour).Format(config.TimestampFormat) + config.BackupExtension + ".gz",
+		now.AddDate(0, 0, -8).Format(config.TimestampFormat) + config.BackupExtension,
+		now.AddDate(0, 0, -9).Format(config.TimestampFormat) + config.BackupExtension,
+		"notes" + config.BackupExtension,
+	}
+
+	setup := func(t *testing.T) *adapters.FSRepository {
+		t.Helper()
+		tempDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tempDir, config.LocalBackups), 0755))
+		for _, name := range names {
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, config.LocalBackups, name), []byte("backup data"), 0644))
+		}
+		root, err := os.OpenRoot(tempDir)
+		require.NoError(t, err)
+		storage, err := adapters.NewFSRepository(root)
+		require.NoError(t, err)
+		t.Cleanup(func() { storage.Close() })
+		return storage
+	}
+
+	remaining := func(t *testing.T, storage *adapters.FSRepository) []string {
+		t.Helper()
+		keys, err := storage.List(ctx, config.LocalBackups)
+		require.NoError(t, err)
+		var base []string
+		for _, key := range keys {
+			base = append(base, filepath.Base(key))
+		}
+		return base
+	}
+
+	t.Run("keeps the newest backups without a policy", func(t *testing.T) {
+		storage := setup(t)
+		retention, err := services.NewLocalRetention(storage, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, retention.Apply(ctx, nil))
+		expected := append([]string{names[4]}, names[:config.LocalMaxBackups]...)
+		assert.ElementsMatch(t, expected, remaining(t, storage))
+	})
+
+	t.Run("follows the manifest policy", func(t *testing.T) {
+		storage := setup(t)
+		retention, err := services.NewLocalRetention(storage, nil)
+		require.NoError(t, err)
+
+		manifest := &domain.Manifest{Retention: &domain.RetentionPolicy{Latest: 1, Weekly: 2}}
+		require.NoError(t, retention.Apply(ctx, manifest))
+		// Newest backup, plus the newest from the week before; untimestamped files are left alone
+		assert.ElementsMatch(t, []string{names[0], names[2], names[4]}, remaining(t, storage))
+	})
+	t.Run("keeps copies of pinned backups outside the policy", func(t *testing.T) {
+		storage := setup(t)
+		retention, err := services.NewLocalRetention(storage, nil)
+		require.NoError(t, err)
+
+		manifest := &domain.Manifest{
+			Retention: &domain.RetentionPolicy{Latest: 1},
+			Backups:   []domain.World{{URI: config.RemoteBackups + "/" + names[3], Pinned: true}},
+		}
+		require.NoError(t, retention.Apply(ctx, manifest))
+		assert.ElementsMatch(t, []string{names[0], names[3], names[4]}, remaining(t, storage))
+	})
+}
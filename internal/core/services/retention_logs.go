@@ -44,22 +44,24 @@ func (r *LogRetention) send(evt ports.Event) {
 	ports.SendEvent(r.events, evt)
 }
 
-// Apply removes old log files exceeding the retention limit
+// Plan lists the log files exceeding the retention limit without deleting anything
 // Manifest is not used for logs - retention is based on file count only
-func (r *LogRetention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+func (r *LogRetention) Plan(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error) {
 	if r == nil {
-		return ErrLogRetentionNil
+		return nil, ErrLogRetentionNil
 	}
 	if ctx == nil {
-		return errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
 	// manifest is not used for log retention
 
+	plan := &domain.RetentionPlan{Scope: "logs", Policy: fmt.Sprintf("latest=%d", config.MaxLogFiles)}
+
 	// List all log files
-	keys, err := r.localStorage.List(ctx, config.LogsDir)
+	keys, sizes, err := listSizes(ctx, r.localStorage, config.LogsDir)
 	if err != nil {
 		// If logs dir doesn't exist yet, nothing to clean
-		return nil
+		return plan, nil
 	}
 
 	// Filter only .log files
@@ -70,29 +72,45 @@ func (r *LogRetention) Apply(ctx context.Context, manifest *domain.Manifest) err
 		}
 	}
 
-	// If within limit, nothing to do
-	if len(logFiles) <= config.MaxLogFiles {
-		return nil
-	}
-
 	// Sort by filename (timestamp in name, newest first)
 	sort.Slice(logFiles, func(i, j int) bool {
 		return logFiles[i] > logFiles[j]
 	})
 
-	// Delete oldest logs exceeding limit
-	toDelete := logFiles[config.MaxLogFiles:]
+	// Oldest logs exceeding limit are deleted
+	for i, key := range logFiles {
+		item := domain.RetentionItem{Key: key, Size: sizes[key], Reasons: []string{domain.RetainLatest}}
+		if i >= config.MaxLogFiles {
+			item.Delete = true
+			item.Reasons = []string{domain.RetentionOverLimit}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	return plan, nil
+}
+
+// Apply executes the retention plan, deleting old log files exceeding the retention limit
+func (r *LogRetention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+	plan, err := r.Plan(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	toDelete := plan.Deletions()
+	if len(toDelete) == 0 {
+		return nil
+	}
 
 	r.send(ports.UpdateEvent{Operation: "retention", Message: "Applying log retention policy", Data: map[string]any{
-		"total":       len(logFiles),
+		"total":       len(plan.Items),
 		"max_allowed": config.MaxLogFiles,
 		"to_delete":   len(toDelete),
 	}})
 
-	for _, key := range toDelete {
-		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting old log", Data: map[string]any{"key": key}})
-		if err := r.localStorage.Delete(ctx, key); err != nil {
-			return fmt.Errorf("failed to delete log %s: %w", key, err)
+	for _, item := range toDelete {
+		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting old log", Data: map[string]any{"key": item.Key}})
+		if err := r.localStorage.Delete(ctx, item.Key); err != nil {
+			return fmt.Errorf("failed to delete log %s: %w", item.Key, err)
 		}
 	}
 
@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ritual/internal/config"
+	"ritual/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRetention_Plan(t *testing.T) {
+	ctx := context.Background()
+	storage := newReplicaStorage(t)
+	var logs []string
+	for i := 0; i < config.MaxLogFiles+2; i++ {
+		key := config.LogsDir + "/" + time.Date(2024, 1, 1, 0, i, 0, 0, time.Local).Format(config.TimestampFormat) + config.LogExtension
+		require.NoError(t, storage.Put(ctx, key, []byte("log")))
+		logs = append(logs, key)
+	}
+
+	retention, err := services.NewLogRetention(storage, nil)
+	require.NoError(t, err)
+
+	plan, err := retention.Plan(ctx, nil)
+	require.NoError(t, err)
+	deletions := plan.Deletions()
+	require.Len(t, deletions, 2)
+	assert.ElementsMatch(t, logs[:2], []string{deletions[0].Key, deletions[1].Key})
+	assert.Equal(t, int64(6), plan.ReclaimedBytes())
+
+	keys, err := storage.List(ctx, config.LogsDir)
+	require.NoError(t, err)
+	assert.Len(t, keys, len(logs), "planning deletes nothing")
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
@@ -21,6 +23,7 @@ var (
 // R2Retention implements RetentionService for R2 backup storage
 type R2Retention struct {
 	remoteStorage ports.StorageRepository
+	target        string // Replication target the storage serves; config.PrimaryReplica for the primary remote
 	events        chan<- ports.Event
 }
 
@@ -35,49 +38,66 @@ func NewR2Retention(remoteStorage ports.StorageRepository, events chan<- ports.E
 
 	return &R2Retention{
 		remoteStorage: remoteStorage,
+		target:        config.PrimaryReplica,
 		events:        events,
 	}, nil
 }
 
+// NewReplicaRetention creates a retention service for the storage of a replication target
+// Backups the target never received are skipped when counting chunk references
+func NewReplicaRetention(target string, storage ports.StorageRepository, events chan<- ports.Event) (*R2Retention, error) {
+	if target == "" {
+		return nil, ErrReplicaTargetNameEmpty
+	}
+	retention, err := NewR2Retention(storage, events)
+	if err != nil {
+		return nil, err
+	}
+	retention.target = target
+	return retention, nil
+}
+
 // send safely sends an event to the channel
 func (r *R2Retention) send(evt ports.Event) {
 	ports.SendEvent(r.events, evt)
 }
 
-// Apply removes old R2 backups exceeding the retention limit
-// Keeps only backups that are in manifest's Backups, up to R2MaxBackups
-func (r *R2Retention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+// Plan evaluates retention against the stored backups without deleting anything
+// Keeps only backups that are in manifest's Backups and pinned or kept by its retention policy
+// Without a policy in the manifest, the newest R2MaxBackups are kept; pinned backups do not count
+// Chunks no retained chunk index references are planned for deletion too
+func (r *R2Retention) Plan(ctx context.Context, manifest *domain.Manifest) (*domain.RetentionPlan, error) {
 	if r == nil {
-		return ErrR2RetentionNil
+		return nil, ErrR2RetentionNil
 	}
 	if ctx == nil {
-		return errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
 	if manifest == nil {
-		return errors.New("manifest cannot be nil")
+		return nil, errors.New("manifest cannot be nil")
 	}
 
 	// List all R2 backups
-	keys, err := r.remoteStorage.List(ctx, config.RemoteBackups)
+	keys, sizes, err := listSizes(ctx, r.remoteStorage, config.RemoteBackups)
 	if err != nil {
-		return fmt.Errorf("failed to list R2 backups: %w", err)
+		return nil, fmt.Errorf("failed to list R2 backups: %w", err)
 	}
 
 	// Static bounds check
 	if len(keys) > config.MaxFiles {
-		return fmt.Errorf("too many backup files: %d exceeds limit %d", len(keys), config.MaxFiles)
+		return nil, fmt.Errorf("too many backup files: %d exceeds limit %d", len(keys), config.MaxFiles)
 	}
 
-	// Build set of valid URIs from manifest
-	validURIs := make(map[string]bool)
+	// Index manifest entries by URI
+	worlds := make(map[string]domain.World, len(manifest.Backups))
 	for _, world := range manifest.Backups {
-		validURIs[world.URI] = true
+		worlds[world.URI] = world
 	}
 
-	// Filter valid backup files (exclude manual.tar.gz and temp files)
+	// Filter valid backup files and chunk indexes (exclude manual.tar.gz and temp files)
 	var backups []string
 	for _, key := range keys {
-		if strings.HasSuffix(key, config.BackupExtension) {
+		if strings.HasSuffix(streamer.TrimCodecExtension(key), config.BackupExtension) || streamer.IsChunkIndex(key) {
 			// Skip manual world file and temp files
 			if strings.Contains(key, config.ManualWorldFilename) || strings.Contains(key, "temp_") {
 				continue
@@ -91,53 +111,176 @@ func (r *R2Retention) Apply(ctx context.Context, manifest *domain.Manifest) erro
 		return backups[i] > backups[j]
 	})
 
-	// Identify backups to delete:
-	// 1. Dangling backups (not in manifest)
-	// 2. Excess backups beyond retention limit
-	var toDelete []string
+	policy := manifest.GetRetentionPolicy(config.R2MaxBackups)
+	plan := &domain.RetentionPlan{Scope: r.scope(), Policy: policy.String()}
+	size := func(key string) int64 {
+		if stored, ok := sizes[key]; ok {
+			return stored
+		}
+		return worlds[key].Size
+	}
 
-	// First pass: identify dangling backups
+	// Dangling backups (not in manifest) are always deleted
 	var validBackups []string
 	for _, key := range backups {
-		if !validURIs[key] {
-			// Dangling backup - not in manifest
-			r.send(ports.UpdateEvent{Operation: "retention", Message: "Found dangling R2 backup", Data: map[string]any{"key": key}})
-			toDelete = append(toDelete, key)
-		} else {
-			validBackups = append(validBackups, key)
+		if _, ok := worlds[key]; !ok {
+			plan.Items = append(plan.Items, domain.RetentionItem{Key: key, Size: size(key), Delete: true, Reasons: []string{domain.RetentionDangling}})
+			continue
 		}
+		validBackups = append(validBackups, key)
 	}
 
-	// Second pass: apply retention limit to valid backups
-	if len(validBackups) > config.R2MaxBackups {
-		r.send(ports.UpdateEvent{Operation: "retention", Message: "Applying R2 retention policy", Data: map[string]any{
-			"total_valid": len(validBackups),
-			"max_allowed": config.R2MaxBackups,
-			"to_delete":   len(validBackups) - config.R2MaxBackups,
-		}})
-		toDelete = append(toDelete, validBackups[config.R2MaxBackups:]...)
+	// Pinned backups are kept outside the policy; the rest are kept by it or deleted
+	var policyBackups []string
+	var createdAt []time.Time
+	for _, key := range validBackups {
+		if worlds[key].Pinned {
+			plan.Items = append(plan.Items, domain.RetentionItem{Key: key, Size: size(key), Reasons: []string{domain.RetainPinned}})
+			continue
+		}
+		policyBackups = append(policyBackups, key)
+		createdAt = append(createdAt, worlds[key].CreatedAt)
+	}
+	for i, reasons := range policy.Keep(createdAt) {
+		item := domain.RetentionItem{Key: policyBackups[i], Size: size(policyBackups[i]), Reasons: reasons}
+		if len(reasons) == 0 {
+			item.Delete = true
+			item.Reasons = []string{domain.RetentionOverLimit}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	chunks, err := r.planChunks(ctx, retainedWorlds(manifest.Backups, plan.DeletedKeys()))
+	if err != nil {
+		return nil, err
 	}
+	plan.Items = append(plan.Items, chunks...)
 
-	// Delete identified backups
-	deletedSet := make(map[string]bool)
-	for _, key := range toDelete {
-		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting R2 backup", Data: map[string]any{"key": key}})
-		if err := r.remoteStorage.Delete(ctx, key); err != nil {
-			return fmt.Errorf("failed to delete R2 backup %s: %w", key, err)
+	return plan, nil
+}
+
+// Apply executes the retention plan
+// Deleted backups are removed from manifest's Backups before unreferenced chunks are deleted
+func (r *R2Retention) Apply(ctx context.Context, manifest *domain.Manifest) error {
+	plan, err := r.Plan(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	deletions := plan.Deletions()
+	if len(deletions) == 0 {
+		return nil
+	}
+
+	r.send(ports.UpdateEvent{Operation: "retention", Message: "Applying R2 retention policy", Data: map[string]any{
+		"scope":           plan.Scope,
+		"policy":          plan.Policy,
+		"to_delete":       len(deletions),
+		"reclaimed_bytes": plan.ReclaimedBytes(),
+	}})
+
+	// Delete backups first so the manifest never lists a backup whose chunks are gone
+	deleted := make(map[string]bool)
+	var chunks []domain.RetentionItem
+	for _, item := range deletions {
+		if strings.HasPrefix(item.Key, streamer.ChunkPrefix) {
+			chunks = append(chunks, item)
+			continue
+		}
+		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting R2 backup", Data: map[string]any{"key": item.Key, "reason": item.Reasons[0]}})
+		if err := r.remoteStorage.Delete(ctx, item.Key); err != nil {
+			return fmt.Errorf("failed to delete R2 backup %s: %w", item.Key, err)
 		}
-		deletedSet[key] = true
+		deleted[item.Key] = true
 	}
 
 	// Update manifest to remove deleted worlds
-	if len(deletedSet) > 0 {
-		var remainingWorlds []domain.World
-		for _, world := range manifest.Backups {
-			if !deletedSet[world.URI] {
-				remainingWorlds = append(remainingWorlds, world)
-			}
+	if len(deleted) > 0 {
+		manifest.Backups = retainedWorlds(manifest.Backups, deleted)
+	}
+
+	if len(chunks) > 0 {
+		r.send(ports.UpdateEvent{Operation: "retention", Message: "Deleting unreferenced R2 chunks", Data: map[string]any{"unreferenced": len(chunks)}})
+	}
+	for _, item := range chunks {
+		if err := r.remoteStorage.Delete(ctx, item.Key); err != nil {
+			return fmt.Errorf("failed to delete R2 chunk %s: %w", item.Key, err)
 		}
-		manifest.Backups = remainingWorlds
 	}
 
 	return nil
 }
+
+// scope names the storage this retention prunes in plans
+func (r *R2Retention) scope() string {
+	if r.target == config.PrimaryReplica {
+		return "remote"
+	}
+	return "replica " + r.target
+}
+
+// retainedWorlds returns the worlds whose URI is not deleted
+func retainedWorlds(worlds []domain.World, deleted map[string]bool) []domain.World {
+	var retained []domain.World
+	for _, world := range worlds {
+		if !deleted[world.URI] {
+			retained = append(retained, world)
+		}
+	}
+	return retained
+}
+
+// planChunks lists stored chunks that no retained backup or published instance references
+// Reference counts come from the indexes of the retained chunked backups and the instance index;
+// an unreadable index aborts planning so chunks are never deleted on incomplete information
+func (r *R2Retention) planChunks(ctx context.Context, retained []domain.World) ([]domain.RetentionItem, error) {
+	chunks, sizes, err := listSizes(ctx, r.remoteStorage, streamer.ChunkPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list R2 chunks: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	if len(chunks) > config.MaxChunks {
+		return nil, fmt.Errorf("too many chunks: %d exceeds limit %d", len(chunks), config.MaxChunks)
+	}
+
+	refs := make(map[string]int)
+	count := func(indexKey string, optional bool) error {
+		data, err := r.remoteStorage.Get(ctx, indexKey)
+		if optional && errors.Is(err, ports.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read chunk index %s: %w", indexKey, err)
+		}
+		index, err := streamer.ParseChunkIndex(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse chunk index %s: %w", indexKey, err)
+		}
+		for _, key := range index.ChunkKeys() {
+			refs[key]++
+		}
+		return nil
+	}
+
+	for _, world := range retained {
+		if !streamer.IsChunkIndex(world.URI) || !world.HeldBy(r.target) {
+			continue
+		}
+		if err := count(world.URI, false); err != nil {
+			return nil, err
+		}
+	}
+	// The published instance shares the chunk store
+	if err := count(config.InstanceIndexKey, true); err != nil {
+		return nil, err
+	}
+
+	var unreferenced []domain.RetentionItem
+	for _, key := range chunks {
+		if refs[key] == 0 {
+			unreferenced = append(unreferenced, domain.RetentionItem{Key: key, Size: sizes[key], Delete: true, Reasons: []string{domain.RetentionUnreferenced}})
+		}
+	}
+	return unreferenced, nil
+}
@@ -2,11 +2,14 @@ package services_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"ritual/internal/adapters"
+	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
 	"ritual/internal/core/domain"
+	"ritual/internal/core/ports"
 	"ritual/internal/core/services"
 	"strings"
 	"testing"
@@ -93,3 +96,270 @@ func TestR2Retention_ManifestShouldNotAccumulateWorlds(t *testing.T) {
 		"BUG: Manifest should have only %d worlds after retention, but has %d",
 		config.R2MaxBackups, len(manifest.Backups))
 }
+
+func TestR2Retention_CompressedBackups(t *testing.T) {
+	tempDir := t.TempDir()
+	tempRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	defer tempRoot.Close()
+
+	remoteStorage, err := adapters.NewFSRepository(tempRoot)
+	require.NoError(t, err)
+	defer remoteStorage.Close()
+
+	ctx := context.Background()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, config.RemoteBackups), 0755))
+
+	// Older plain tar backups and newer gzip backups share one retention budget
+	var worlds []domain.World
+	for i, ext := range []string{".tar.gz", ".tar.gz", ".tar", ".tar"} {
+		createdAt := time.Now().Add(time.Duration(-i) * time.Hour)
+		filename := createdAt.Format(config.TimestampFormat) + ext
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, config.RemoteBackups, filename), []byte("backup data"), 0644))
+		worlds = append(worlds, domain.World{URI: config.RemoteBackups + "/" + filename, CreatedAt: createdAt})
+	}
+	manifest := &domain.Manifest{Backups: worlds}
+
+	retention, err := services.NewR2Retention(remoteStorage, nil)
+	require.NoError(t, err)
+	require.NoError(t, retention.Apply(ctx, manifest))
+
+	remaining, err := remoteStorage.List(ctx, config.RemoteBackups)
+	require.NoError(t, err)
+	assert.Len(t, remaining, config.R2MaxBackups)
+	for _, key := range remaining {
+		assert.True(t, strings.HasSuffix(key, ".tar.gz"), "newest gzip backups should be kept: %s", key)
+	}
+	assert.Len(t, manifest.Backups, config.R2MaxBackups)
+}
+
+func TestR2Retention_CollectsUnreferencedChunks(t *testing.T) {
+	tempDir := t.TempDir()
+	tempRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	defer tempRoot.Close()
+
+	remoteStorage, err := adapters.NewFSRepository(tempRoot)
+	require.NoError(t, err)
+	defer remoteStorage.Close()
+
+	ctx := context.Background()
+	uploader := &mockStreamUploader{storage: remoteStorage}
+	worldDir := filepath.Join(t.TempDir(), "world")
+	require.NoError(t, os.MkdirAll(worldDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "level.dat"), []byte("shared level data"), 0644))
+
+	// Each backup adds one region file; level.dat is shared by all of them
+	var worlds []domain.World
+	for i := 0; i < config.R2MaxBackups+1; i++ {
+		region := filepath.Join(worldDir, fmt.Sprintf("r.%d.0.mca", i))
+		require.NoError(t, os.WriteFile(region, []byte(fmt.Sprintf("region %d", i)), 0644))
+
+		createdAt := time.Now().Add(time.Duration(i-config.R2MaxBackups) * time.Hour)
+		key := config.RemoteBackups + "/" + createdAt.Format(config.TimestampFormat) + streamer.IndexExtension
+		_, err := streamer.PushChunks(ctx, streamer.PushConfig{Bucket: "b", Key: key, Dirs: []string{worldDir}}, uploader, remoteStorage)
+		require.NoError(t, err)
+		worlds = append(worlds, domain.World{URI: key, CreatedAt: createdAt})
+
+		// The oldest backup's region file is gone from later snapshots
+		if i == 0 {
+			require.NoError(t, os.Remove(region))
+		}
+	}
+
+	// A chunk left by an interrupted backup is not referenced by anything
+	require.NoError(t, remoteStorage.Put(ctx, streamer.ChunkPrefix+"orphan", []byte("orphan")))
+
+	manifest := &domain.Manifest{Backups: worlds}
+	retention, err := services.NewR2Retention(remoteStorage, nil)
+	require.NoError(t, err)
+	require.NoError(t, retention.Apply(ctx, manifest))
+	require.Len(t, manifest.Backups, config.R2MaxBackups)
+
+	// Only chunks of retained indexes remain
+	expected := make(map[string]bool)
+	for _, world := range manifest.Backups {
+		data, err := remoteStorage.Get(ctx, world.URI)
+		require.NoError(t, err)
+		index, err := streamer.ParseChunkIndex(data)
+		require.NoError(t, err)
+		for _, key := range index.ChunkKeys() {
+			expected[key] = true
+		}
+	}
+	remaining, err := remoteStorage.List(ctx, streamer.ChunkPrefix)
+	require.NoError(t, err)
+	assert.Len(t, remaining, len(expected))
+	for _, key := range remaining {
+		assert.True(t, expected[key], "unreferenced chunk kept: %s", key)
+	}
+
+	// Retained backups still rebuild completely
+	for _, world := range manifest.Backups {
+		destDir := t.TempDir()
+		require.NoError(t, streamer.Pull(ctx, streamer.PullConfig{Bucket: "b", Key: world.URI, Dest: destDir}, uploader))
+		level, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "shared level data", string(level))
+	}
+}
+
+func TestReplicaRetention_SkipsBackupsTheTargetLacks(t *testing.T) {
+	ctx := context.Background()
+	replica := newReplicaStorage(t)
+	worldDir := filepath.Join(t.TempDir(), "world")
+	require.NoError(t, os.MkdirAll(worldDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(worldDir, "level.dat"), []byte("level data"), 0644))
+
+	// The replica received the older backup; replication of the newer one failed
+	held := config.RemoteBackups + "/20240101000000" + streamer.IndexExtension
+	_, err := streamer.PushChunks(ctx, streamer.PushConfig{Bucket: "b", Key: held, Dirs: []string{worldDir}}, replica, replica)
+	require.NoError(t, err)
+	manifest := &domain.Manifest{Backups: []domain.World{
+		{URI: held, CreatedAt: time.Now().Add(-time.Hour), Replicas: []string{config.PrimaryReplica, "nas"}},
+		{URI: config.RemoteBackups + "/20240102000000" + streamer.IndexExtension, CreatedAt: time.Now(), Replicas: []string{config.PrimaryReplica}},
+	}}
+
+	_, err = services.NewReplicaRetention("", replica, nil)
+	assert.ErrorIs(t, err, services.ErrReplicaTargetNameEmpty)
+
+	retention, err := services.NewReplicaRetention("nas", replica, nil)
+	require.NoError(t, err)
+	require.NoError(t, retention.Apply(ctx, manifest))
+	assert.Len(t, manifest.Backups, 2)
+
+	// Chunks of the held backup survive collection
+	destDir := t.TempDir()
+	require.NoError(t, streamer.Pull(ctx, streamer.PullConfig{Bucket: "b", Key: held, Dest: destDir}, replica))
+	level, err := os.ReadFile(filepath.Join(destDir, "world", "level.dat"))
+	require.NoError(t, err)
+	assert.Equal(t, "level data", string(level))
+
+	// The primary remote requires every chunked backup's index
+	primary, err := services.NewR2Retention(replica, nil)
+	require.NoError(t, err)
+	assert.Error(t, primary.Apply(ctx, manifest))
+}
+
+func TestR2Retention_ManifestPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	tempRoot, err := os.OpenRoot(tempDir)
+	require.NoError(t, err)
+	defer tempRoot.Close()
+
+	remoteStorage, err := adapters.NewFSRepository(tempRoot)
+	require.NoError(t, err)
+	defer remoteStorage.Close()
+
+	ctx := context.Background()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, config.RemoteBackups), 0755))
+
+	// Three sessions on one day, then one per day for the previous four days
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	var worlds []domain.World
+	for _, createdAt := range []time.Time{
+		now, now.Add(-time.Minute), now.Add(-2 * time.Minute),
+		now.AddDate(0, 0, -1), now.AddDate(0, 0, -2), now.AddDate(0, 0, -3), now.AddDate(0, 0, -4),
+	} {
+		filename := createdAt.Format(config.TimestampFormat) + config.BackupExtension
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, config.RemoteBackups, filename), []byte("backup data"), 0644))
+		worlds = append(worlds, domain.World{URI: config.RemoteBackups + "/" + filename, CreatedAt: createdAt})
+	}
+	manifest := &domain.Manifest{Backups: worlds, Retention: &domain.RetentionPolicy{Latest: 2, Daily: 3}}
+
+	retention, err := services.NewR2Retention(remoteStorage, nil)
+	require.NoError(t, err)
+	require.NoError(t, retention.Apply(ctx, manifest))
+
+	// Two latest plus the newest of the two previous days
+	expected := []domain.World{worlds[0], worlds[1], worlds[3], worlds[4]}
+	assert.ElementsMatch(t, expected, manifest.Backups)
+	remaining, err := remoteStorage.List(ctx, config.RemoteBackups)
+	require.NoError(t, err)
+	assert.Len(t, remaining, len(expected))
+}
+
+func TestR2Retention_Plan(t *testing.T) {
+	ctx := context.Background()
+	remoteStorage := newReplicaStorage(t)
+
+	// Three backups in the manifest, one stored backup the manifest lost, one orphaned chunk
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	var worlds []domain.World
+	for i := 0; i < 3; i++ {
+		createdAt := now.Add(time.Duration(-i) * time.Hour)
+		key := config.RemoteBackups + "/" + createdAt.Format(config.TimestampFormat) + config.BackupExtension
+		require.NoError(t, remoteStorage.Put(ctx, key, []byte(strings.Repeat("w", 10*(i+1)))))
+		worlds = append(worlds, domain.World{URI: key, CreatedAt: createdAt})
+	}
+	dangling := config.RemoteBackups + "/20200101000000" + config.BackupExtension
+	require.NoError(t, remoteStorage.Put(ctx, dangling, []byte("dangling")))
+	orphan := streamer.ChunkPrefix + "orphan"
+	require.NoError(t, remoteStorage.Put(ctx, orphan, []byte("orphan")))
+	manifest := &domain.Manifest{Backups: worlds, Retention: &domain.RetentionPolicy{Latest: 2}}
+
+	retention, err := services.NewR2Retention(remoteStorage, nil)
+	require.NoError(t, err)
+
+	plan, err := retention.Plan(ctx, manifest)
+	require.NoError(t, err)
+	assert.Equal(t, "remote", plan.Scope)
+
+	reasons := make(map[string][]string)
+	for _, item := range plan.Deletions() {
+		reasons[item.Key] = item.Reasons
+	}
+	assert.Equal(t, map[string][]string{
+		dangling:      {domain.RetentionDangling},
+		worlds[2].URI: {domain.RetentionOverLimit},
+		orphan:        {domain.RetentionUnreferenced},
+	}, reasons)
+	assert.Equal(t, int64(len("dangling")+30+len("orphan")), plan.ReclaimedBytes())
+
+	// Planning changes nothing
+	assert.Len(t, manifest.Backups, 3)
+	stored, err := remoteStorage.List(ctx, config.RemoteBackups)
+	require.NoError(t, err)
+	assert.Len(t, stored, 4)
+
+	// Apply deletes exactly the planned keys
+	require.NoError(t, retention.Apply(ctx, manifest))
+	assert.Equal(t, worlds[:2], manifest.Backups)
+	for key := range reasons {
+		_, err := remoteStorage.Get(ctx, key)
+		assert.ErrorIs(t, err, ports.ErrNotFound, key)
+	}
+}
+
+func TestR2Retention_KeepsPinnedBackups(t *testing.T) {
+	ctx := context.Background()
+	remoteStorage := newReplicaStorage(t)
+
+	// The oldest backup is pinned; four newer ones compete for the limit
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	var worlds []domain.World
+	for i := 0; i < 5; i++ {
+		createdAt := now.AddDate(0, 0, -i)
+		key := config.RemoteBackups + "/" + createdAt.Format(config.TimestampFormat) + config.BackupExtension
+		require.NoError(t, remoteStorage.Put(ctx, key, []byte("backup data")))
+		worlds = append(worlds, domain.World{URI: key, CreatedAt: createdAt, Pinned: i == 4})
+	}
+	manifest := &domain.Manifest{Backups: worlds, Retention: &domain.RetentionPolicy{Latest: 2}}
+
+	retention, err := services.NewR2Retention(remoteStorage, nil)
+	require.NoError(t, err)
+	plan, err := retention.Plan(ctx, manifest)
+	require.NoError(t, err)
+	for _, item := range plan.Items {
+		if item.Key == worlds[4].URI {
+			assert.Equal(t, []string{domain.RetainPinned}, item.Reasons)
+			assert.False(t, item.Delete)
+		}
+	}
+
+	// The pinned backup does not use up one of the two latest slots
+	require.NoError(t, retention.Apply(ctx, manifest))
+	assert.Equal(t, []domain.World{worlds[0], worlds[1], worlds[4]}, manifest.Backups)
+	_, err = remoteStorage.Get(ctx, worlds[4].URI)
+	assert.NoError(t, err)
+}
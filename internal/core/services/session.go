@@ -6,27 +6,73 @@ import (
 	"path/filepath"
 	"regexp"
 	"ritual/internal/config"
+	"slices"
 
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
 // PlayerJoinPattern matches Minecraft server log entries for player joins
-// Matches: "PlayerName joined the game"
-var PlayerJoinPattern = regexp.MustCompile(`\S+ joined the game`)
+// Matches: "PlayerName joined the game"; the first group is the player name
+var PlayerJoinPattern = regexp.MustCompile(`(\S+) joined the game`)
+
+// ServerVersionPattern matches the Minecraft version the server logs at startup
+// Matches: "Starting minecraft server version 1.20.1"; the first group is the version
+var ServerVersionPattern = regexp.MustCompile(`Starting minecraft server version (\S+)`)
+
+// SessionLog holds what the server log tells about the last session
+type SessionLog struct {
+	Players          []string // Players who joined, in order of first join
+	MinecraftVersion string   // Empty if the server did not log its version
+}
 
 // CheckPlayersJoined parses the server log file and returns true if any player joined
 // Returns false if log file doesn't exist (no server run = no players)
 func CheckPlayersJoined(workRoot *os.Root) (bool, error) {
+	joined := false
+	err := scanServerLog(workRoot, func(line string) bool {
+		joined = PlayerJoinPattern.MatchString(line)
+		return !joined
+	})
+	if err != nil {
+		return false, err
+	}
+	return joined, nil
+}
+
+// ReadSessionLog parses the server log file for the players who joined and the server version
+// Returns an empty SessionLog if log file doesn't exist
+func ReadSessionLog(workRoot *os.Root) (SessionLog, error) {
+	var session SessionLog
+	err := scanServerLog(workRoot, func(line string) bool {
+		if match := PlayerJoinPattern.FindStringSubmatch(line); match != nil && !slices.Contains(session.Players, match[1]) {
+			session.Players = append(session.Players, match[1])
+		}
+		if session.MinecraftVersion == "" {
+			if match := ServerVersionPattern.FindStringSubmatch(line); match != nil {
+				session.MinecraftVersion = match[1]
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return SessionLog{}, err
+	}
+	return session, nil
+}
+
+// scanServerLog calls visit for each line of the server log until visit returns false
+// A missing log file has no lines
+func scanServerLog(workRoot *os.Root, visit func(line string) bool) error {
 	logPath := filepath.Join(config.LogsDir, config.ServerLogFilename)
 
 	file, err := workRoot.Open(logPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			// No log file means no server ran, so no players joined
-			return false, nil
+			// No log file means no server ran
+			return nil
 		}
-		return false, err
+		return err
 	}
 	defer file.Close()
 
@@ -36,14 +82,10 @@ func CheckPlayersJoined(workRoot *os.Root) (bool, error) {
 
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		if PlayerJoinPattern.MatchString(scanner.Text()) {
-			return true, nil
+		if !visit(scanner.Text()) {
+			return nil
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return false, err
-	}
-
-	return false, nil
+	return scanner.Err()
 }
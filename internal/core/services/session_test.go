@@ -157,3 +157,42 @@ func TestCheckPlayersJoined(t *testing.T) {
 		assert.True(t, joined)
 	})
 }
+
+func TestReadSessionLog(t *testing.T) {
+	logContent := `[21Dec2025 20:42:48.100] [Server thread/INFO] [net.minecraft.server.dedicated.DedicatedServer/]: Starting minecraft server version 1.20.1
+[21Dec2025 20:42:48.251] [Server thread/INFO] [net.minecraft.server.dedicated.DedicatedServer/]: Starting Minecraft server on 0.0.0.0:25565
+[21Dec2025 20:43:43.001] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: owl joined the game
+[21Dec2025 20:43:50.001] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: fox joined the game
+[21Dec2025 20:44:38.959] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: owl left the game
+[21Dec2025 20:45:01.001] [Server thread/INFO] [net.minecraft.server.MinecraftServer/]: owl joined the game
+`
+
+	t.Run("UTF-16 LE: reads players and version", func(t *testing.T) {
+		tempDir := t.TempDir()
+		workRoot, err := os.OpenRoot(tempDir)
+		require.NoError(t, err)
+		defer workRoot.Close()
+		require.NoError(t, workRoot.Mkdir(config.LogsDir, 0755))
+
+		logFile, err := os.Create(filepath.Join(tempDir, config.LogsDir, config.ServerLogFilename))
+		require.NoError(t, err)
+		require.NoError(t, writeUTF16LE(logFile, logContent))
+		logFile.Close()
+
+		session, err := services.ReadSessionLog(workRoot)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"owl", "fox"}, session.Players, "players are listed once in order of first join")
+		assert.Equal(t, "1.20.1", session.MinecraftVersion)
+	})
+
+	t.Run("returns empty session when log file missing", func(t *testing.T) {
+		workRoot, err := os.OpenRoot(t.TempDir())
+		require.NoError(t, err)
+		defer workRoot.Close()
+
+		session, err := services.ReadSessionLog(workRoot)
+		require.NoError(t, err)
+		assert.Empty(t, session.Players)
+		assert.Empty(t, session.MinecraftVersion)
+	})
+}
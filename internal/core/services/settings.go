@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"ritual/internal/core/domain"
 	"ritual/internal/core/ports"
@@ -108,6 +109,25 @@ func promptWithValidation(events chan<- ports.Event, prompt, defaultValue string
 	}
 }
 
+// promptConfirm asks a yes/no question defaulting to no
+// Returns true only for an explicit "y" or "yes"
+func promptConfirm(events chan<- ports.Event, id, prompt string) bool {
+	responseChan := make(chan any, 1)
+	ports.SendEvent(events, ports.PromptEvent{
+		ID:           id,
+		Prompt:       prompt + " [y/N]",
+		DefaultValue: "n",
+		ResponseChan: responseChan,
+	})
+
+	response, ok := (<-responseChan).(string)
+	if !ok {
+		return false
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
 func validateIP(input string) error {
 	if input == "" {
 		return fmt.Errorf("IP cannot be empty")
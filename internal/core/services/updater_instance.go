@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"ritual/internal/adapters/streamer"
 	"ritual/internal/config"
@@ -23,13 +24,15 @@ var (
 )
 
 // InstanceUpdater implements UpdaterService for instance updates
-// InstanceUpdater handles downloading and extracting instance.tar.gz from remote storage
+// InstanceUpdater handles downloading and extracting instance.tar.gz from remote storage,
+// or applying a published instance file index as a delta
 type InstanceUpdater struct {
 	librarian  ports.LibrarianService
 	validator  ports.ValidatorService
 	downloader streamer.S3StreamDownloader
 	bucket     string
 	workRoot   *os.Root
+	keys       *streamer.Keyring // Optional: decrypts an encrypted instance archive
 }
 
 // Compile-time check to ensure InstanceUpdater implements ports.UpdaterService
@@ -43,6 +46,7 @@ func NewInstanceUpdater(
 	downloader streamer.S3StreamDownloader,
 	bucket string,
 	workRoot *os.Root,
+	keys *streamer.Keyring,
 ) (*InstanceUpdater, error) {
 	if librarian == nil {
 		return nil, ErrInstanceUpdaterLibrarianNil
@@ -63,6 +67,7 @@ func NewInstanceUpdater(
 		downloader: downloader,
 		bucket:     bucket,
 		workRoot:   workRoot,
+		keys:       keys,
 	}
 
 	// Postcondition assertion
@@ -100,7 +105,8 @@ func (u *InstanceUpdater) Run(ctx context.Context) error {
 
 	// Check if instance update is needed
 	if err := u.validator.CheckInstance(localManifest, remoteManifest); err != nil {
-		if err.Error() == "outdated instance" {
+		// A local manifest without an instance version was written by a restore on a host with no instance record
+		if errors.Is(err, ErrOutdatedInstance) || errors.Is(err, ErrLocalInstanceVersionEmpty) {
 			// Instance needs update
 			if updateErr := u.updateInstance(ctx, remoteManifest); updateErr != nil {
 				return fmt.Errorf("failed to update instance: %w", updateErr)
@@ -156,7 +162,7 @@ func (u *InstanceUpdater) initializeInstance(ctx context.Context, remoteManifest
 	}
 
 	// Download and extract instance
-	if err := u.downloadAndExtractInstance(ctx); err != nil {
+	if err := u.downloadAndExtractInstance(ctx, remoteManifest); err != nil {
 		return err
 	}
 
@@ -165,6 +171,8 @@ func (u *InstanceUpdater) initializeInstance(ctx context.Context, remoteManifest
 	localManifest := &domain.Manifest{
 		RitualVersion:   remoteManifest.RitualVersion,
 		InstanceVersion: remoteManifest.InstanceVersion,
+		InstanceChecksum: remoteManifest.InstanceChecksum,
+		InstanceIndex:    remoteManifest.InstanceIndex,
 		Backups:    []domain.World{}, // Empty - WorldsUpdater handles this
 		UpdatedAt:       remoteManifest.UpdatedAt,
 	}
@@ -187,7 +195,7 @@ func (u *InstanceUpdater) updateInstance(ctx context.Context, remoteManifest *do
 	}
 
 	// Download and extract instance
-	if err := u.downloadAndExtractInstance(ctx); err != nil {
+	if err := u.downloadAndExtractInstance(ctx, remoteManifest); err != nil {
 		return err
 	}
 
@@ -200,10 +208,18 @@ func (u *InstanceUpdater) updateInstance(ctx context.Context, remoteManifest *do
 }
 
 // downloadAndExtractInstance downloads instance.tar.gz from remote and extracts it
-func (u *InstanceUpdater) downloadAndExtractInstance(ctx context.Context) error {
+// A published file index is applied as a delta instead
+// A non-empty checksum is verified before anything is extracted
+func (u *InstanceUpdater) downloadAndExtractInstance(ctx context.Context, manifest *domain.Manifest) error {
 	if ctx == nil {
 		return errors.New("context cannot be nil")
 	}
+	if manifest == nil {
+		return errors.New("manifest cannot be nil")
+	}
+	if manifest.InstanceIndex != "" {
+		return u.syncInstance(ctx, manifest)
+	}
 
 	// Destination directory
 	destPath := filepath.Join(u.workRoot.Name(), config.InstanceDir)
@@ -214,6 +230,8 @@ func (u *InstanceUpdater) downloadAndExtractInstance(ctx context.Context) error
 		Key:      config.InstanceArchiveKey,
 		Dest:     destPath,
 		Conflict: streamer.Replace,
+		Checksum: manifest.InstanceChecksum,
+		Keys:     u.keys,
 	}, u.downloader)
 	if err != nil {
 		return fmt.Errorf("failed to download and extract instance: %w", err)
@@ -221,3 +239,49 @@ func (u *InstanceUpdater) downloadAndExtractInstance(ctx context.Context) error
 
 	return nil
 }
+
+// syncInstance applies the published file index to the instance directory
+// Only changed files are downloaded; files no longer published are deleted unless protected
+func (u *InstanceUpdater) syncInstance(ctx context.Context, manifest *domain.Manifest) error {
+	_, err := streamer.Sync(ctx, streamer.SyncConfig{
+		Bucket:    u.bucket,
+		Key:       manifest.InstanceIndex,
+		Dest:      u.workRoot.Name(),
+		Checksum:  manifest.InstanceChecksum,
+		Keys:      u.keys,
+		Protected: instanceProtection(manifest),
+	}, u.downloader)
+	if err != nil {
+		return fmt.Errorf("failed to sync instance: %w", err)
+	}
+
+	return nil
+}
+
+// instanceProtection reports the index paths that instance publishing and delta updates leave alone:
+// anything outside the instance directory, the manifest's world directories and protected paths,
+// and the runtime state in config.InstanceProtectedPaths
+func instanceProtection(manifest *domain.Manifest) func(indexPath string) bool {
+	prefix := config.InstanceDir + "/"
+
+	var protected []string
+	for _, paths := range [][]string{config.InstanceProtectedPaths, manifest.WorldDirs, manifest.ProtectedPaths} {
+		for _, p := range paths {
+			if cleaned := path.Clean(filepath.ToSlash(p)); cleaned != "." && cleaned != "/" {
+				protected = append(protected, prefix+strings.TrimPrefix(cleaned, "/"))
+			}
+		}
+	}
+
+	return func(indexPath string) bool {
+		if !strings.HasPrefix(indexPath, prefix) {
+			return indexPath != config.InstanceDir
+		}
+		for _, p := range protected {
+			if indexPath == p || strings.HasPrefix(indexPath, p+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -191,6 +193,7 @@ func TestInstanceUpdater_Run(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -239,6 +242,7 @@ func TestInstanceUpdater_Run(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -261,6 +265,29 @@ func TestInstanceUpdater_Run(t *testing.T) {
 		assert.Equal(t, "1.20.2", manifestObj.InstanceVersion)
 	})
 
+	t.Run("local manifest without instance version - updates instance", func(t *testing.T) {
+		localStorage, remoteStorage, librarian, validator, downloader, _, remoteTempDir, workRoot, cleanup := setupInstanceUpdaterServices(t)
+		defer cleanup()
+
+		setupInstanceRemoteManifest(t, remoteStorage, "1.0.0", "1.20.2")
+		setupInstanceRemoteTar(t, downloader, remoteTempDir)
+
+		// A restore on a host without a local manifest records the backups but no instance
+		ctx := context.Background()
+		restoredManifest := createInstanceTestManifest("1.0.0", "", []domain.World{})
+		manifestData, err := json.Marshal(restoredManifest)
+		require.NoError(t, err)
+		require.NoError(t, localStorage.Put(ctx, "manifest.json", manifestData))
+
+		updater, err := services.NewInstanceUpdater(librarian, validator, downloader, "test-bucket", workRoot, nil)
+		require.NoError(t, err)
+		require.NoError(t, updater.Run(ctx))
+
+		localManifest, err := librarian.GetLocalManifest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "1.20.2", localManifest.InstanceVersion)
+	})
+
 	t.Run("up-to-date instance - no download", func(t *testing.T) {
 		localStorage, remoteStorage, librarian, validator, downloader, _, _, workRoot, cleanup := setupInstanceUpdaterServices(t)
 		defer cleanup()
@@ -283,6 +310,7 @@ func TestInstanceUpdater_Run(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -310,6 +338,7 @@ func TestInstanceUpdater_Run(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -330,6 +359,7 @@ func TestNewInstanceUpdater(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "librarian")
@@ -345,6 +375,7 @@ func TestNewInstanceUpdater(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "validator")
@@ -360,6 +391,7 @@ func TestNewInstanceUpdater(t *testing.T) {
 			nil, // downloader
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "downloader")
@@ -375,6 +407,7 @@ func TestNewInstanceUpdater(t *testing.T) {
 			downloader,
 			"test-bucket",
 			nil, // workRoot
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "workRoot")
@@ -390,6 +423,7 @@ func TestNewInstanceUpdater(t *testing.T) {
 			downloader,
 			"test-bucket",
 			workRoot,
+			nil,
 		)
 		assert.NoError(t, err)
 		assert.NotNil(t, updater)
@@ -398,3 +432,41 @@ func TestNewInstanceUpdater(t *testing.T) {
 		var _ streamer.S3StreamDownloader = downloader
 	})
 }
+
+func TestInstanceUpdater_VerifiesChecksum(t *testing.T) {
+	for name, corrupt := range map[string]bool{"matching archive is extracted": false, "mismatched archive is rejected": true} {
+		t.Run(name, func(t *testing.T) {
+			localStorage, remoteStorage, librarian, validator, downloader, tempDir, remoteTempDir, workRoot, cleanup := setupInstanceUpdaterServices(t)
+			defer cleanup()
+			ctx := context.Background()
+
+			setupInstanceRemoteTar(t, downloader, remoteTempDir)
+			remoteManifest := createInstanceTestManifest("1.0.0", "1.20.1", []domain.World{})
+			remoteManifest.InstanceChecksum = fmt.Sprintf("%x", sha256.Sum256(downloader.data[config.InstanceArchiveKey]))
+			if corrupt {
+				remoteManifest.InstanceChecksum = fmt.Sprintf("%x", sha256.Sum256([]byte("other")))
+			}
+			data, err := json.Marshal(remoteManifest)
+			require.NoError(t, err)
+			require.NoError(t, remoteStorage.Put(ctx, "manifest.json", data))
+
+			updater, err := services.NewInstanceUpdater(librarian, validator, downloader, "test-bucket", workRoot, nil)
+			require.NoError(t, err)
+
+			err = updater.Run(ctx)
+			if corrupt {
+				assert.ErrorIs(t, err, streamer.ErrChecksumMismatch)
+				_, err = localStorage.Get(ctx, "manifest.json")
+				assert.Error(t, err, "local manifest should not be created from a rejected archive")
+				return
+			}
+			require.NoError(t, err)
+			_, err = os.Stat(filepath.Join(tempDir, config.InstanceDir))
+			assert.NoError(t, err)
+
+			local, err := librarian.GetLocalManifest(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, remoteManifest.InstanceChecksum, local.InstanceChecksum)
+		})
+	}
+}
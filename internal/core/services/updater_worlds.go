@@ -30,6 +30,7 @@ type WorldsUpdater struct {
 	downloader streamer.S3StreamDownloader
 	bucket     string
 	workRoot   *os.Root
+	keys       *streamer.Keyring // Optional: decrypts encrypted archives
 	events     chan<- ports.Event
 }
 
@@ -44,6 +45,7 @@ func NewWorldsUpdater(
 	downloader streamer.S3StreamDownloader,
 	bucket string,
 	workRoot *os.Root,
+	keys *streamer.Keyring,
 	events chan<- ports.Event,
 ) (*WorldsUpdater, error) {
 	if librarian == nil {
@@ -65,6 +67,7 @@ func NewWorldsUpdater(
 		downloader: downloader,
 		bucket:     bucket,
 		workRoot:   workRoot,
+		keys:       keys,
 		events:     events,
 	}
 
@@ -141,14 +144,14 @@ func (u *WorldsUpdater) updateWorlds(ctx context.Context, remoteManifest *domain
 	}
 
 	// Sanitize world URI
-	sanitizedURI, valid := u.sanitizeWorldURI(latestWorld.URI)
+	sanitizedURI, valid := sanitizeWorldURI(latestWorld.URI)
 	if !valid {
 		u.send(ports.UpdateEvent{Operation: "worlds", Message: "Invalid world URI, skipping world update", Data: map[string]any{"uri": latestWorld.URI}})
 		return nil
 	}
 
 	// Download and extract world archive using streamer.Pull
-	if err := u.downloadAndExtractWorld(ctx, sanitizedURI); err != nil {
+	if err := u.downloadAndExtractWorld(ctx, sanitizedURI, latestWorld); err != nil {
 		return err
 	}
 
@@ -161,7 +164,7 @@ func (u *WorldsUpdater) updateWorlds(ctx context.Context, remoteManifest *domain
 }
 
 // sanitizeWorldURI validates and sanitizes the world URI
-func (u *WorldsUpdater) sanitizeWorldURI(uri string) (string, bool) {
+func sanitizeWorldURI(uri string) (string, bool) {
 	sanitizedURI := filepath.ToSlash(filepath.Clean(uri))
 	// Allow manual.tar.gz without worlds/ prefix as special case
 	if sanitizedURI == config.ManualWorldFilename {
@@ -174,13 +177,17 @@ func (u *WorldsUpdater) sanitizeWorldURI(uri string) (string, bool) {
 }
 
 // downloadAndExtractWorld downloads and extracts the world archive from remote storage
-func (u *WorldsUpdater) downloadAndExtractWorld(ctx context.Context, key string) error {
+// The archive is verified against the world's recorded size and checksum when present
+func (u *WorldsUpdater) downloadAndExtractWorld(ctx context.Context, key string, world *domain.World) error {
 	if ctx == nil {
 		return errors.New("context cannot be nil")
 	}
 	if key == "" {
 		return errors.New("key cannot be empty")
 	}
+	if world == nil {
+		return errors.New("world cannot be nil")
+	}
 
 	// Destination is the instance directory
 	destPath := filepath.Join(u.workRoot.Name(), config.InstanceDir)
@@ -188,12 +195,16 @@ func (u *WorldsUpdater) downloadAndExtractWorld(ctx context.Context, key string)
 	u.send(ports.StartEvent{Operation: "download"})
 	u.send(ports.UpdateEvent{Operation: "download", Message: "Downloading world archive", Data: map[string]any{"key": key}})
 
-	// Use streamer.Pull to download and extract
+	// Extract into staging first so a failed download leaves the current world in place
 	err := streamer.Pull(ctx, streamer.PullConfig{
 		Bucket:   u.bucket,
 		Key:      key,
 		Dest:     destPath,
 		Conflict: streamer.Replace,
+		Staged:   true,
+		Size:     world.Size,
+		Checksum: world.Checksum,
+		Keys:     u.keys,
 	}, u.downloader)
 	if err != nil {
 		u.send(ports.ErrorEvent{Operation: "download", Err: err})
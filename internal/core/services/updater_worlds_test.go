@@ -4,7 +4,9 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -219,6 +221,7 @@ func TestWorldsUpdater_Run(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -272,6 +275,7 @@ func TestWorldsUpdater_Run(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -339,6 +343,7 @@ func TestWorldsUpdater_Run(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -383,6 +388,7 @@ func TestWorldsUpdater_Run(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -402,6 +408,7 @@ func TestWorldsUpdater_Run(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -423,6 +430,7 @@ func TestNewWorldsUpdater(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "librarian")
@@ -439,6 +447,7 @@ func TestNewWorldsUpdater(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "validator")
@@ -455,6 +464,7 @@ func TestNewWorldsUpdater(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "downloader")
@@ -471,6 +481,7 @@ func TestNewWorldsUpdater(t *testing.T) {
 			"test-bucket",
 			nil, // workRoot
 			nil,
+			nil,
 		)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "workRoot")
@@ -487,6 +498,7 @@ func TestNewWorldsUpdater(t *testing.T) {
 			"test-bucket",
 			workRoot,
 			nil,
+			nil,
 		)
 		assert.NoError(t, err)
 		assert.NotNil(t, updater)
@@ -495,3 +507,92 @@ func TestNewWorldsUpdater(t *testing.T) {
 		var _ streamer.S3StreamDownloader = downloader
 	})
 }
+
+func TestWorldsUpdater_VerifiesChecksum(t *testing.T) {
+	for name, corrupt := range map[string]bool{"matching archive is extracted": false, "mismatched archive is rejected": true} {
+		t.Run(name, func(t *testing.T) {
+			localStorage, remoteStorage, librarian, validator, downloader, tempDir, remoteTempDir, workRoot, cleanup := setupWorldsUpdaterServices(t)
+			defer cleanup()
+			ctx := context.Background()
+
+			worldURI := config.RemoteBackups + "/1234567890.tar"
+			setupWorldsRemoteTar(t, downloader, remoteTempDir, worldURI)
+			archive := downloader.data[worldURI]
+			world := createWorldsTestWorld(worldURI)
+			world.Size = int64(len(archive))
+			world.Checksum = fmt.Sprintf("%x", sha256.Sum256(archive))
+			if corrupt {
+				world.Checksum = fmt.Sprintf("%x", sha256.Sum256([]byte("other")))
+			}
+
+			remoteData, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.20.1", []domain.World{world}))
+			require.NoError(t, err)
+			require.NoError(t, remoteStorage.Put(ctx, "manifest.json", remoteData))
+			localData, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.20.1", []domain.World{}))
+			require.NoError(t, err)
+			require.NoError(t, localStorage.Put(ctx, "manifest.json", localData))
+
+			instancePath := filepath.Join(tempDir, config.InstanceDir)
+			require.NoError(t, os.MkdirAll(instancePath, 0755))
+
+			updater, err := services.NewWorldsUpdater(librarian, validator, downloader, "test-bucket", workRoot, nil, nil)
+			require.NoError(t, err)
+
+			err = updater.Run(ctx)
+			local, getErr := librarian.GetLocalManifest(ctx)
+			require.NoError(t, getErr)
+			_, statErr := os.Stat(filepath.Join(instancePath, "world"))
+
+			if corrupt {
+				assert.ErrorIs(t, err, streamer.ErrChecksumMismatch)
+				assert.True(t, os.IsNotExist(statErr))
+				assert.Empty(t, local.Backups)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, statErr)
+			require.Len(t, local.Backups, 1)
+			assert.Equal(t, world.Checksum, local.Backups[0].Checksum)
+		})
+	}
+}
+
+func TestWorldsUpdater_TruncatedArchiveKeepsWorld(t *testing.T) {
+	localStorage, remoteStorage, librarian, validator, downloader, tempDir, remoteTempDir, workRoot, cleanup := setupWorldsUpdaterServices(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	worldURI := config.RemoteBackups + "/1234567890.tar"
+	setupWorldsRemoteTar(t, downloader, remoteTempDir, worldURI)
+	archive := downloader.data[worldURI]
+	downloader.data[worldURI] = archive[:len(archive)/2]
+
+	previous := createWorldsTestWorld(config.RemoteBackups + "/1111111111.tar")
+	remoteData, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.20.1", []domain.World{createWorldsTestWorld(worldURI)}))
+	require.NoError(t, err)
+	require.NoError(t, remoteStorage.Put(ctx, "manifest.json", remoteData))
+	localData, err := json.Marshal(createWorldsTestManifest("1.0.0", "1.20.1", []domain.World{previous}))
+	require.NoError(t, err)
+	require.NoError(t, localStorage.Put(ctx, "manifest.json", localData))
+
+	levelPath := filepath.Join(tempDir, config.InstanceDir, "world", "level.dat")
+	require.NoError(t, os.MkdirAll(filepath.Dir(levelPath), 0755))
+	require.NoError(t, os.WriteFile(levelPath, []byte("current world"), 0644))
+
+	updater, err := services.NewWorldsUpdater(librarian, validator, downloader, "test-bucket", workRoot, nil, nil)
+	require.NoError(t, err)
+	assert.Error(t, updater.Run(ctx))
+
+	// The current world is untouched and still matches the local manifest
+	level, err := os.ReadFile(levelPath)
+	require.NoError(t, err)
+	assert.Equal(t, "current world", string(level))
+	entries, err := os.ReadDir(filepath.Join(tempDir, config.InstanceDir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "staging directories should be removed")
+
+	local, err := librarian.GetLocalManifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, local.Backups, 1)
+	assert.Equal(t, previous.URI, local.Backups[0].URI)
+}
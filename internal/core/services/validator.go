@@ -141,8 +141,8 @@ func (v *ValidatorService) CheckWorld(local *domain.Manifest, remote *domain.Man
 	}
 
 	// Safe comparison of last world only
-	if remote.Backups[len(remote.Backups)-1] !=
-		local.Backups[len(local.Backups)-1] {
+	if !remote.Backups[len(remote.Backups)-1].Equal(
+		local.Backups[len(local.Backups)-1]) {
 		return ErrOutdatedWorld
 	}
 